@@ -0,0 +1,215 @@
+package app
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/omerkaya1/gg-config/internal/input"
+	"github.com/omerkaya1/gg-config/internal/variables"
+	"github.com/omerkaya1/gg-config/pkg/config"
+	"github.com/omerkaya1/gg-config/pkg/wizard"
+	"golang.org/x/term"
+)
+
+// runAddFile appends a file entry to an existing config, asked for through
+// the file questions of the wizard or given through flags, and writes the
+// config back in place.
+func runAddFile(fs *flag.FlagSet) func(args []string) error {
+	var (
+		f        File
+		locals   stringList
+		tplDir   string
+		noCol    bool
+		portable bool
+		maxLine  int
+	)
+	fs.StringVar(&f.Name, "name", "", "name of the file to generate; skips the wizard")
+	fs.StringVar(&f.Path, "path", "", "path the file is generated in")
+	fs.StringVar(&f.Template, "template", "", "template the file is generated from")
+	fs.StringVar(&f.SkipIf, "skip-if", "", "expression skipping the file when true")
+	fs.Var(&locals, "local", "local variable as key=value or key:type=value; repeatable")
+	fs.StringVar(&tplDir, "templates-dir", "", "directory whose files are offered as template choices")
+	fs.BoolVar(&noCol, "no-color", false, "disable colored wizard output; NO_COLOR is respected as well")
+	fs.IntVar(&maxLine, "max-line-size", wizard.DefaultMaxLineSize, "length in bytes of the longest answer line read by the wizard")
+	fs.BoolVar(&portable, "portable-paths", false, "store the paths and names of files slash separated and cleaned, the form every system reads the same")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gg-config add-file config.json [flags]")
+		fmt.Fprintln(fs.Output(), "Without --name, --path and --template, the file is asked for the way the wizard asks for files.")
+		fs.PrintDefaults()
+	}
+	return func(args []string) error {
+		// The config may come first, as in add-file config.json --name main.go.
+		var path string
+		if len(args) != 0 && !strings.HasPrefix(args[0], "-") {
+			path, args = args[0], args[1:]
+		}
+		if err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		if path == "" && fs.NArg() == 1 {
+			path = fs.Arg(0)
+		} else if path == "" || fs.NArg() != 0 {
+			return usageError(fs, "add-file: expected exactly one config file")
+		}
+
+		cfg, format, err := readConfigFile(path)
+		if err != nil {
+			return fmt.Errorf("add-file: %w", err)
+		}
+		scripted := f.Name != "" || f.Path != "" || f.Template != "" || f.SkipIf != "" || len(locals) != 0
+		if scripted {
+			var (
+				local = input.Scope{}
+				seen  = make(map[string]bool)
+			)
+			for _, l := range locals {
+				key, value, ok := strings.Cut(l, "=")
+				if !ok || key == "" {
+					return fmt.Errorf("add-file: local %q: expected key=value", l)
+				}
+				if err := local.Add(key, value, seen); err != nil {
+					return fmt.Errorf("add-file: local %q: %w", l, err)
+				}
+			}
+			local.SetLocal(&f)
+			if f.Name == "" || f.Path == "" || f.Template == "" {
+				return errors.New("add-file: --name, --path and --template are required")
+			}
+			if j := config.FindCollision(cfg.Files, f, -1); j >= 0 {
+				return fmt.Errorf("add-file: %s is already produced by files[%d]", f.Target(), j)
+			}
+			cfg.Files = append(cfg.Files, f)
+		} else {
+			if !term.IsTerminal(int(os.Stdin.Fd())) && !assumeYes {
+				return errors.New("add-file: stdin is not a terminal: provide the file through --name, --path and --template, or the answers with --yes")
+			}
+			if maxLine < 1 {
+				return usageError(fs, "add-file: invalid --max-line-size: %d", maxLine)
+			}
+			opts := append(colorOptions(noCol), wizard.WithMaxLineSize(maxLine), wizard.WithTemplatesDir(tplDir))
+			w, err := newWizard(os.Stdout, opts...)
+			if err != nil {
+				return err
+			}
+			if cfg.Files, err = w.AddFile(cfg.Files); err != nil {
+				return fmt.Errorf("add-file: %w", err)
+			}
+		}
+		if portable {
+			cfg.Files = portableFiles(cfg.Files)
+		}
+		if err := writeConfigFile(path, format, cfg); err != nil {
+			return fmt.Errorf("add-file: %w", err)
+		}
+		return nil
+	}
+}
+
+// runAddCommand appends a command, or a pre-command, to an existing config
+// and writes the config back in place. The command is given as separate
+// arguments after --, as a single command line split the way the wizard
+// splits arguments, or asked for through the command questions of the
+// wizard.
+func runAddCommand(fs *flag.FlagSet) func(args []string) error {
+	var (
+		c     Command
+		pre   bool
+		env   stringList
+		noCol bool
+	)
+	fs.BoolVar(&pre, "pre", false, "add a pre-generation command instead of a post-processing one")
+	fs.StringVar(&c.ID, "id", "", "ID other commands can depend on")
+	fs.StringVar(&c.Dir, "dir", "", "working directory of the command")
+	fs.Var(&env, "env", "environment variable of the command as KEY=value; repeatable")
+	fs.BoolVar(&c.Shell, "shell", false, "run the command through a shell")
+	fs.StringVar(&c.Timeout, "timeout", "", "duration after which the command is stopped, e.g. 30s")
+	fs.StringVar(&c.When, "when", "", `condition the command only runs under, e.g. os == "linux"`)
+	fs.BoolVar(&noCol, "no-color", false, "disable colored wizard output; NO_COLOR is respected as well")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gg-config add-command config.json [flags] [--] [command [arguments]]")
+		fmt.Fprintln(fs.Output(), `E.g. gg-config add-command config.json -- go mod tidy, or gg-config add-command config.json "go mod tidy".`)
+		fmt.Fprintln(fs.Output(), "Without a command, it is asked for the way the wizard asks for commands.")
+		fs.PrintDefaults()
+	}
+	return func(args []string) error {
+		// The config may come first, as in add-command config.json --pre -- make.
+		var path string
+		if len(args) != 0 && !strings.HasPrefix(args[0], "-") {
+			path, args = args[0], args[1:]
+		}
+		if err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		argv := fs.Args()
+		if path == "" {
+			if len(argv) == 0 {
+				return usageError(fs, "add-command: expected a config file")
+			}
+			path, argv = argv[0], argv[1:]
+		}
+		for _, e := range env {
+			key, value, ok := strings.Cut(e, "=")
+			if !ok || !variables.IsEnvName(key) {
+				return fmt.Errorf("add-command: environment variable %q: expected KEY=value", e)
+			}
+			if c.Env == nil {
+				c.Env = make(map[string]string)
+			}
+			c.Env[key] = value
+		}
+
+		cfg, format, err := readConfigFile(path)
+		if err != nil {
+			return fmt.Errorf("add-command: %w", err)
+		}
+		switch {
+		case len(argv) == 1:
+			// A single argument is a command line, as typed in the wizard.
+			line := strings.TrimSpace(argv[0])
+			if input.HasShellSyntax(line) && !c.Shell {
+				return fmt.Errorf("add-command: %q contains shell syntax, add --shell to run it through a shell", line)
+			}
+			name, rest, _ := strings.Cut(line, " ")
+			if c.Shell {
+				c.Name = name
+				if rest = strings.TrimSpace(rest); rest != "" {
+					c.Args = []string{rest}
+				}
+				break
+			}
+			parsed, err := input.ParseCommand(line)
+			if err != nil {
+				return fmt.Errorf("add-command: %w", err)
+			}
+			c.Name, c.Args = parsed.Name, parsed.Args
+		case len(argv) > 1:
+			c.Name, c.Args = argv[0], argv[1:]
+			if c.Shell {
+				c.Args = []string{strings.Join(argv[1:], " ")}
+			}
+		default:
+			if !term.IsTerminal(int(os.Stdin.Fd())) && !assumeYes {
+				return errors.New("add-command: stdin is not a terminal: provide the command after --, or the answers with --yes")
+			}
+			w, err := newWizard(os.Stdout, colorOptions(noCol)...)
+			if err != nil {
+				return err
+			}
+			if c, err = w.AddCommand(c); err != nil {
+				return fmt.Errorf("add-command: %w", err)
+			}
+		}
+		if pre {
+			cfg.PreCmds = append(cfg.PreCmds, c)
+		} else {
+			cfg.Cmds = append(cfg.Cmds, c)
+		}
+		if err := writeConfigFile(path, format, cfg); err != nil {
+			return fmt.Errorf("add-command: %w", err)
+		}
+		return nil
+	}
+}
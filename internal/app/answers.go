@@ -0,0 +1,102 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/omerkaya1/gg-config/internal/input"
+	"github.com/omerkaya1/gg-config/pkg/wizard"
+	"gopkg.in/yaml.v3"
+)
+
+// answers holds predetermined responses to the wizard prompts. Values are
+// given exactly as they would be typed in, so they go through the same type
+// inference as interactive input.
+type answers struct {
+	Globals     map[string]string `yaml:"globals"`
+	Files       []fileAnswers     `yaml:"files"`
+	PreCommands []string          `yaml:"pre_commands"`
+	Commands    []string          `yaml:"commands"`
+}
+
+type fileAnswers struct {
+	Name     string            `yaml:"name"`
+	Path     string            `yaml:"path"`
+	Template string            `yaml:"template"`
+	Local    map[string]string `yaml:"local"`
+	SkipIf   string            `yaml:"skip_if"`
+	Format   string            `yaml:"format"`
+}
+
+// loadAnswers reads an answers file, or stdin if path is "-". Since YAML is a
+// superset of JSON, both formats are accepted.
+func loadAnswers(path string) (answers, error) {
+	var (
+		a    answers
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(wizard.Stdin())
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return a, fmt.Errorf("load answers: %w", err)
+	}
+	if err = yaml.Unmarshal(data, &a); err != nil {
+		return a, fmt.Errorf("load answers %s: %w", path, err)
+	}
+	return a, nil
+}
+
+// apply adds the answered globals, files and (pre-)commands to cfg, the
+// variables entered by rules.
+func (a answers) apply(cfg *Config, rules input.Rules) error {
+	scope := input.GlobalScope(*cfg)
+	scope.Rules = rules
+	for _, k := range sortedKeys(a.Globals) {
+		if err := scope.Add(k, a.Globals[k], nil); err != nil {
+			return fmt.Errorf("answers: global: %w", err)
+		}
+	}
+	scope.SetGlobal(cfg)
+
+	for i, fa := range a.Files {
+		if fa.Name == "" || fa.Path == "" || fa.Template == "" {
+			return fmt.Errorf("answers: file %d: name, path and template are required", i)
+		}
+		f := File{
+			Name:     fa.Name,
+			Path:     fa.Path,
+			Template: fa.Template,
+			SkipIf:   fa.SkipIf,
+			Format:   fa.Format,
+		}
+		local := input.Scope{Rules: rules}
+		for _, k := range sortedKeys(fa.Local) {
+			if err := local.Add(k, fa.Local[k], nil); err != nil {
+				return fmt.Errorf("answers: file %d: %w", i, err)
+			}
+		}
+		local.SetLocal(&f)
+		cfg.Files = append(cfg.Files, f)
+	}
+
+	for i, line := range a.PreCommands {
+		c, err := input.ParseCommand(line)
+		if err != nil {
+			return fmt.Errorf("answers: pre-command %d: %w", i, err)
+		}
+		cfg.PreCmds = append(cfg.PreCmds, c)
+	}
+	for i, line := range a.Commands {
+		c, err := input.ParseCommand(line)
+		if err != nil {
+			return fmt.Errorf("answers: command %d: %w", i, err)
+		}
+		cfg.Cmds = append(cfg.Cmds, c)
+	}
+	return nil
+}
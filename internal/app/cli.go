@@ -0,0 +1,322 @@
+package app
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+// subcommand is one of the commands gg-config dispatches its first argument
+// to. Each parses its own flags and prints its own usage on -h.
+type subcommand struct {
+	name    string
+	summary string
+	// setup declares the flags of the command and its usage on fs, and
+	// returns the function running the command with its arguments.
+	setup func(fs *flag.FlagSet) func(args []string) error
+}
+
+// flagSet returns the flag set of the command, and the function running it.
+func (c subcommand) flagSet() (*flag.FlagSet, func(args []string) error) {
+	fs := flag.NewFlagSet(c.name, flag.ExitOnError)
+	return fs, c.setup(fs)
+}
+
+// subcommands lists the commands of gg-config in the order they are listed
+// in the usage.
+var subcommands []subcommand
+
+func init() {
+	subcommands = []subcommand{
+		{"init", "assemble a new config through the wizard, or through answers and flags", runInit},
+		{"edit", "edit an existing config through the wizard", runEdit},
+		{"list", "print the globals, files and commands of a config as tables", runList},
+		{"get", "print the value at a path of a config", runGet},
+		{"set", "set the value at a path of a config", runSet},
+		{"unset", "remove the value at a path of a config", runUnset},
+		{"add-file", "add a file entry to a config, through the wizard or flags", runAddFile},
+		{"add-command", "add a command or pre-command to a config, through the wizard or arguments", runAddCommand},
+		{"rm", "remove file entries, commands or global variables from a config", runRm},
+		{"validate", "check a config, and optionally its templates, for problems", runValidate},
+		{"lint", "check a config against style and consistency rules", runLint},
+		{"render", "generate the files of a config out of their templates", runRender},
+		{"diff", "compare two configs", runDiff},
+		{"merge", "merge several configs into one", runMerge},
+		{"migrate", "rewrite configs in an older layout to the current one", runMigrate},
+		{"import", "convert a cookiecutter, plop or yeoman generator into a config", runImport},
+		{"export-env", "print the global variables of a config as environment variables", runExportEnv},
+		{"schema", "print the JSON schema of configs", runSchema},
+		{"completion", "print the shell completion script of bash, zsh, fish or powershell", runCompletion},
+		{"version", "print the version and build information of gg-config", runVersion},
+		{"help", "print the usage of gg-config or of one of its commands", runHelp},
+	}
+}
+
+var (
+	// assumeYes answers the confirmations of the wizard and of the commands
+	// with yes, for unattended runs.
+	assumeYes bool
+	// forceOverwrite overwrites existing output files without asking.
+	forceOverwrite bool
+	// keepBackups backs up the files replaced by configs written, see
+	// commitFile.
+	keepBackups = true
+)
+
+// Exit codes of gg-config, telling scripts how a command failed. Errors of
+// no particular kind exit with exitFailure.
+const (
+	exitFailure = 1
+	// exitUsage is for invalid arguments and flags, as with flag.ExitOnError.
+	exitUsage = 2
+	// exitIncomplete is for commands whose input ended before they were done.
+	exitIncomplete = 3
+	// exitInvalid is for configs failing validation.
+	exitInvalid = 4
+	// exitIO is for files which could not be read or written.
+	exitIO = 5
+	// exitHook is for commands of a config which failed.
+	exitHook = 6
+	// exitCanceled is for commands the user aborted or declined.
+	exitCanceled = 7
+	// exitInterrupted is for commands stopped by SIGINT or SIGTERM, the code
+	// shells report for SIGINT.
+	exitInterrupted = 130
+)
+
+// exitError makes Main exit with code rather than 1.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// commonFlags are the flags every subcommand accepts.
+type commonFlags struct {
+	log      logOptions
+	yes      bool
+	force    bool
+	noBackup bool
+}
+
+func (c *commonFlags) register(fs *flag.FlagSet) {
+	c.log.register(fs)
+	fs.BoolVar(&c.yes, "yes", false, "answer confirmations with yes; the wizard reads the other answers from stdin and stops adding entries at its end")
+	fs.BoolVar(&c.force, "force", false, "overwrite existing output files without asking")
+	fs.BoolVar(&c.noBackup, "no-backup", false, "replace configs without keeping a timestamped .bak copy of the previous one")
+}
+
+// apply sets the state of the package according to c.
+func (c commonFlags) apply() error {
+	assumeYes, forceOverwrite, keepBackups = c.yes, c.force, !c.noBackup
+	return c.log.apply()
+}
+
+// Main runs the gg-config command with the arguments in os.Args. Without a
+// command, or when the first argument is a flag, the init command runs, as
+// gg-config did before it had any.
+func Main() {
+	name, args := "init", os.Args[1:]
+	if len(args) != 0 {
+		switch {
+		case args[0] == "-h" || args[0] == "-help" || args[0] == "--help":
+			name, args = "help", nil
+		case !strings.HasPrefix(args[0], "-"):
+			name, args = args[0], args[1:]
+		}
+	}
+	cmd, ok := lookupSubcommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "gg-config: unknown command %q\n\n", name)
+		printUsage(os.Stderr)
+		os.Exit(exitUsage)
+	}
+	_, run := cmd.flagSet()
+	if err := run(args); err != nil {
+		logger.Error(err.Error())
+		os.Exit(exitCode(err))
+	}
+}
+
+// parseFlags parses the arguments of a subcommand with fs, along with the
+// flags every subcommand accepts.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	_, err := parseArgs(fs, args, false)
+	return err
+}
+
+// parseInterspersed parses args with fs like parseFlags, except that flags
+// may follow the arguments which are not flags, which it returns. Arguments
+// following -- are never flags.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	return parseArgs(fs, args, true)
+}
+
+func parseArgs(fs *flag.FlagSet, args []string, interspersed bool) ([]string, error) {
+	var common commonFlags
+	common.register(fs)
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		n := len(args) - fs.NArg()
+		if !interspersed || fs.NArg() == 0 || n > 0 && args[n-1] == "--" {
+			rest = append(rest, fs.Args()...)
+			break
+		}
+		rest, args = append(rest, fs.Arg(0)), fs.Args()[1:]
+	}
+	if err := common.apply(); err != nil {
+		return nil, &exitError{code: exitUsage, err: err}
+	}
+	return rest, nil
+}
+
+// exitCode returns the code gg-config exits with after err.
+func exitCode(err error) int {
+	var (
+		exit    *exitError
+		hook    hookError
+		invalid config.ValidationErrors
+		pathErr *fs.PathError
+		linkErr *os.LinkError
+	)
+	switch {
+	case errors.As(err, &exit):
+		return exit.code
+	case errors.Is(err, errInterrupted):
+		return exitInterrupted
+	case errors.Is(err, errQuit):
+		return exitCanceled
+	case errors.As(err, &hook):
+		return exitHook
+	case errors.Is(err, errStrict), errors.As(err, &invalid):
+		return exitInvalid
+	case errors.As(err, &pathErr), errors.As(err, &linkErr):
+		return exitIO
+	default:
+		return exitFailure
+	}
+}
+
+// usageError prints the usage of fs and returns an error exiting with
+// exitUsage.
+func usageError(fs *flag.FlagSet, format string, args ...any) error {
+	fs.Usage()
+	return &exitError{code: exitUsage, err: fmt.Errorf(format, args...)}
+}
+
+func lookupSubcommand(name string) (subcommand, bool) {
+	for _, c := range subcommands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return subcommand{}, false
+}
+
+// printUsage lists the commands of gg-config.
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: gg-config <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, c := range subcommands {
+		fmt.Fprintf(tw, "  %s\t%s\n", c.name, c.summary)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "gg-config help <command>" for the flags of a command. Every command accepts`)
+	fmt.Fprintln(w, "--verbose, --quiet and --log-format json to control what it logs on stderr, and --yes and")
+	fmt.Fprintln(w, "--force to run unattended.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Exit codes:")
+	for _, c := range []struct {
+		code    int
+		meaning string
+	}{
+		{0, "success"},
+		{exitFailure, "failure"},
+		{exitUsage, "invalid arguments or flags"},
+		{exitIncomplete, "input ended before the command was done"},
+		{exitInvalid, "the config failed validation"},
+		{exitIO, "a file could not be read or written"},
+		{exitHook, "a command of the config failed"},
+		{exitCanceled, "aborted or declined by the user"},
+		{exitInterrupted, "interrupted by SIGINT or SIGTERM"},
+	} {
+		fmt.Fprintf(tw, "  %d\t%s\n", c.code, c.meaning)
+	}
+	tw.Flush()
+}
+
+// runHelp prints the usage of gg-config, or of the command named by its
+// argument. It takes no flags.
+func runHelp(*flag.FlagSet) func(args []string) error {
+	return func(args []string) error {
+		switch len(args) {
+		case 0:
+			printUsage(os.Stdout)
+			return nil
+		case 1:
+			cmd, ok := lookupSubcommand(args[0])
+			if !ok {
+				return fmt.Errorf("help: unknown command %q", args[0])
+			}
+			if cmd.name == "help" {
+				printUsage(os.Stdout)
+				return nil
+			}
+			_, run := cmd.flagSet()
+			return run([]string{"-h"})
+		default:
+			return &exitError{code: exitUsage, err: errors.New("help: expected at most one command")}
+		}
+	}
+}
+
+// runSchema prints the JSON schema of configs.
+func runSchema(fs *flag.FlagSet) func(args []string) error {
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gg-config schema")
+		fs.PrintDefaults()
+	}
+	return func(args []string) error {
+		if err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		if fs.NArg() != 0 {
+			return usageError(fs, "schema: unexpected arguments: %s", strings.Join(fs.Args(), " "))
+		}
+		if err := config.WriteSchema(os.Stdout); err != nil {
+			return fmt.Errorf("failed to produce schema: %w", err)
+		}
+		return nil
+	}
+}
+
+// flagPassed reports whether any of the flags called names was passed to fs.
+func flagPassed(fs *flag.FlagSet, names ...string) bool {
+	var passed bool
+	fs.Visit(func(f *flag.Flag) {
+		for _, name := range names {
+			passed = passed || f.Name == name
+		}
+	})
+	return passed
+}
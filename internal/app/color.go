@@ -0,0 +1,16 @@
+package app
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// colorEnabled reports whether output should be styled: only on a terminal,
+// and neither --no-color nor the NO_COLOR convention is set.
+func colorEnabled(noColor bool) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
@@ -0,0 +1,328 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+var completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// completionFlag is a flag of a subcommand, as offered by completion.
+type completionFlag struct {
+	Name  string
+	Usage string
+	// Bool flags take no value.
+	Bool bool
+	// Values are the values offered for the flag, files if there are none.
+	Values []string
+}
+
+// Dashed returns the flag as typed: -f for single letter flags, --format
+// otherwise.
+func (f completionFlag) Dashed() string {
+	if len(f.Name) == 1 {
+		return "-" + f.Name
+	}
+	return "--" + f.Name
+}
+
+// Patterns returns the case patterns matching the flag as typed with either
+// one or two dashes, as in --format|-format.
+func (f completionFlag) Patterns() string {
+	if len(f.Name) == 1 {
+		return f.Dashed()
+	}
+	return f.Dashed() + "|-" + f.Name
+}
+
+// zshSpec returns the _arguments specification of the flag.
+func (f completionFlag) zshSpec() string {
+	spec := f.Dashed() + "[" + zshEscape(f.Usage) + "]"
+	switch {
+	case f.Bool:
+	case len(f.Values) != 0:
+		spec += ":value:(" + strings.Join(f.Values, " ") + ")"
+	default:
+		spec += ":value:_files"
+	}
+	return spec
+}
+
+// completionCommand is a subcommand, as offered by completion.
+type completionCommand struct {
+	Name    string
+	Summary string
+	Flags   []completionFlag
+	// Args are the values offered for the arguments of the command, files
+	// if there are none and Files is set.
+	Args  []string
+	Files bool
+}
+
+// ZshSpecs returns the _arguments specifications of the flags and
+// arguments of the command, quoted.
+func (c completionCommand) ZshSpecs() []string {
+	var specs []string
+	for _, f := range c.Flags {
+		specs = append(specs, shellQuote(f.zshSpec()))
+	}
+	switch {
+	case len(c.Args) != 0:
+		specs = append(specs, shellQuote("*:argument:("+strings.Join(c.Args, " ")+")"))
+	case c.Files:
+		specs = append(specs, "'*:file:_files'")
+	}
+	return specs
+}
+
+// completionCommands lists the subcommands with their flags, collected from
+// their flag sets along with the flags every subcommand accepts.
+func completionCommands() []completionCommand {
+	var (
+		cmds  []completionCommand
+		names []string
+	)
+	for _, c := range subcommands {
+		names = append(names, c.name)
+	}
+	for _, c := range subcommands {
+		cc := completionCommand{Name: c.name, Summary: c.summary}
+		switch c.name {
+		case "help":
+			cc.Args = names
+			cmds = append(cmds, cc)
+			continue
+		case "completion":
+			cc.Args = completionShells
+		case "version", "schema":
+		default:
+			cc.Files = true
+		}
+		fs, _ := c.flagSet()
+		var common commonFlags
+		common.register(fs)
+		fs.VisitAll(func(f *flag.Flag) {
+			cf := completionFlag{Name: f.Name, Usage: f.Usage}
+			if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+				cf.Bool = true
+			}
+			switch f.Name {
+			case "format", "f":
+				cf.Values = config.EncodeFormats()
+			case "log-format":
+				cf.Values = []string{logFormatText, logFormatJSON}
+			}
+			cc.Flags = append(cc.Flags, cf)
+		})
+		cmds = append(cmds, cc)
+	}
+	return cmds
+}
+
+// runCompletion prints the completion script of a shell.
+func runCompletion(fs *flag.FlagSet) func(args []string) error {
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: gg-config completion %s\n", strings.Join(completionShells, "|"))
+		fmt.Fprintln(fs.Output(), `E.g. source <(gg-config completion bash) in ~/.bashrc, or gg-config completion fish > ~/.config/fish/completions/gg-config.fish.`)
+		fs.PrintDefaults()
+	}
+	return func(args []string) error {
+		if err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return usageError(fs, "completion: expected exactly one shell")
+		}
+		t, ok := completionScripts[fs.Arg(0)]
+		if !ok {
+			return usageError(fs, "completion: unsupported shell %q, expected one of %s", fs.Arg(0), strings.Join(completionShells, ", "))
+		}
+		if err := t.Execute(os.Stdout, completionCommands()); err != nil {
+			return fmt.Errorf("completion: %w", err)
+		}
+		return nil
+	}
+}
+
+// shellQuote quotes s for POSIX shells, zsh and fish alike, in single
+// quotes.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// zshEscape escapes the characters _arguments treats specially in flag
+// descriptions.
+func zshEscape(s string) string {
+	return strings.NewReplacer("[", `\[`, "]", `\]`, ":", `\:`).Replace(s)
+}
+
+// psQuote quotes s for PowerShell, in single quotes.
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// flagValues maps the flags of cmds offering values, as typed, to the
+// values.
+func flagValues(cmds []completionCommand) map[string][]string {
+	values := make(map[string][]string)
+	for _, c := range cmds {
+		for _, f := range c.Flags {
+			if len(f.Values) != 0 {
+				values[f.Dashed()] = f.Values
+			}
+		}
+	}
+	return values
+}
+
+var completionFuncs = template.FuncMap{
+	"flagValues": flagValues,
+	"join":       strings.Join,
+	"quote":      shellQuote,
+	"psQuote":    psQuote,
+}
+
+var completionScripts = map[string]*template.Template{
+	"bash": template.Must(template.New("bash").Funcs(completionFuncs).Parse(`# bash completion for gg-config
+_gg_config() {
+    local cur prev cmd words
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+    cmd="${COMP_WORDS[1]}"
+    if [[ $COMP_CWORD -eq 1 && $cur != -* ]]; then
+        COMPREPLY=($(compgen -W "{{range .}}{{.Name}} {{end}}" -- "$cur"))
+        return
+    fi
+    [[ $cmd == -* ]] && cmd=init
+    case "$cmd" in
+{{- range .}}
+    {{.Name}})
+        case "$prev" in
+{{- range .Flags}}{{if .Values}}
+        {{.Patterns}}) COMPREPLY=($(compgen -W "{{join .Values " "}}" -- "$cur")); return ;;
+{{- else if not .Bool}}
+        {{.Patterns}}) COMPREPLY=($(compgen -f -- "$cur")); return ;;
+{{- end}}{{end}}
+        esac
+        words="{{range .Flags}}{{.Dashed}} {{end}}"
+        if [[ $cur == -* ]]; then
+            COMPREPLY=($(compgen -W "$words" -- "$cur"))
+{{- if .Args}}
+        else
+            COMPREPLY=($(compgen -W "{{join .Args " "}}" -- "$cur"))
+{{- else if .Files}}
+        else
+            COMPREPLY=($(compgen -f -- "$cur"))
+{{- end}}
+        fi
+        ;;
+{{- end}}
+    esac
+}
+complete -o filenames -F _gg_config gg-config
+`)),
+	"zsh": template.Must(template.New("zsh").Funcs(completionFuncs).Parse(`#compdef gg-config
+# zsh completion for gg-config
+_gg_config() {
+    local -a commands
+    commands=(
+{{- range .}}
+        {{quote (print .Name ":" .Summary)}}
+{{- end}}
+    )
+    if (( CURRENT == 2 )) && [[ ${words[2]} != -* ]]; then
+        _describe 'command' commands
+        return
+    fi
+    local cmd=${words[2]}
+    if [[ $cmd == -* ]]; then
+        cmd=init
+    else
+        shift words
+        (( CURRENT-- ))
+    fi
+    case $cmd in
+{{- range .}}
+    {{.Name}})
+{{- if .ZshSpecs}}
+        _arguments -s {{join .ZshSpecs " \\\n            "}}
+{{- else}}
+        _message 'no arguments'
+{{- end}}
+        ;;
+{{- end}}
+    esac
+}
+if [[ $zsh_eval_context[-1] == loadautofunc ]]; then
+    _gg_config "$@"
+else
+    compdef _gg_config gg-config
+fi
+`)),
+	"fish": template.Must(template.New("fish").Funcs(completionFuncs).Parse(`# fish completion for gg-config
+complete -c gg-config -f
+{{- range .}}
+complete -c gg-config -n __fish_use_subcommand -a {{.Name}} -d {{quote .Summary}}
+{{- end}}
+{{- range $c := .}}
+{{- range .Flags}}
+complete -c gg-config -n {{quote (print "__fish_seen_subcommand_from " $c.Name)}} {{if eq (len .Name) 1}}-s{{else}}-l{{end}} {{.Name}}
+{{- if .Values}} -x -a {{quote (join .Values " ")}}{{else if not .Bool}} -r -F{{end}} -d {{quote .Usage}}
+{{- end}}
+{{- if .Args}}
+complete -c gg-config -n {{quote (print "__fish_seen_subcommand_from " $c.Name)}} -a {{quote (join .Args " ")}}
+{{- else if .Files}}
+complete -c gg-config -n {{quote (print "__fish_seen_subcommand_from " $c.Name)}} -F
+{{- end}}
+{{- end}}
+`)),
+	"powershell": template.Must(template.New("powershell").Funcs(completionFuncs).Parse(`# PowerShell completion for gg-config
+Register-ArgumentCompleter -Native -CommandName gg-config -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $commands = [ordered]@{
+{{- range .}}
+        {{psQuote .Name}} = {{psQuote .Summary}}
+{{- end}}
+    }
+    $flags = @{
+{{- range .}}
+        {{psQuote .Name}} = @({{range $i, $f := .Flags}}{{if $i}}, {{end}}{{psQuote $f.Dashed}}{{end}})
+{{- end}}
+    }
+    $values = @{
+{{- range $flag, $values := flagValues .}}
+        {{psQuote $flag}} = @({{range $i, $v := $values}}{{if $i}}, {{end}}{{psQuote $v}}{{end}})
+{{- end}}
+    }
+    $arguments = @{
+{{- range .}}{{if .Args}}
+        {{psQuote .Name}} = @({{range $i, $v := .Args}}{{if $i}}, {{end}}{{psQuote $v}}{{end}})
+{{- end}}{{end}}
+    }
+    $words = @($commandAst.CommandElements | Select-Object -Skip 1 | ForEach-Object { $_.ToString() })
+    if ($wordToComplete -ne '') { $words = @($words | Select-Object -SkipLast 1) }
+    $complete = {
+        param($candidates)
+        $candidates | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+            [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+        }
+    }
+    if ($words.Count -eq 0 -and $wordToComplete -notlike '-*') {
+        $commands.Keys | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+            [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $commands[$_])
+        }
+        return
+    }
+    $cmd = if ($words.Count -eq 0 -or $words[0] -like '-*') { 'init' } else { $words[0] }
+    $prev = if ($words.Count -ne 0) { $words[-1] } else { '' }
+    if ($values.ContainsKey($prev)) { & $complete $values[$prev]; return }
+    if ($wordToComplete -like '-*') { & $complete $flags[$cmd]; return }
+    if ($arguments.ContainsKey($cmd)) { & $complete $arguments[$cmd]; return }
+}
+`)),
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCompletionCommands(t *testing.T) {
+	cmds := completionCommands()
+	if len(cmds) != len(subcommands) {
+		t.Fatalf("got %d commands, want %d", len(cmds), len(subcommands))
+	}
+	for _, c := range cmds {
+		flags := make(map[string]completionFlag)
+		for _, f := range c.Flags {
+			flags[f.Name] = f
+		}
+		if c.Name == "help" {
+			if len(flags) != 0 {
+				t.Errorf("help got flags %v", c.Flags)
+			}
+			continue
+		}
+		for _, name := range []string{"yes", "force", "log-format"} {
+			if _, ok := flags[name]; !ok {
+				t.Errorf("%s lacks the common flag %s", c.Name, name)
+			}
+		}
+	}
+	// The flags of the commands are the ones their flag sets declare.
+	for _, c := range cmds {
+		if c.Name != "unset" {
+			continue
+		}
+		var found bool
+		for _, f := range c.Flags {
+			found = found || f.Name == "allow-empty-files" && f.Bool
+		}
+		if !found {
+			t.Errorf("unset lacks --allow-empty-files: %v", c.Flags)
+		}
+	}
+}
+
+func TestCompletionBash(t *testing.T) {
+	var b bytes.Buffer
+	if err := completionScripts["bash"].Execute(&b, completionCommands()); err != nil {
+		t.Fatal(err)
+	}
+	script := b.String()
+	for _, want := range []string{"        -f) ", "        --format|-format) ", "        --output|-output) "} {
+		if !strings.Contains(script, want) {
+			t.Errorf("the script lacks the case %q", want)
+		}
+	}
+	if strings.Contains(script, "-f|-f)") || strings.Contains(script, "-o|-o)") {
+		t.Error("the script repeats the patterns of single letter flags")
+	}
+}
@@ -0,0 +1,320 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/omerkaya1/gg-config/internal/input"
+	"github.com/omerkaya1/gg-config/internal/variables"
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+const diffContext = 3
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// splitLines splits s into lines, each keeping its trailing newline.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes a shortest edit script turning a into b using Myers'
+// algorithm.
+func diffLines(a, b []string) []diffOp {
+	var (
+		n, m  = len(a), len(b)
+		off   = n + m
+		v     = make([]int, 2*off+2)
+		trace [][]int
+	)
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || k != d && v[off+k-1] < v[off+k+1] {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	var (
+		ops  []diffOp
+		x, y = n, m
+	)
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || k != d && v[off+k-1] < v[off+k+1] {
+			prevK = k + 1
+		}
+		prevX := v[off+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			ops = append(ops, diffOp{' ', a[x]})
+		}
+		if x == prevX {
+			ops = append(ops, diffOp{'+', b[prevY]})
+		} else {
+			ops = append(ops, diffOp{'-', a[prevX]})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		x, y = x-1, y-1
+		ops = append(ops, diffOp{' ', a[x]})
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// writeUnifiedDiff writes the differences between oldText and newText in the
+// unified format, writing nothing if they are equal.
+func writeUnifiedDiff(w io.Writer, oldName, newName, oldText, newText string) error {
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	// Positions of every op in the old and new text, and the op ranges of
+	// the hunks, changes closer than twice the context being merged.
+	var (
+		oldPos = make([]int, len(ops)+1)
+		newPos = make([]int, len(ops)+1)
+		hunks  [][2]int
+	)
+	for i, op := range ops {
+		oldPos[i+1], newPos[i+1] = oldPos[i], newPos[i]
+		if op.kind != '+' {
+			oldPos[i+1]++
+		}
+		if op.kind != '-' {
+			newPos[i+1]++
+		}
+		if op.kind == ' ' {
+			continue
+		}
+		start, end := max(0, i-diffContext), min(len(ops), i+diffContext+1)
+		if len(hunks) != 0 && start <= hunks[len(hunks)-1][1] {
+			hunks[len(hunks)-1][1] = end
+		} else {
+			hunks = append(hunks, [2]int{start, end})
+		}
+	}
+	if len(hunks) == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks {
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(oldPos[h[0]], oldPos[h[1]]), hunkRange(newPos[h[0]], newPos[h[1]]))
+		for _, op := range ops[h[0]:h[1]] {
+			b.WriteByte(op.kind)
+			b.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				b.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func hunkRange(start, end int) string {
+	switch n := end - start; n {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprint(start + 1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, n)
+	}
+}
+
+// runDiff compares two configs and prints the global variables, files and
+// commands added, removed or changed.
+func runDiff(fs *flag.FlagSet) func(args []string) error {
+	var (
+		asJSON bool
+	)
+	fs.BoolVar(&asJSON, "json", false, "print the changes as a JSON object of globals, files and commands")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gg-config diff [flags] old.json new.json")
+		fs.PrintDefaults()
+	}
+	return func(args []string) error {
+		if err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		if fs.NArg() != 2 {
+			return usageError(fs, "diff: expected exactly two config files")
+		}
+		a, err := config.ReadFile(fs.Arg(0))
+		if err != nil {
+			return fmt.Errorf("diff: %w", err)
+		}
+		b, err := config.ReadFile(fs.Arg(1))
+		if err != nil {
+			return fmt.Errorf("diff: %w", err)
+		}
+
+		changes := redactChanges(config.Diff(a, b), append(a.Secrets, b.Secrets...))
+		if asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(changes); err != nil {
+				return fmt.Errorf("diff: %w", err)
+			}
+			return nil
+		}
+		return writeChanges(os.Stdout, changes)
+	}
+}
+
+// globalChangeName returns the name of the global variable changed at
+// path, of the base globals or of those of an environment.
+func globalChangeName(path string) (string, bool) {
+	if name, ok := strings.CutPrefix(path, "global."); ok {
+		return name, true
+	}
+	if rest, ok := strings.CutPrefix(path, "environments."); ok {
+		_, name, ok := strings.Cut(rest, ".global.")
+		return name, ok
+	}
+	return "", false
+}
+
+// redactChanges redacts the values of the global variables named by
+// secrets, and of the secret local variables of files, from changes.
+func redactChanges(changes config.ChangeSet, secrets []string) config.ChangeSet {
+	for i := range changes.Globals {
+		c := &changes.Globals[i]
+		name, ok := globalChangeName(c.Path)
+		if !ok || !holdsSecret(secrets, name) {
+			continue
+		}
+		if c.Old != nil {
+			c.Old = input.Redacted
+		}
+		if c.New != nil {
+			c.New = input.Redacted
+		}
+	}
+	for i := range changes.Files {
+		c := &changes.Files[i]
+		if f, ok := c.Old.(File); ok {
+			c.Old = redactFile(f)
+		}
+		if f, ok := c.New.(File); ok {
+			c.New = redactFile(f)
+		}
+	}
+	return changes
+}
+
+// holdsSecret reports whether the variable name is a secret or holds one.
+func holdsSecret(secrets []string, name string) bool {
+	for _, s := range secrets {
+		if s == name || strings.HasPrefix(s, name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// redactFile returns f with the values of its secret local variables
+// redacted.
+func redactFile(f File) File {
+	local := make(map[string]any, len(f.Local))
+	for k, v := range f.Local {
+		if holdsSecret(f.Secrets, k) {
+			v = input.Redacted
+		}
+		local[k] = v
+	}
+	f.Local = local
+	return f
+}
+
+// writeChanges writes changes as one line per change, marked with + when
+// added, - when removed and ~ when changed.
+func writeChanges(w io.Writer, changes config.ChangeSet) error {
+	bw := bufio.NewWriter(w)
+	if changes.Empty() {
+		fmt.Fprintln(bw, "no changes")
+	}
+	for _, c := range changes.Globals {
+		switch {
+		case strings.HasPrefix(c.Path, "secrets."):
+			fmt.Fprintf(bw, "%s %s\n", changeMark(c.Kind), c.Path)
+		case c.Kind == config.Added:
+			fmt.Fprintf(bw, "+ %s = %s\n", c.Path, variables.Display(c.New))
+		case c.Kind == config.Removed:
+			fmt.Fprintf(bw, "- %s = %s\n", c.Path, variables.Display(c.Old))
+		default:
+			fmt.Fprintf(bw, "~ %s: %s -> %s\n", c.Path, variables.Display(c.Old), variables.Display(c.New))
+		}
+	}
+	for _, c := range append(changes.Files, changes.Commands...) {
+		switch c.Kind {
+		case config.Added:
+			fmt.Fprintf(bw, "+ %s: %s\n", c.Path, describeItem(c.New))
+		case config.Removed:
+			fmt.Fprintf(bw, "- %s: %s\n", c.Path, describeItem(c.Old))
+		default:
+			fmt.Fprintf(bw, "~ %s: %s changed\n", c.Path, strings.Join(c.Fields, ", "))
+		}
+	}
+	return bw.Flush()
+}
+
+func changeMark(kind config.ChangeKind) string {
+	switch kind {
+	case config.Added:
+		return "+"
+	case config.Removed:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+// describeItem summarizes a file entry or command on a single line.
+func describeItem(item any) string {
+	switch v := item.(type) {
+	case File:
+		return fmt.Sprintf("template %s", v.Template)
+	case Command:
+		if v.Shell {
+			return shellScript(v) + " (shell)"
+		}
+		return input.JoinTokens(append([]string{v.Name}, v.Args...))
+	default:
+		return fmt.Sprint(item)
+	}
+}
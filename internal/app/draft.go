@@ -0,0 +1,145 @@
+package app
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/omerkaya1/gg-config/internal/variables"
+	"github.com/omerkaya1/gg-config/pkg/config"
+	"github.com/omerkaya1/gg-config/pkg/wizard"
+)
+
+// draft is the state of an interrupted wizard session: the config collected
+// so far and the next section to be filled in. The values of secrets are
+// left out of it, and asked for again when the session is resumed.
+type draft struct {
+	Section wizard.Section `json:"section"`
+	Config  Config         `json:"config"`
+}
+
+// draftPath returns where the draft of the wizard writing the config at
+// target is kept: in the cache directory of the user, under a name derived
+// from the absolute path of target, so that the sessions of other configs
+// keep their drafts apart. The draft of a config written to stdout is the
+// one of the working directory.
+func draftPath(target string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(target)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(dir, "gg-config", "drafts", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+func saveDraft(target string, d draft) error {
+	path, err := draftPath(target)
+	if err != nil {
+		return fmt.Errorf("save draft: %w", err)
+	}
+	d.Config = withoutSecrets(d.Config)
+	data, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("save draft: %w", err)
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("save draft: %w", err)
+	}
+	err = config.WriteFileAtomic(path, 0o600, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("save draft: %w", err)
+	}
+	return nil
+}
+
+func loadDraft(target string) (draft, error) {
+	var d draft
+
+	path, err := draftPath(target)
+	if err != nil {
+		return d, fmt.Errorf("load draft: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return d, fmt.Errorf("load draft: no saved session to resume")
+		}
+		return d, fmt.Errorf("load draft: %w", err)
+	}
+	// Numbers are kept as json.Number so that config.NormalizeVars sees
+	// their exact text.
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err = dec.Decode(&d); err != nil {
+		return d, fmt.Errorf("load draft %s: %w", path, err)
+	}
+	d.Config.Global = config.NormalizeVars(d.Config.Global)
+	for i := range d.Config.Files {
+		d.Config.Files[i].Local = config.NormalizeVars(d.Config.Files[i].Local)
+	}
+	return d, nil
+}
+
+func removeDraft(target string) {
+	path, err := draftPath(target)
+	if err == nil {
+		err = os.Remove(path)
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Warn("failed to remove draft: "+err.Error(), "error", err)
+	}
+}
+
+// withoutSecrets returns a copy of cfg without the values of its secrets,
+// global and local ones, which keep being listed as secrets.
+func withoutSecrets(cfg Config) Config {
+	cfg.Global = withoutVars(cfg.Global, cfg.Secrets)
+	files := make([]File, len(cfg.Files))
+	for i, f := range cfg.Files {
+		f.Local = withoutVars(f.Local, f.Secrets)
+		files[i] = f
+	}
+	cfg.Files = files
+	return cfg
+}
+
+// withoutVars returns a copy of vars without the variables called names,
+// which may be dotted.
+func withoutVars(vars map[string]any, names []string) map[string]any {
+	if len(names) == 0 {
+		return vars
+	}
+	kept := copyVars(vars)
+	for _, name := range names {
+		variables.Delete(kept, name)
+	}
+	return kept
+}
+
+// copyVars returns a copy of vars whose nested maps are copied as well.
+func copyVars(vars map[string]any) map[string]any {
+	if vars == nil {
+		return nil
+	}
+	c := make(map[string]any, len(vars))
+	for k, v := range vars {
+		if nested, ok := v.(map[string]any); ok {
+			v = copyVars(nested)
+		}
+		c[k] = v
+	}
+	return c
+}
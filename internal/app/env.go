@@ -0,0 +1,106 @@
+package app
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/omerkaya1/gg-config/internal/input"
+	"github.com/omerkaya1/gg-config/internal/variables"
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+// runExportEnv converts the global variables of a config into dotenv format.
+func runExportEnv(fs *flag.FlagSet) func(args []string) error {
+	var (
+		path   string
+		prefix string
+	)
+	fs.StringVar(&path, "output", "", "output .env file path")
+	fs.StringVar(&path, "o", "", "output .env file path (shortened)")
+	fs.StringVar(&prefix, "prefix", "", "prefix added to every variable name")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gg-config export-env [flags] config.json")
+		fs.PrintDefaults()
+	}
+	return func(args []string) error {
+		if err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return usageError(fs, "export-env: expected exactly one config file")
+		}
+
+		if err := checkOverwrite(path); err != nil {
+			return fmt.Errorf("export-env: %w", err)
+		}
+		cfg, err := config.ReadFile(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		out := newOutputWriter(path, false)
+		if err := writeEnv(out, prefix, cfg.Global); err != nil {
+			return fmt.Errorf("export-env: %w", err)
+		}
+		if err := out.Close(); err != nil {
+			return fmt.Errorf("export-env: %w", err)
+		}
+		return nil
+	}
+}
+
+func writeEnv(w io.Writer, prefix string, vars map[string]any) error {
+	bw := bufio.NewWriter(w)
+	vars = variables.Flatten(vars)
+	for _, k := range sortedKeys(vars) {
+		// Nested variables are exported as PARENT_NAME.
+		name := prefix + strings.ReplaceAll(k, ".", "_")
+		if !variables.IsEnvName(name) {
+			return fmt.Errorf("%q is not a valid environment variable name", name)
+		}
+		fmt.Fprintf(bw, "%s=%s\n", name, envValue(vars[k]))
+	}
+	return bw.Flush()
+}
+
+func envValue(v any) string {
+	var s string
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		s = val
+	default:
+		s = variables.String(val)
+	}
+	if s != "" && !strings.ContainsAny(s, " \t\r\n\"'\\$#=`") {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+// importEnv adds every environment variable starting with prefix to vars,
+// with the prefix stripped from its name, which has to be a valid variable
+// name according to rules, and its value converted by them.
+func importEnv(prefix string, vars map[string]any, rules input.Rules) (map[string]any, error) {
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		key, ok := strings.CutPrefix(name, prefix)
+		if !ok || key == "" {
+			continue
+		}
+		if err := rules.Keys.Check(key); err != nil {
+			return vars, fmt.Errorf("environment variable %s: %w", name, err)
+		}
+		if vars == nil {
+			vars = make(map[string]any)
+		}
+		vars[key] = rules.Format(value)
+	}
+	return vars, nil
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omerkaya1/gg-config/internal/input"
+)
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// scriptedInput holds the config parts provided through command line flags.
+type scriptedInput struct {
+	globals stringList
+	files   stringList
+	preCmds stringList
+	cmds    stringList
+}
+
+func (in scriptedInput) empty() bool {
+	return len(in.globals) == 0 && len(in.files) == 0 && len(in.preCmds) == 0 && len(in.cmds) == 0
+}
+
+// apply adds everything provided through flags to cfg, the variables
+// entered by rules. Globals set repeatedly are lists of their values.
+func (in scriptedInput) apply(cfg *Config, rules input.Rules) error {
+	var (
+		seen  = make(map[string]bool)
+		scope = input.GlobalScope(*cfg)
+	)
+	scope.Rules = rules
+	for _, g := range in.globals {
+		key, value, ok := strings.Cut(g, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("global %q: expected key=value", g)
+		}
+		if err := scope.Add(key, value, seen); err != nil {
+			return fmt.Errorf("global %q: %w", g, err)
+		}
+	}
+	scope.SetGlobal(cfg)
+	for _, spec := range in.files {
+		f, err := parseFileFlag(spec, rules)
+		if err != nil {
+			return fmt.Errorf("file %q: %w", spec, err)
+		}
+		cfg.Files = append(cfg.Files, f)
+	}
+	for _, line := range in.preCmds {
+		c, err := input.ParseCommand(line)
+		if err != nil {
+			return fmt.Errorf("pre-command %q: %w", line, err)
+		}
+		cfg.PreCmds = append(cfg.PreCmds, c)
+	}
+	for _, line := range in.cmds {
+		c, err := input.ParseCommand(line)
+		if err != nil {
+			return fmt.Errorf("command %q: %w", line, err)
+		}
+		cfg.Cmds = append(cfg.Cmds, c)
+	}
+	return nil
+}
+
+// parseFileFlag parses a comma separated list of key=value pairs describing a
+// file. Besides name, path, template, skip_if and format, local variables can
+// be set with the local. prefix, e.g. name=main.go,path=cmd,template=main,local.Port=8080,
+// and are entered by rules.
+func parseFileFlag(spec string, rules input.Rules) (File, error) {
+	var (
+		f     File
+		seen  = make(map[string]bool)
+		local = input.Scope{Rules: rules}
+	)
+	for _, part := range input.SplitTopLevel(spec, ',') {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return f, fmt.Errorf("expected key=value, got %q", part)
+		}
+		switch key {
+		case "name":
+			f.Name = value
+		case "path":
+			f.Path = value
+		case "template":
+			f.Template = value
+		case "skip_if":
+			f.SkipIf = value
+		case "format":
+			f.Format = value
+		default:
+			name, found := strings.CutPrefix(key, "local.")
+			if !found || name == "" {
+				return f, fmt.Errorf("unknown file parameter %q", key)
+			}
+			if err := local.Add(name, value, seen); err != nil {
+				return f, err
+			}
+		}
+	}
+	local.SetLocal(&f)
+	if f.Name == "" || f.Path == "" || f.Template == "" {
+		return f, fmt.Errorf("name, path and template are required")
+	}
+	return f, nil
+}
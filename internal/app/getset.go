@@ -0,0 +1,444 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/omerkaya1/gg-config/internal/input"
+	"github.com/omerkaya1/gg-config/internal/variables"
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+// configPath is a location inside a config, such as global.db.host or
+// files[0].template, split into its segments. files.0.template is the same
+// as files[0].template.
+type configPath []string
+
+func parseConfigPath(s string) (configPath, error) {
+	var p configPath
+	for _, part := range strings.Split(s, ".") {
+		name, rest, _ := strings.Cut(part, "[")
+		if name != "" {
+			p = append(p, name)
+		}
+		for rest != "" {
+			index, after, ok := strings.Cut(rest, "]")
+			if !ok || index == "" {
+				return nil, fmt.Errorf("invalid path %q", s)
+			}
+			p = append(p, index)
+			if rest, ok = strings.CutPrefix(after, "["); !ok && after != "" {
+				return nil, fmt.Errorf("invalid path %q", s)
+			}
+		}
+		if name == "" && !strings.HasPrefix(part, "[") || part == "" {
+			return nil, fmt.Errorf("invalid path %q", s)
+		}
+	}
+	if len(p) == 0 {
+		return nil, fmt.Errorf("invalid path %q", s)
+	}
+	return p, nil
+}
+
+// String formats p the way validation problems locate entries, as in
+// files[0].template.
+func (p configPath) String() string {
+	var b strings.Builder
+	for i, segment := range p {
+		if _, err := strconv.Atoi(segment); err == nil && i > 0 {
+			fmt.Fprintf(&b, "[%s]", segment)
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(segment)
+	}
+	return b.String()
+}
+
+// configDocument returns cfg as the generic document it is encoded as.
+func configDocument(cfg Config) (map[string]any, error) {
+	var b bytes.Buffer
+	if err := config.EncodeValue(&b, cfg, config.EncodeOptions{Format: config.FormatJSON, Compact: true}); err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	dec := json.NewDecoder(&b)
+	dec.UseNumber()
+	return doc, dec.Decode(&doc)
+}
+
+// documentConfig turns a document back into a config.
+func documentConfig(doc map[string]any) (Config, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return Config{}, err
+	}
+	return config.Decode(data, config.FormatJSON)
+}
+
+// configFields returns the names of the fields of configs, as they are
+// encoded.
+func configFields() []string {
+	t := reflect.TypeOf(Config{})
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ","); name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// checkConfigField checks that p starts with a field of configs.
+func checkConfigField(p configPath) error {
+	fields := configFields()
+	if slices.Contains(fields, p[0]) {
+		return nil
+	}
+	return fmt.Errorf("%s: unknown field %q, expected one of %s", p, p[0], strings.Join(fields, ", "))
+}
+
+// lookupPath returns the value at p in doc.
+func lookupPath(doc any, p configPath) (any, error) {
+	v := doc
+	for i, segment := range p {
+		var ok bool
+		switch node := v.(type) {
+		case map[string]any:
+			v, ok = node[segment]
+		case []any:
+			var n int
+			if n, ok = listIndex(segment, len(node)); ok {
+				v = node[n]
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s: not found", p[:i+1])
+		}
+	}
+	return v, nil
+}
+
+// setPath sets the value at p in doc to v, creating the maps missing on the
+// way. Entries of lists have to exist already.
+func setPath(doc map[string]any, p configPath, v any) error {
+	parent, last := p[:len(p)-1], p[len(p)-1]
+	var node any = doc
+	for i, segment := range parent {
+		switch n := node.(type) {
+		case map[string]any:
+			next, ok := n[segment]
+			if !ok || next == nil {
+				next = make(map[string]any)
+				n[segment] = next
+			}
+			node = next
+		case []any:
+			index, ok := listIndex(segment, len(n))
+			if !ok {
+				return fmt.Errorf("%s: not found", p[:i+1])
+			}
+			node = n[index]
+		default:
+			return fmt.Errorf("%s: %s is not a map or a list", p, p[:i])
+		}
+	}
+	switch n := node.(type) {
+	case map[string]any:
+		n[last] = v
+	case []any:
+		index, ok := listIndex(last, len(n))
+		if !ok {
+			return fmt.Errorf("%s: not found", p)
+		}
+		n[index] = v
+	default:
+		return fmt.Errorf("%s: %s is not a map or a list", p, parent)
+	}
+	return nil
+}
+
+// unsetPath removes the value at p from doc, entries of lists included.
+func unsetPath(doc map[string]any, p configPath) error {
+	parent, err := lookupPath(doc, p[:len(p)-1])
+	if len(p) == 1 {
+		parent, err = doc, nil
+	}
+	if err != nil {
+		return err
+	}
+	last := p[len(p)-1]
+	switch n := parent.(type) {
+	case map[string]any:
+		if _, ok := n[last]; !ok {
+			return fmt.Errorf("%s: not found", p)
+		}
+		delete(n, last)
+		return nil
+	case []any:
+		index, ok := listIndex(last, len(n))
+		if !ok {
+			return fmt.Errorf("%s: not found", p)
+		}
+		return setPath(doc, p[:len(p)-1], append(n[:index:index], n[index+1:]...))
+	default:
+		return fmt.Errorf("%s: not found", p)
+	}
+}
+
+func listIndex(segment string, n int) (int, bool) {
+	i, err := strconv.Atoi(segment)
+	return i, err == nil && i >= 0 && i < n
+}
+
+// readConfigFile reads the config at path along with the format it is
+// written in, for commands writing it back in place.
+func readConfigFile(path string) (Config, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, "", err
+	}
+	format := config.DetectFormat(path, data)
+	cfg, err := config.Decode(data, format)
+	if errors.Is(err, config.ErrSplitIndex) {
+		return Config{}, "", fmt.Errorf("%s is the index of a split config, edit its sections in their own files", path)
+	}
+	if err != nil {
+		return Config{}, "", fmt.Errorf("load config %s: %w", path, err)
+	}
+	return cfg, format, nil
+}
+
+// writeConfigFile writes cfg back to the config at path in format, refusing
+// configs failing validation.
+func writeConfigFile(path, format string, cfg Config) error {
+	if errs := config.Validate(cfg); len(errs) != 0 {
+		return fmt.Errorf("%s would be invalid: %w", path, errs)
+	}
+	if !config.CanEncode(format) {
+		return fmt.Errorf("%s configs cannot be written, convert %s with gg-config migrate --format first", format, path)
+	}
+	return writeConfig(path, outputOptions{format: format, indent: config.DefaultIndent}, cfg)
+}
+
+// readConfigDocument reads the config at path as a document, along with the
+// format it is written in.
+func readConfigDocument(path string) (map[string]any, string, error) {
+	cfg, format, err := readConfigFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	doc, err := configDocument(cfg)
+	return doc, format, err
+}
+
+// runGet prints the value at a path of a config.
+func runGet(fs *flag.FlagSet) func(args []string) error {
+	var (
+		asJSON bool
+	)
+	fs.BoolVar(&asJSON, "json", false, "print the value as JSON, strings included")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gg-config get [flags] config.json path")
+		fmt.Fprintln(fs.Output(), "Paths are dotted, as in global.Port, global.db.host or files[0].template.")
+		fs.PrintDefaults()
+	}
+	return func(args []string) error {
+		if err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		if fs.NArg() != 2 {
+			return usageError(fs, "get: expected a config file and a path")
+		}
+		p, err := parseConfigPath(fs.Arg(1))
+		if err != nil {
+			return fmt.Errorf("get: %w", err)
+		}
+		doc, _, err := readConfigDocument(fs.Arg(0))
+		if err != nil {
+			return fmt.Errorf("get: %w", err)
+		}
+		v, err := lookupPath(doc, p)
+		if err != nil {
+			return fmt.Errorf("get: %w", err)
+		}
+		switch val := v.(type) {
+		case string:
+			if !asJSON {
+				fmt.Println(val)
+				return nil
+			}
+		case json.Number, bool, nil:
+			fmt.Println(variables.String(val))
+			return nil
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(v)
+	}
+}
+
+// runSet sets the value at a path of a config, written back in place.
+func runSet(fs *flag.FlagSet) func(args []string) error {
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gg-config set config.json path value")
+		fmt.Fprintln(fs.Output(), "Paths are dotted, as in global.Port or files[0].template, and may be annotated with a type, as in global.Port:string.")
+		fmt.Fprintln(fs.Output(), "Values are read like the wizard reads them: [a, b] is a list and, without a type, numbers and booleans are guessed")
+		fmt.Fprintln(fs.Output(), "unless the current value is a string.")
+		fs.PrintDefaults()
+	}
+	return func(args []string) error {
+		if err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		if fs.NArg() != 3 {
+			return usageError(fs, "set: expected a config file, a path and a value")
+		}
+		path := fs.Arg(0)
+		rawPath, typ, typed := strings.Cut(fs.Arg(1), ":")
+		p, err := parseConfigPath(rawPath)
+		if err != nil {
+			return fmt.Errorf("set: %w", err)
+		}
+		if err := checkConfigField(p); err != nil {
+			return fmt.Errorf("set: %w", err)
+		}
+		doc, format, err := readConfigDocument(path)
+		if err != nil {
+			return fmt.Errorf("set: %w", err)
+		}
+		if cur, err := lookupPath(doc, p); err == nil && !typed {
+			if _, ok := cur.(string); ok {
+				typ, typed = "string", true
+			}
+		}
+		key := "value"
+		if typed {
+			key += ":" + typ
+		}
+		_, v, err := input.Rules{Keys: variableKeys}.Value(key, fs.Arg(2))
+		if err != nil {
+			return fmt.Errorf("set %s: %w", p, err)
+		}
+		if err := setPath(doc, p, v); err != nil {
+			return fmt.Errorf("set: %w", err)
+		}
+		if typ == input.SecretType {
+			if err := markSecret(doc, p); err != nil {
+				return fmt.Errorf("set: %w", err)
+			}
+		}
+		// Decoding drops what configs have no field for, such as a misspelled
+		// field of a file.
+		cfg, err := documentConfig(doc)
+		if err != nil {
+			return fmt.Errorf("set: %w", err)
+		}
+		if kept, err := configDocument(cfg); err != nil {
+			return fmt.Errorf("set: %w", err)
+		} else if _, err := lookupPath(kept, p); err != nil {
+			return fmt.Errorf("set: %s: not a field of the config", p)
+		}
+		if err := writeConfigFile(path, format, cfg); err != nil {
+			return fmt.Errorf("set: %w", err)
+		}
+		return nil
+	}
+}
+
+// markSecret lists the variable at p, a global or local variable, among
+// the secrets of the config or of its file.
+func markSecret(doc map[string]any, p configPath) error {
+	var (
+		owner any = doc
+		name  string
+	)
+	switch {
+	case len(p) >= 2 && p[0] == "global":
+		name = strings.Join(p[1:], ".")
+	case len(p) >= 4 && p[0] == "files" && p[2] == "local":
+		owner, _ = lookupPath(doc, p[:2])
+		name = strings.Join(p[3:], ".")
+	default:
+		return fmt.Errorf("%s: only variables can be secrets", p)
+	}
+	m, ok := owner.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%s: not found", p)
+	}
+	var secrets []string
+	if list, ok := m["secrets"].([]any); ok {
+		for _, s := range list {
+			secrets = append(secrets, fmt.Sprint(s))
+		}
+	}
+	secrets = input.AddSecret(secrets, name)
+	list := make([]any, len(secrets))
+	for i, s := range secrets {
+		list[i] = s
+	}
+	m["secrets"] = list
+	return nil
+}
+
+// runUnset removes the value at a path of a config, written back in place.
+func runUnset(fs *flag.FlagSet) func(args []string) error {
+	empty := fs.Bool("allow-empty-files", false, "allow removing the last file entry")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gg-config unset [flags] config.json path")
+		fmt.Fprintln(fs.Output(), "Paths are dotted, as in global.Port, or name entries of lists, as in files[1].")
+		fs.PrintDefaults()
+	}
+	return func(args []string) error {
+		if err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		if fs.NArg() != 2 {
+			return usageError(fs, "unset: expected a config file and a path")
+		}
+		path := fs.Arg(0)
+		p, err := parseConfigPath(fs.Arg(1))
+		if err != nil {
+			return fmt.Errorf("unset: %w", err)
+		}
+		before, format, err := readConfigFile(path)
+		if err != nil {
+			return fmt.Errorf("unset: %w", err)
+		}
+		doc, err := configDocument(before)
+		if err != nil {
+			return fmt.Errorf("unset: %w", err)
+		}
+		if err := unsetPath(doc, p); err != nil {
+			return fmt.Errorf("unset: %w", err)
+		}
+		cfg, err := documentConfig(doc)
+		if err != nil {
+			return fmt.Errorf("unset: %w", err)
+		}
+		// Global variables go along with their choices, computed variable and
+		// secrets, as they do with rm --global.
+		if len(p) > 1 && p[0] == "global" {
+			forgetGlobal(&cfg, strings.Join(p[1:], "."))
+		}
+		if err := checkFilesLeft(before, cfg, *empty); err != nil {
+			return fmt.Errorf("unset: %w", err)
+		}
+		if err := writeConfigFile(path, format, cfg); err != nil {
+			return fmt.Errorf("unset: %w", err)
+		}
+		return nil
+	}
+}
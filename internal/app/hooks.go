@@ -0,0 +1,304 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/omerkaya1/gg-config/internal/expr"
+	"github.com/omerkaya1/gg-config/internal/input"
+	"github.com/omerkaya1/gg-config/internal/variables"
+	"github.com/omerkaya1/gg-config/pkg/config"
+	"github.com/omerkaya1/gg-config/pkg/templates"
+)
+
+// runHooks executes the commands of the section called section in
+// declaration order, as far as their dependencies allow, streaming their
+// output. Commands failing once their retries are exhausted are handled
+// according to their failure policy: by default the first one aborts the
+// section, unless hc.keepGoing is set, in which case only the commands
+// depending on it are skipped and the failures are reported at the end.
+// The output of commands capturing it is stored in hc.captured, whose values
+// are passed on to every command as environment variables.
+func runHooks(section string, cmds []Command, hc hookContext) error {
+	order, err := config.ScheduleCommands(cmds)
+	if err != nil {
+		return fmt.Errorf("%s: %w", section, err)
+	}
+	var (
+		errs   []error
+		failed = make(map[string]bool)
+	)
+	for n, i := range order {
+		status := fmt.Sprintf("[%d/%d]", n+1, len(cmds))
+		if dep := failedDependency(cmds[i], failed); dep != "" {
+			logger.Info(fmt.Sprintf("%s skipped %s[%d], %s failed", status, section, i, dep), "event", "hook_skipped", "hook", fmt.Sprintf("%s[%d]", section, i), "failed_dependency", dep)
+			failed[cmds[i].ID] = true
+			continue
+		}
+		err := runHook(fmt.Sprintf("%s[%d]", section, i), cmds[i], status, hc)
+		if err == nil {
+			continue
+		}
+		switch cmds[i].OnFailure {
+		case config.OnFailureContinue:
+			continue
+		case config.OnFailureWarn:
+			logger.Warn(fmt.Sprintf("%s %s", status, err), "event", "hook_failed", "hook", fmt.Sprintf("%s[%d]", section, i), "error", err)
+			continue
+		}
+		if !hc.keepGoing {
+			return hookError{err}
+		}
+		errs = append(errs, err)
+		failed[cmds[i].ID] = true
+	}
+	if len(errs) != 0 {
+		return hookError{errors.Join(errs...)}
+	}
+	return nil
+}
+
+// hookError reports the commands of a config which failed, making gg-config
+// exit with exitHook.
+type hookError struct {
+	err error
+}
+
+func (e hookError) Error() string {
+	return e.err.Error()
+}
+
+func (e hookError) Unwrap() error {
+	return e.err
+}
+
+// failedDependency returns the first dependency of c found in failed.
+func failedDependency(c Command, failed map[string]bool) string {
+	for _, dep := range c.DependsOn {
+		if failed[dep] {
+			return dep
+		}
+	}
+	return ""
+}
+
+// runHook runs the command c, located at loc, retrying it as configured.
+func runHook(loc string, c Command, status string, hc hookContext) error {
+	c, err := expandCommand(c, hc.data())
+	if err != nil {
+		return fmt.Errorf("%s: %w", loc, err)
+	}
+	line := input.JoinTokens(append([]string{c.Name}, c.Args...))
+	if c.Shell {
+		line = shellScript(c)
+	}
+	// Arguments may well pass secrets on, which must not end up in logs.
+	line = redactText(line, secretValues(hc.cfg))
+	log := logger.With("hook", loc, "command", line)
+	if c.Dir != "" {
+		log.Info(fmt.Sprintf("%s %s (in %s)", status, line, c.Dir), "event", "hook_started", "dir", c.Dir)
+	} else {
+		log.Info(fmt.Sprintf("%s %s", status, line), "event", "hook_started")
+	}
+
+	if c.When != "" {
+		run, err := holdsWhen(c.When, hc.vars())
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", loc, line, err)
+		}
+		if !run {
+			log.Info(fmt.Sprintf("%s skipped, %s does not hold", status, c.When), "event", "hook_skipped", "when", c.When)
+			return nil
+		}
+	}
+
+	timeout, err := commandTimeout(c)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", loc, line, err)
+	}
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		out, err := runCommand(c, timeout, hc.captured)
+		elapsed := time.Since(start).Round(time.Millisecond)
+		if err == nil {
+			if c.Capture != "" {
+				hc.captured[c.Capture] = strings.TrimRight(out, "\r\n")
+				log.Debug(fmt.Sprintf("%s captured %s", status, c.Capture), "event", "captured", "variable", c.Capture)
+			}
+			log.Info(fmt.Sprintf("%s ok in %s", status, elapsed), "event", "hook_succeeded", "elapsed", elapsed.String())
+			return nil
+		}
+
+		var exitErr *exec.ExitError
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			log.Info(fmt.Sprintf("%s timed out after %s", status, timeout), "event", "hook_timed_out", "timeout", timeout.String())
+		case errors.As(err, &exitErr):
+			log.Info(fmt.Sprintf("%s failed with exit code %d after %s", status, exitErr.ExitCode(), elapsed), "event", "hook_failed", "exit_code", exitErr.ExitCode(), "elapsed", elapsed.String())
+		}
+		if attempt >= c.Retries {
+			return fmt.Errorf("%s %s: %w", loc, line, err)
+		}
+		log.Info(fmt.Sprintf("%s retrying (%d/%d)", status, attempt+1, c.Retries), "event", "hook_retrying", "attempt", attempt+1, "retries", c.Retries)
+	}
+}
+
+// hookContext is what commands are run with.
+type hookContext struct {
+	cfg Config
+	// file is the file whose hooks are run, if any.
+	file *File
+	// captured holds the output of the commands capturing it.
+	captured map[string]string
+	// keepGoing runs the commands not depending on failed ones even if
+	// their failure policy is to abort.
+	keepGoing bool
+}
+
+// vars returns the variables the when conditions of commands are evaluated
+// against.
+func (hc hookContext) vars() map[string]any {
+	var local map[string]any
+	if hc.file != nil {
+		local = hc.file.Local
+	}
+	return variables.ForCommands(variables.Data(hc.cfg.Global, local), hc.captured)
+}
+
+// data returns the data the templates in command arguments are executed
+// with: the globals, including the captured variables, as .Global, the
+// files as .Files and, for file hooks, the file as .File and its local
+// variables as .Local.
+func (hc hookContext) data() map[string]any {
+	data := map[string]any{
+		"Global": variables.WithCaptured(hc.cfg.Global, hc.captured),
+		"Files":  hc.cfg.Files,
+	}
+	if hc.file != nil {
+		data["File"], data["Local"] = *hc.file, hc.file.Local
+	}
+	return data
+}
+
+// expandCommand executes the templates contained in the arguments and
+// working directory of c, such as {{ .Global.ProjectDir }}.
+func expandCommand(c Command, data map[string]any) (Command, error) {
+	args := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		s, err := expandArg(fmt.Sprintf("args[%d]", i), arg, data)
+		if err != nil {
+			return c, err
+		}
+		args[i] = s
+	}
+	dir, err := expandArg("dir", c.Dir, data)
+	if err != nil {
+		return c, err
+	}
+	c.Args, c.Dir = args, dir
+	return c, nil
+}
+
+// parseArg parses s as a template if it contains any action.
+func parseArg(name, s string) (*template.Template, error) {
+	if !strings.Contains(s, "{{") {
+		return nil, nil
+	}
+	return templates.New(name).Option("missingkey=error").Parse(s)
+}
+
+func expandArg(name, s string, data map[string]any) (string, error) {
+	t, err := parseArg(name, s)
+	if err != nil || t == nil {
+		return s, err
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func holdsWhen(when string, vars map[string]any) (bool, error) {
+	c, err := expr.Parse("condition", when)
+	if err != nil {
+		return false, err
+	}
+	return c.Holds(vars)
+}
+
+// shellScript returns the script run by a shell command.
+func shellScript(c Command) string {
+	return strings.Join(append([]string{c.Name}, c.Args...), " ")
+}
+
+// commandTimeout returns the parsed timeout of c, zero meaning none.
+func commandTimeout(c Command) (time.Duration, error) {
+	if c.Timeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(c.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout: %w", err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid timeout: %s", c.Timeout)
+	}
+	return d, nil
+}
+
+// waitDelay is how long a command timing out is waited for once killed
+// before its output is closed regardless of processes still holding it.
+const waitDelay = time.Second
+
+// runCommand runs c once, killing it if it takes longer than timeout, and
+// returns its standard output if c captures it.
+func runCommand(c Command, timeout time.Duration, captured map[string]string) (string, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var cmd *exec.Cmd
+	switch {
+	case !c.Shell:
+		cmd = exec.CommandContext(ctx, c.Name, c.Args...)
+	case runtime.GOOS == "windows":
+		cmd = exec.CommandContext(ctx, "cmd.exe", "/C", shellScript(c))
+	default:
+		cmd = exec.CommandContext(ctx, "sh", "-c", shellScript(c))
+	}
+	cmd.Dir = c.Dir
+	killProcessGroup(cmd)
+	cmd.WaitDelay = waitDelay
+	if len(c.Env) != 0 || len(captured) != 0 {
+		cmd.Env = os.Environ()
+		for _, k := range sortedKeys(captured) {
+			if variables.IsEnvName(k) {
+				cmd.Env = append(cmd.Env, k+"="+captured[k])
+			}
+		}
+		for _, k := range sortedKeys(c.Env) {
+			cmd.Env = append(cmd.Env, k+"="+c.Env[k])
+		}
+	}
+	var stdout strings.Builder
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if c.Capture != "" {
+		cmd.Stdout = &stdout
+	}
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	return stdout.String(), err
+}
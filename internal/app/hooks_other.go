@@ -0,0 +1,9 @@
+//go:build !unix
+
+package app
+
+import "os/exec"
+
+// killProcessGroup leaves cmd as it is: only the command itself is killed
+// on cancellation, and waitDelay bounds the wait for its children.
+func killProcessGroup(cmd *exec.Cmd) {}
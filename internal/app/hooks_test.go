@@ -0,0 +1,48 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRunCommandTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the commands are run through sh")
+	}
+	tests := []struct {
+		name string
+		c    Command
+	}{
+		{"shell", Command{Name: "sleep 5; echo x", Shell: true}},
+		{"shell capturing", Command{Name: "sleep 5; echo x", Shell: true, Capture: "Out"}},
+		{"command capturing", Command{Name: "sh", Args: []string{"-c", "sleep 5; echo x"}, Capture: "Out"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			_, err := runCommand(tt.c, 200*time.Millisecond, nil)
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("took %s, want the timeout enforced", elapsed)
+			}
+		})
+	}
+}
+
+func TestRunCommandCapture(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the commands are run through sh")
+	}
+	out, err := runCommand(Command{Name: "echo captured", Shell: true, Capture: "Out"}, time.Second, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "captured\n" {
+		t.Errorf("got %q, want %q", out, "captured\n")
+	}
+}
@@ -0,0 +1,241 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/omerkaya1/gg-config/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	importCookiecutter = "cookiecutter"
+	importPlop         = "plop"
+	importYeoman       = "yeoman"
+)
+
+// runImport converts the configuration of another scaffolding tool into a
+// gg-config config.
+func runImport(fs *flag.FlagSet) func(args []string) error {
+	var (
+		path string
+		opts outputOptions
+	)
+	addOutputFlags(fs, &path, &opts)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gg-config import [flags] cookiecutter|plop|yeoman file")
+		fs.PrintDefaults()
+	}
+	return func(args []string) error {
+		if err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		if fs.NArg() != 2 {
+			return usageError(fs, "import: expected a source kind and a file")
+		}
+		if err := opts.validate(); err != nil {
+			return fmt.Errorf("import: %w", err)
+		}
+
+		if err := checkOverwrite(path); err != nil {
+			return fmt.Errorf("import: %w", err)
+		}
+		var (
+			cfg Config
+			err error
+		)
+		switch kind := fs.Arg(0); kind {
+		case importCookiecutter:
+			cfg, err = importCookiecutterFile(fs.Arg(1))
+		case importPlop:
+			cfg, err = importPlopFile(fs.Arg(1))
+		case importYeoman:
+			cfg, err = importYeomanFile(fs.Arg(1))
+		default:
+			return fmt.Errorf("import: unknown source kind: %s", kind)
+		}
+		if err != nil {
+			return fmt.Errorf("import %s: %w", fs.Arg(0), err)
+		}
+		return writeConfig(path, opts, cfg)
+	}
+}
+
+// importCookiecutterFile maps the context variables of a cookiecutter.json
+// onto global variables. Choice variables take their first (default) option
+// and private variables, prefixed with an underscore, are skipped.
+func importCookiecutterFile(path string) (Config, error) {
+	var (
+		cfg     Config
+		context map[string]any
+	)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err = decodeJSON(data, &context); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for k, v := range context {
+		if strings.HasPrefix(k, "_") {
+			continue
+		}
+		if choices, ok := v.([]any); ok {
+			if len(choices) == 0 {
+				continue
+			}
+			v = choices[0]
+		}
+		if cfg.Global == nil {
+			cfg.Global = make(map[string]any, len(context))
+		}
+		cfg.Global[k] = config.NormalizeValue(v)
+	}
+	return cfg, nil
+}
+
+type (
+	plopfile struct {
+		Generators    map[string]plopGenerator `yaml:"generators"`
+		plopGenerator `yaml:",inline"`
+	}
+	plopGenerator struct {
+		Prompts []plopPrompt `yaml:"prompts"`
+		Actions []plopAction `yaml:"actions"`
+	}
+	plopPrompt struct {
+		Name    string `yaml:"name"`
+		Default any    `yaml:"default"`
+		Choices []any  `yaml:"choices"`
+	}
+	plopAction struct {
+		Type          string `yaml:"type"`
+		Path          string `yaml:"path"`
+		Destination   string `yaml:"destination"`
+		TemplateFile  string `yaml:"templateFile"`
+		TemplateFiles any    `yaml:"templateFiles"`
+	}
+)
+
+// importPlopFile converts a JSON or YAML export of plop generators, either a
+// single generator or a "generators" map keyed by name, into a config. Prompts
+// become global variables holding their defaults and add/addMany actions
+// become files. Plop has no notion of post-generation commands.
+func importPlopFile(file string) (Config, error) {
+	var (
+		cfg Config
+		pf  plopfile
+	)
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return cfg, err
+	}
+	if err = yaml.Unmarshal(data, &pf); err != nil {
+		return cfg, fmt.Errorf("%s: %w", file, err)
+	}
+
+	generators := []plopGenerator{pf.plopGenerator}
+	names := make([]string, 0, len(pf.Generators))
+	for name := range pf.Generators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		generators = append(generators, pf.Generators[name])
+	}
+
+	for _, g := range generators {
+		for _, p := range g.Prompts {
+			if p.Name == "" {
+				continue
+			}
+			v := p.Default
+			if v == nil && len(p.Choices) != 0 {
+				v = p.Choices[0]
+			}
+			if v == nil {
+				v = ""
+			}
+			if cfg.Global == nil {
+				cfg.Global = make(map[string]any)
+			}
+			cfg.Global[p.Name] = config.NormalizeValue(v)
+		}
+		for i, a := range g.Actions {
+			var f File
+			switch a.Type {
+			case "add":
+				f = File{
+					Name:     path.Base(a.Path),
+					Path:     path.Dir(a.Path),
+					Template: a.TemplateFile,
+				}
+			case "addMany":
+				pattern, _ := a.TemplateFiles.(string)
+				f = File{
+					Name:     path.Base(pattern),
+					Path:     a.Destination,
+					Template: pattern,
+				}
+			default:
+				continue
+			}
+			if f.Template == "" {
+				return cfg, fmt.Errorf("action %d: missing template", i)
+			}
+			cfg.Files = append(cfg.Files, f)
+		}
+	}
+	return cfg, nil
+}
+
+// importYeomanFile maps the stored answers of a .yo-rc.json onto global
+// variables. Every generator namespace is merged, with promptValues flattened
+// into the namespace itself.
+func importYeomanFile(file string) (Config, error) {
+	var (
+		cfg Config
+		rc  map[string]map[string]any
+	)
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return cfg, err
+	}
+	if err = decodeJSON(data, &rc); err != nil {
+		return cfg, fmt.Errorf("%s: %w", file, err)
+	}
+
+	for _, values := range rc {
+		if prompts, ok := values["promptValues"].(map[string]any); ok {
+			delete(values, "promptValues")
+			for k, v := range prompts {
+				values[k] = v
+			}
+		}
+		for k, v := range values {
+			if cfg.Global == nil {
+				cfg.Global = make(map[string]any)
+			}
+			cfg.Global[k] = config.NormalizeValue(v)
+		}
+	}
+	return cfg, nil
+}
+
+// decodeJSON decodes data into v, keeping numbers as json.Number so that
+// config.NormalizeValue sees their exact text.
+func decodeJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
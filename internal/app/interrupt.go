@@ -0,0 +1,66 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"golang.org/x/term"
+)
+
+// errInterrupted is returned by what stops on SIGINT or SIGTERM itself,
+// such as the full-screen wizard, making gg-config exit with
+// exitInterrupted.
+var errInterrupted = errors.New("interrupted")
+
+// interruptible makes SIGINT and SIGTERM, until stop is called, restore the
+// terminal as it was, hidden input having disabled echo for instance, run
+// cleanup and exit with exitInterrupted, rather than kill gg-config
+// half-way. The error cleanup returns is logged in place of errInterrupted,
+// which it should wrap. Once cleanup started, stop waits for the exit.
+func interruptible(cleanup func() error) (stop func()) {
+	var state *term.State
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		state, _ = term.GetState(fd)
+	}
+	var (
+		mu      sync.Mutex
+		signals = make(chan os.Signal, 1)
+		done    = make(chan struct{})
+	)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			mu.Lock()
+			select {
+			case <-done:
+				// stop won the race, the command is done.
+				mu.Unlock()
+				return
+			default:
+			}
+			if state != nil {
+				term.Restore(int(os.Stdin.Fd()), state)
+			}
+			// The ^C echoed by the terminal ends no line.
+			fmt.Fprintln(os.Stderr)
+			err := cleanup()
+			if err == nil {
+				err = errInterrupted
+			}
+			logger.Error(err.Error(), "event", "interrupted", "signal", sig.String())
+			os.Exit(exitInterrupted)
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		mu.Lock()
+		defer mu.Unlock()
+		close(done)
+	}
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+// variableKeys are the rules in effect, configured through the key-pattern
+// and reserved-keys flags.
+var variableKeys config.KeyRules
+
+// keyRuleFlags holds the raw values of the flags configuring variableKeys.
+type keyRuleFlags struct {
+	pattern  string
+	reserved string
+}
+
+func (f *keyRuleFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.pattern, "key-pattern", config.DefaultKeyPattern, "regular expression variable names have to match")
+	fs.StringVar(&f.reserved, "reserved-keys", strings.Join(config.DefaultReservedKeys, ","), "comma separated variable names that may not be used")
+}
+
+// apply installs the configured rules as variableKeys.
+func (f keyRuleFlags) apply() error {
+	r, err := config.NewKeyRules(f.pattern, strings.Split(f.reserved, ","))
+	if err != nil {
+		return err
+	}
+	variableKeys = r
+	return nil
+}
@@ -0,0 +1,264 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/omerkaya1/gg-config/internal/input"
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+// lintRule is a single style or safety check. Unlike validation problems, lint
+// findings do not make a config unusable.
+type lintRule struct {
+	name        string
+	description string
+	severity    severity
+	// enabled tells whether the rule runs unless explicitly disabled.
+	enabled bool
+	check   func(cfg Config) []lintFinding
+}
+
+type lintFinding struct {
+	location string
+	message  string
+}
+
+var lintRules = []lintRule{
+	{
+		name:        "empty-local",
+		description: "file entries with an empty local variables map",
+		severity:    severityInfo,
+		check:       lintEmptyLocal,
+	},
+	{
+		name:        "absolute-path",
+		description: "file paths that are absolute and tie the config to one machine",
+		severity:    severityWarning,
+		enabled:     true,
+		check:       lintAbsolutePath,
+	},
+	{
+		name:        "shell-metachars",
+		description: "commands containing shell syntax, which is passed to them literally",
+		severity:    severityWarning,
+		enabled:     true,
+		check:       lintShellMetachars,
+	},
+	{
+		name:        "global-secret",
+		description: "globals that look like passwords, tokens or keys but are not declared secrets",
+		severity:    severityError,
+		enabled:     true,
+		check:       lintGlobalSecret,
+	},
+}
+
+func findLintRule(name string) (lintRule, bool) {
+	for _, r := range lintRules {
+		if r.name == name {
+			return r, true
+		}
+	}
+	return lintRule{}, false
+}
+
+// lintToggle enables or disables a comma separated list of rules, "all"
+// standing for every rule.
+type lintToggle struct {
+	names  string
+	enable bool
+}
+
+// selectLintRules returns the rules enabled by default, adjusted by toggles in
+// the order they were given.
+func selectLintRules(toggles []lintToggle) ([]lintRule, error) {
+	selected := make(map[string]bool)
+	for _, r := range lintRules {
+		selected[r.name] = r.enabled
+	}
+	for _, t := range toggles {
+		for _, name := range strings.Split(t.names, ",") {
+			name = strings.TrimSpace(name)
+			if name == "all" {
+				for k := range selected {
+					selected[k] = t.enable
+				}
+				continue
+			}
+			if _, ok := findLintRule(name); !ok {
+				return nil, fmt.Errorf("unknown lint rule: %s", name)
+			}
+			selected[name] = t.enable
+		}
+	}
+
+	var rules []lintRule
+	for _, r := range lintRules {
+		if selected[r.name] {
+			rules = append(rules, r)
+		}
+	}
+	return rules, nil
+}
+
+// lintConfig runs rules against cfg, reporting each finding with the severity
+// of the rule that produced it.
+func lintConfig(cfg Config, rules []lintRule) []problem {
+	var problems []problem
+	for _, r := range rules {
+		for _, f := range r.check(cfg) {
+			problems = append(problems, problem{Severity: r.severity, Path: f.location, Message: fmt.Sprintf("%s [%s]", f.message, r.name)})
+		}
+	}
+	return problems
+}
+
+func lintEmptyLocal(cfg Config) []lintFinding {
+	var findings []lintFinding
+	for i, f := range cfg.Files {
+		if f.Local != nil && len(f.Local) == 0 {
+			findings = append(findings, lintFinding{fmt.Sprintf("files[%d].local", i), "local variables map is empty"})
+		}
+	}
+	return findings
+}
+
+func lintAbsolutePath(cfg Config) []lintFinding {
+	var findings []lintFinding
+	for i, f := range cfg.Files {
+		if config.IsAbsPath(f.Path) {
+			findings = append(findings, lintFinding{fmt.Sprintf("files[%d].path", i), fmt.Sprintf("path %q is absolute", f.Path)})
+		}
+	}
+	return findings
+}
+
+func lintShellMetachars(cfg Config) []lintFinding {
+	var findings []lintFinding
+	for _, list := range []struct {
+		section string
+		cmds    []Command
+	}{{"pre_commands", cfg.PreCmds}, {"commands", cfg.Cmds}} {
+		for i, c := range list.cmds {
+			if c.Shell {
+				continue
+			}
+			for j, arg := range append([]string{c.Name}, c.Args...) {
+				if !input.HasShellSyntax(arg) {
+					continue
+				}
+				loc := fmt.Sprintf("%s[%d].name", list.section, i)
+				if j > 0 {
+					loc = fmt.Sprintf("%s[%d].args[%d]", list.section, i, j-1)
+				}
+				findings = append(findings, lintFinding{loc, fmt.Sprintf("%q contains shell syntax, but the command is not run through a shell", arg)})
+			}
+		}
+	}
+	return findings
+}
+
+var (
+	secretKeyPattern   = regexp.MustCompile(`(?i)(passw(or)?d|secret|token|api[_-]?key|private[_-]?key|credential)`)
+	secretValuePattern = regexp.MustCompile(`AKIA[0-9A-Z]{16}|gh[pousr]_[A-Za-z0-9]{36}|xox[abprs]-[A-Za-z0-9-]+|-----BEGIN [A-Z ]*PRIVATE KEY-----`)
+)
+
+func lintGlobalSecret(cfg Config) []lintFinding {
+	return lintSecretVars("global", "", cfg.Global, cfg.Secrets)
+}
+
+// lintSecretVars looks for the variables of vars, nested ones included, that
+// look like secrets without being listed in secrets. Their names are
+// prefixed with prefix, and their locations with loc.
+func lintSecretVars(loc, prefix string, vars map[string]any, secrets []string) []lintFinding {
+	var findings []lintFinding
+	for _, k := range sortedKeys(vars) {
+		name := prefix + k
+		if nested, ok := vars[k].(map[string]any); ok {
+			findings = append(findings, lintSecretVars(loc, name+".", nested, secrets)...)
+			continue
+		}
+		s, ok := vars[k].(string)
+		if !ok || s == "" || input.IsSecret(secrets, name) {
+			continue
+		}
+		switch {
+		case secretValuePattern.MatchString(s):
+			findings = append(findings, lintFinding{loc + "." + name, "value looks like a credential"})
+		case secretKeyPattern.MatchString(k):
+			findings = append(findings, lintFinding{loc + "." + name, fmt.Sprintf("key %q suggests a secret stored in plain text", k)})
+		}
+	}
+	return findings
+}
+
+// runLint checks a config file against the selected lint rules.
+func runLint(fs *flag.FlagSet) func(args []string) error {
+	var (
+		toggles   []lintToggle
+		failOn    string
+		listRules bool
+	)
+	fs.Func("enable", "enable lint rules, or all of them with \"all\" (repeatable, comma separated)", func(v string) error {
+		toggles = append(toggles, lintToggle{v, true})
+		return nil
+	})
+	fs.Func("disable", "disable lint rules, or all of them with \"all\" (repeatable, comma separated)", func(v string) error {
+		toggles = append(toggles, lintToggle{v, false})
+		return nil
+	})
+	fs.StringVar(&failOn, "fail-on", "error", "lowest severity making lint fail: error, warning or info")
+	fs.BoolVar(&listRules, "rules", false, "list the available rules and exit")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gg-config lint [flags] config.json")
+		fs.PrintDefaults()
+	}
+	return func(args []string) error {
+		if err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		if listRules {
+			for _, r := range lintRules {
+				state := "off"
+				if r.enabled {
+					state = "on"
+				}
+				fmt.Printf("%-16s %-8s %-4s %s\n", r.name, r.severity, state, r.description)
+			}
+			return nil
+		}
+		if fs.NArg() != 1 {
+			return usageError(fs, "lint: expected exactly one config file")
+		}
+		threshold, err := config.ParseSeverity(failOn)
+		if err != nil {
+			return fmt.Errorf("lint: %w", err)
+		}
+		rules, err := selectLintRules(toggles)
+		if err != nil {
+			return fmt.Errorf("lint: %w", err)
+		}
+
+		path := fs.Arg(0)
+		cfg, err := config.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		var failed int
+		for _, p := range lintConfig(cfg, rules) {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", path, p)
+			if p.Severity <= threshold {
+				failed++
+			}
+		}
+		if failed != 0 {
+			return &exitError{code: exitInvalid, err: fmt.Errorf("lint: %d finding(s) at or above %s in %s", failed, threshold, path)}
+		}
+		return nil
+	}
+}
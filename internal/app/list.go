@@ -0,0 +1,189 @@
+package app
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/omerkaya1/gg-config/internal/input"
+	"github.com/omerkaya1/gg-config/internal/variables"
+	"github.com/omerkaya1/gg-config/pkg/config"
+	"github.com/omerkaya1/gg-config/pkg/templates"
+)
+
+// listing is the summary of a config printed by the list command.
+type listing struct {
+	Globals  []listedGlobal  `json:"globals" yaml:"globals"`
+	Files    []listedFile    `json:"files" yaml:"files"`
+	Commands []listedCommand `json:"commands" yaml:"commands"`
+}
+
+type listedGlobal struct {
+	Name string `json:"name" yaml:"name"`
+	// Type is the kind of the value, as in template hints, or secret or
+	// computed.
+	Type  string `json:"type" yaml:"type"`
+	Value any    `json:"value" yaml:"value"`
+	// Choices are the values the variable is restricted to, if any.
+	Choices []string `json:"choices,omitempty" yaml:"choices,omitempty"`
+}
+
+type listedFile struct {
+	Target   string   `json:"target" yaml:"target"`
+	Template string   `json:"template" yaml:"template"`
+	Locals   []string `json:"locals,omitempty" yaml:"locals,omitempty"`
+	SkipIf   string   `json:"skip_if,omitempty" yaml:"skip_if,omitempty"`
+}
+
+type listedCommand struct {
+	// Section is pre_commands or commands.
+	Section string `json:"section" yaml:"section"`
+	Index   int    `json:"index" yaml:"index"`
+	ID      string `json:"id,omitempty" yaml:"id,omitempty"`
+	Command string `json:"command" yaml:"command"`
+	Dir     string `json:"dir,omitempty" yaml:"dir,omitempty"`
+}
+
+// listConfig summarizes cfg, the values of secrets redacted.
+func listConfig(cfg Config) listing {
+	l := listing{Globals: []listedGlobal{}, Files: []listedFile{}, Commands: []listedCommand{}}
+	vars := variables.Flatten(cfg.Global)
+	names := sortedKeys(vars)
+	for name := range cfg.Computed {
+		if _, ok := vars[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g := listedGlobal{Name: name, Choices: cfg.Choices[name]}
+		switch expr, computed := cfg.Computed[name]; {
+		case holdsSecret(cfg.Secrets, name):
+			g.Type, g.Value = input.SecretType, input.Redacted
+		case computed:
+			g.Type, g.Value = "computed", expr
+		default:
+			g.Type, g.Value = templates.TypeOf(vars[name]), vars[name]
+		}
+		l.Globals = append(l.Globals, g)
+	}
+	for _, f := range cfg.Files {
+		l.Files = append(l.Files, listedFile{
+			Target:   filepath.ToSlash(f.Target()),
+			Template: f.Template,
+			Locals:   sortedKeys(variables.Flatten(f.Local)),
+			SkipIf:   f.SkipIf,
+		})
+	}
+	for _, list := range []struct {
+		section string
+		cmds    []Command
+	}{{"pre_commands", cfg.PreCmds}, {"commands", cfg.Cmds}} {
+		for i, c := range list.cmds {
+			l.Commands = append(l.Commands, listedCommand{
+				Section: list.section,
+				Index:   i,
+				ID:      c.ID,
+				Command: describeItem(c),
+				Dir:     c.Dir,
+			})
+		}
+	}
+	return l
+}
+
+// writeListing writes l as aligned tables, one per section.
+func writeListing(w io.Writer, l listing) error {
+	bw := bufio.NewWriter(w)
+	tw := tabwriter.NewWriter(bw, 0, 4, 2, ' ', 0)
+	table := func(title, header string, rows int, row func(i int)) {
+		if rows == 0 {
+			fmt.Fprintf(tw, "%s: none\n", title)
+			return
+		}
+		fmt.Fprintf(tw, "%s:\n  %s\n", title, header)
+		for i := 0; i < rows; i++ {
+			row(i)
+		}
+	}
+	table("Globals", "NAME\tTYPE\tVALUE", len(l.Globals), func(i int) {
+		g := l.Globals[i]
+		value := variables.Display(g.Value)
+		if g.Type == "computed" || g.Type == input.SecretType {
+			value = fmt.Sprint(g.Value)
+		}
+		if len(g.Choices) != 0 {
+			value += " (one of " + strings.Join(g.Choices, "|") + ")"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", g.Name, g.Type, value)
+	})
+	fmt.Fprintln(tw)
+	table("Files", "TARGET\tTEMPLATE\tLOCALS\tSKIP IF", len(l.Files), func(i int) {
+		f := l.Files[i]
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", f.Target, f.Template, orDash(strings.Join(f.Locals, ", ")), orDash(f.SkipIf))
+	})
+	fmt.Fprintln(tw)
+	table("Commands", "ENTRY\tID\tCOMMAND\tDIR", len(l.Commands), func(i int) {
+		c := l.Commands[i]
+		fmt.Fprintf(tw, "  %s[%d]\t%s\t%s\t%s\n", c.Section, c.Index, orDash(c.ID), c.Command, orDash(c.Dir))
+	})
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// orDash returns s, or - if it is empty, for table cells.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// runList prints the globals, files and commands of a config.
+func runList(fs *flag.FlagSet) func(args []string) error {
+	var (
+		asJSON bool
+		format string
+	)
+	fs.BoolVar(&asJSON, "json", false, "print the listing as JSON; same as --format json")
+	fs.StringVar(&format, "format", "", "print the listing in a config format instead of tables: "+strings.Join(config.EncodeFormats(), ", "))
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gg-config list [flags] config.json")
+		fs.PrintDefaults()
+	}
+	return func(args []string) error {
+		if err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return usageError(fs, "list: expected exactly one config file")
+		}
+		if asJSON {
+			format = config.FormatJSON
+		}
+		if format != "" && !config.CanEncode(format) {
+			return usageError(fs, "list: unsupported output format: %s, expected one of %s", format, strings.Join(config.EncodeFormats(), ", "))
+		}
+		cfg, err := config.ReadFile(fs.Arg(0))
+		if err != nil {
+			return fmt.Errorf("list: %w", err)
+		}
+
+		l := listConfig(cfg)
+		if format == "" {
+			return writeListing(os.Stdout, l)
+		}
+		if err := config.EncodeValue(os.Stdout, l, config.EncodeOptions{Format: format, Indent: config.DefaultIndent}); err != nil {
+			return fmt.Errorf("list: %w", err)
+		}
+		return nil
+	}
+}
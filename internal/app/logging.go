@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// logger reports what the commands do, such as the files written and the
+// hooks run, on stderr. Its level and format follow the --verbose, --quiet
+// and --log-format flags every command accepts.
+var logger = slog.New(newPlainHandler(os.Stderr, slog.LevelInfo))
+
+// Log formats accepted by --log-format.
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// logOptions holds the logging flags of a command.
+type logOptions struct {
+	verbose bool
+	quiet   bool
+	format  string
+}
+
+func (o *logOptions) register(fs *flag.FlagSet) {
+	fs.BoolVar(&o.verbose, "verbose", false, "also log debugging details")
+	fs.BoolVar(&o.quiet, "quiet", false, "only log warnings and errors")
+	fs.StringVar(&o.format, "log-format", logFormatText, "log format: text, or json for one object per line")
+}
+
+// apply sets up logger according to o.
+func (o logOptions) apply() error {
+	level := slog.LevelInfo
+	switch {
+	case o.verbose && o.quiet:
+		return &exitError{code: exitUsage, err: errors.New("--verbose and --quiet are mutually exclusive")}
+	case o.verbose:
+		level = slog.LevelDebug
+	case o.quiet:
+		level = slog.LevelWarn
+	}
+	switch o.format {
+	case logFormatText:
+		logger = slog.New(newPlainHandler(os.Stderr, level))
+	case logFormatJSON:
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+	default:
+		return &exitError{code: exitUsage, err: fmt.Errorf("unsupported log format: %s, expected %s or %s", o.format, logFormatText, logFormatJSON)}
+	}
+	return nil
+}
+
+// plainHandler writes records the way gg-config always printed them: the
+// message alone, marked if it is a warning or an error. Attributes are left
+// to the JSON format.
+type plainHandler struct {
+	mu    *sync.Mutex
+	w     io.Writer
+	level slog.Level
+}
+
+func newPlainHandler(w io.Writer, level slog.Level) *plainHandler {
+	return &plainHandler{mu: new(sync.Mutex), w: w, level: level}
+}
+
+func (h *plainHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *plainHandler) Handle(_ context.Context, r slog.Record) error {
+	msg := r.Message
+	switch {
+	case r.Level >= slog.LevelError:
+		msg = "error: " + msg
+	case r.Level >= slog.LevelWarn:
+		msg = "warning: " + msg
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := fmt.Fprintln(h.w, msg)
+	return err
+}
+
+func (h *plainHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *plainHandler) WithGroup(string) slog.Handler { return h }
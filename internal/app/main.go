@@ -0,0 +1,389 @@
+// Package app implements the gg-config command: the wizard assembling
+// configs and the subcommands acting upon them. The packages beneath pkg
+// expose parts of it as a library.
+package app
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/omerkaya1/gg-config/internal/input"
+	"github.com/omerkaya1/gg-config/pkg/config"
+	"github.com/omerkaya1/gg-config/pkg/wizard"
+	"golang.org/x/term"
+)
+
+// The sections of a config are the ones the wizard asks for.
+const (
+	globals = wizard.Globals
+	files   = wizard.Files
+	preCmds = wizard.PreCommands
+	cmds    = wizard.Commands
+)
+
+// The config layout is defined by the config package; these aliases keep
+// the wizard's code short.
+type (
+	Config      = config.Config
+	File        = config.File
+	Command     = config.Command
+	Environment = config.Environment
+)
+
+// runInit runs the wizard assembling a new config.
+func runInit(fs *flag.FlagSet) func(args []string) error {
+	return runConfigWizard(fs, "init")
+}
+
+// runEdit runs the wizard on an existing config, written back in place
+// unless -o is given.
+func runEdit(fs *flag.FlagSet) func(args []string) error {
+	return runConfigWizard(fs, "edit")
+}
+
+// runConfigWizard assembles a config through the wizard, or through answers
+// and flags replacing it, and writes it out. Edit starts from an existing
+// config, as does init with --edit.
+func runConfigWizard(fs *flag.FlagSet, name string) func(args []string) error {
+	var (
+		edit    string
+		path    string
+		split   bool
+		tee     bool
+		flagged scriptedInput
+		answer  string
+		envPfx  string
+		resume  bool
+		plain   bool
+		defCfg  string
+		noCol   bool
+		tplDir  string
+		strict  bool
+		noInf   bool
+		port    bool
+		empty   bool
+		maxLn   int
+		keys    keyRuleFlags
+		inPlace bool
+		defs    Config
+		opts    outputOptions
+		output  Config
+		err     error
+	)
+
+	addOutputFlags(fs, &path, &opts)
+	fs.BoolVar(&split, "split", false, "write globals, files and commands into separate files inside the output directory")
+	fs.BoolVar(&tee, "tee", false, "also print the config written to the output file to stdout")
+	if name == "init" {
+		fs.StringVar(&edit, "edit", "", "existing config to edit; same as gg-config edit")
+	}
+	fs.Var(&flagged.globals, "global", "global variable as key=value or key:type=value, e.g. token:secret=... or license:choice=MIT|GPL-3.0; repeatable, skips the wizard")
+	fs.Var(&flagged.files, "file", "file as name=...,path=...,template=...[,skip_if=...][,format=...][,local.key=value]; repeatable, skips the wizard")
+	fs.Var(&flagged.preCmds, "pre-cmd", "pre-generation command, e.g. \"mkdir -p build\"; repeatable, skips the wizard")
+	fs.Var(&flagged.cmds, "cmd", "post-processing command, e.g. \"go fmt ./...\"; repeatable, skips the wizard")
+	fs.StringVar(&answer, "answers", "", "YAML or JSON file with predetermined wizard answers, - for stdin; skips the wizard")
+	fs.StringVar(&envPfx, "env-prefix", "", "import environment variables with this prefix into the global variables")
+	fs.BoolVar(&resume, "resume", false, "continue the previously interrupted wizard session writing the same output")
+	fs.BoolVar(&plain, "plain", false, "use line-by-line prompts instead of the full-screen wizard")
+	fs.StringVar(&defCfg, "defaults", "", "config whose values are offered as defaults by the wizard")
+	fs.BoolVar(&noCol, "no-color", false, "disable colored wizard output; NO_COLOR is respected as well")
+	fs.StringVar(&tplDir, "templates-dir", "", "directory whose files are offered as template choices")
+	fs.BoolVar(&strict, "strict", false, "treat warnings as errors and refuse to write a config failing validation")
+	fs.BoolVar(&noInf, "no-infer", false, "keep values without a type annotation as strings instead of guessing booleans and numbers")
+	fs.IntVar(&maxLn, "max-line-size", wizard.DefaultMaxLineSize, "length in bytes of the longest answer line read by the wizard")
+	fs.BoolVar(&empty, "allow-empty-files", false, "accept configs without files, which are otherwise an error")
+	fs.BoolVar(&port, "portable-paths", false, "store the paths and names of files slash separated and cleaned, the form every system reads the same")
+	keys.register(fs)
+	fs.Usage = func() {
+		if name == "edit" {
+			fmt.Fprintln(fs.Output(), "usage: gg-config edit config.json [flags]")
+		} else {
+			fmt.Fprintln(fs.Output(), "usage: gg-config [init] [flags]")
+		}
+		fs.PrintDefaults()
+	}
+	return func(args []string) error {
+		// The config of edit may come first, as in edit config.json -o
+		// out.json.
+		if name == "edit" && len(args) != 0 && !strings.HasPrefix(args[0], "-") {
+			edit, args = args[0], args[1:]
+		}
+		if err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		switch {
+		case name == "edit" && edit == "" && fs.NArg() == 1:
+			edit = fs.Arg(0)
+		case name == "edit" && (edit == "" || fs.NArg() != 0):
+			return usageError(fs, "edit: expected exactly one config file")
+		case fs.NArg() != 0:
+			return usageError(fs, "%s: unexpected arguments: %s", name, strings.Join(fs.Args(), " "))
+		}
+
+		if maxLn < 1 {
+			return usageError(fs, "%s: invalid --max-line-size: %d", name, maxLn)
+		}
+		if err = keys.apply(); err != nil {
+			return err
+		}
+		rules := input.Rules{Keys: variableKeys, NoInfer: noInf}
+		var tplNames []string
+		if tplDir != "" {
+			if tplNames, err = templateNames(tplDir); err != nil {
+				return err
+			}
+		}
+
+		if err = opts.validate(); err != nil {
+			return err
+		}
+		if split && !isSplitFormat(opts.format) {
+			return &exitError{code: exitUsage, err: fmt.Errorf("split output is not supported for format: %s", opts.format)}
+		}
+		if split && tee {
+			return &exitError{code: exitUsage, err: errors.New("tee is not supported together with split output")}
+		}
+		if edit != "" {
+			if output, err = config.ReadFile(edit); err != nil {
+				return err
+			}
+			switch {
+			case path != "" || split:
+			case config.IsSplitIndex(edit):
+				// Split configs are written back as such, in the format of
+				// their index unless told otherwise.
+				if tee {
+					return &exitError{code: exitUsage, err: errors.New("tee is not supported together with split output")}
+				}
+				if !flagPassed(fs, "format", "f") {
+					opts.format = config.DetectFormat(edit, nil)
+				}
+				if !isSplitFormat(opts.format) {
+					return fmt.Errorf("split output is not supported for format: %s", opts.format)
+				}
+				split, path, inPlace = true, filepath.Dir(edit), true
+			default:
+				path, inPlace = edit, true
+			}
+		}
+		// Editing a config in place replaces it on purpose.
+		if !inPlace && (edit == "" || filepath.Clean(path) != filepath.Clean(edit)) {
+			targets := []string{path}
+			if split {
+				targets = splitPaths(path, opts.format)
+			}
+			for _, target := range targets {
+				if err = checkOverwrite(target); err != nil {
+					return fmt.Errorf("%s: %w", name, err)
+				}
+			}
+		}
+
+		if defCfg != "" {
+			if defs, err = config.ReadFile(defCfg); err != nil {
+				return err
+			}
+			output = input.WithDefaults(output, defs)
+		}
+		if envPfx != "" {
+			if output.Global, err = importEnv(envPfx, output.Global, rules); err != nil {
+				return fmt.Errorf("failed to import environment variables: %w", err)
+			}
+		}
+		if answer != "" {
+			a, err := loadAnswers(answer)
+			if err != nil {
+				return err
+			}
+			if err = a.apply(&output, rules); err != nil {
+				return fmt.Errorf("failed to process answers: %w", err)
+			}
+		}
+		if !flagged.empty() {
+			if err = flagged.apply(&output, rules); err != nil {
+				return fmt.Errorf("failed to process flags: %w", err)
+			}
+		}
+
+		write := func() error {
+			if port {
+				output.Files = portableFiles(output.Files)
+			}
+			if split {
+				return writeSplit(path, opts, output)
+			}
+			out := newOutputWriter(path, tee)
+			if err := config.Encode(out, output, opts.encodeOptions()); err != nil {
+				return fmt.Errorf("failed to produce output: %w", err)
+			}
+			return out.Close()
+		}
+
+		vopts := validateOptions{templates: templatesFS(tplDir), strict: true, allowEmptyFiles: empty}
+		if answer != "" || !flagged.empty() {
+			if len(output.Files) == 0 && !empty {
+				return &exitError{code: exitInvalid, err: fmt.Errorf("%w, add one with --file or pass --allow-empty-files", config.ErrNoFiles)}
+			}
+			if strict && !checkStrict(output, vopts, noCol) {
+				return errStrict
+			}
+			return write()
+		}
+		if !term.IsTerminal(int(os.Stdin.Fd())) && !assumeYes {
+			return errNotTerminal
+		}
+
+		wopts := append(colorOptions(noCol), wizard.WithMaxLineSize(maxLn), wizard.WithTemplatesDir(tplDir),
+			wizard.WithKeyRules(rules.Keys), wizard.WithDefaults(defs))
+		if noInf {
+			wopts = append(wopts, wizard.WithNoInfer())
+		}
+		if strict {
+			wopts = append(wopts, wizard.WithStrict())
+		}
+		if empty {
+			wopts = append(wopts, wizard.WithAllowEmptyFiles())
+		}
+		start := globals
+		if resume {
+			d, err := loadDraft(path)
+			if err != nil {
+				return err
+			}
+			output, start = d.Config, d.Section
+			w, err := newWizard(os.Stdout, wopts...)
+			if err == nil {
+				err = w.RestoreSecrets(&output)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to resume: %w", err)
+			}
+		}
+
+		var check func(Config) config.ValidationErrors
+		if strict {
+			check = func(cfg Config) config.ValidationErrors { return validateConfig(cfg, vopts) }
+		}
+		// The full-screen wizard cannot read answers from a script.
+		if !plain && !assumeYes {
+			settings := tuiSettings{allowEmptyFiles: empty, rules: rules, templates: tplNames}
+			if output, err = runTUI(output, check, settings); err != nil {
+				if errors.Is(err, errInterrupted) {
+					return interruptedDraft(name, path, draft{Section: globals, Config: output})
+				}
+				return fmt.Errorf("failed to process config: %w", err)
+			}
+			removeDraft(path)
+			return write()
+		}
+
+		// Interrupted, the wizard saves the sections completed so far, the
+		// section being entered being lost.
+		var last atomic.Pointer[draft]
+		if resume {
+			last.Store(&draft{Section: start, Config: output})
+		}
+		stop := interruptible(func() error {
+			if d := last.Load(); d != nil {
+				return interruptedDraft(name, path, *d)
+			}
+			return nil
+		})
+		defer stop()
+
+		saved := resume
+		run := append(wopts, wizard.WithConfig(output), wizard.WithStart(start),
+			wizard.WithProgress(func(cfg Config, next wizard.Section) {
+				last.Store(&draft{Section: next, Config: cfg})
+				if err := saveDraft(path, draft{Section: next, Config: cfg}); err != nil {
+					logger.Warn(err.Error(), "error", err)
+					return
+				}
+				saved = true
+			}))
+		if check != nil {
+			run = append(run, wizard.WithCheck(check))
+		}
+		w, err := newWizard(os.Stdout, run...)
+		if err != nil {
+			return err
+		}
+		output, err = w.Run()
+		var ended wizard.IncompleteError
+		if errors.As(err, &ended) {
+			return finishIncomplete(name, path, output, ended.Section, write, noCol)
+		}
+		if err != nil {
+			if saved {
+				return fmt.Errorf("failed to process config: %w; completed sections were saved, continue with --resume", err)
+			}
+			return fmt.Errorf("failed to process config: %w", err)
+		}
+		removeDraft(path)
+		return write()
+	}
+}
+
+// Choices offered when the input of the wizard ends early.
+const (
+	incompleteDraft   = "d"
+	incompleteWrite   = "w"
+	incompleteDiscard = "q"
+)
+
+// finishIncomplete deals with the config collected by the wizard of the
+// command name, writing to target, before its input ended in section: it is
+// saved as a draft, written as it is with a warning, or discarded, as the
+// user picks.
+// Terminals keep reading after Ctrl-D, so the user is asked; when nobody
+// can answer, the draft is saved. Either way the command fails with
+// exitIncomplete.
+func finishIncomplete(name, target string, cfg Config, section wizard.Section, write func() error, noColor bool) error {
+	w, err := newWizard(os.Stdout, colorOptions(noColor)...)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	w.Warn("The input ended before the config was complete.")
+	choice := incompleteDraft
+	if !assumeYes {
+		answer, err := w.Ask(fmt.Sprintf("Save a draft (%s), write the config collected so far (%s) or discard it (%s)?",
+			incompleteDraft, incompleteWrite, incompleteDiscard), incompleteDraft)
+		if err == nil {
+			choice = answer
+		}
+	}
+	switch choice {
+	case incompleteWrite:
+		logger.Warn("writing an incomplete config", "event", "incomplete", "section", section)
+		if err := write(); err != nil {
+			return err
+		}
+		removeDraft(target)
+		return &exitError{code: exitIncomplete, err: errors.New("input ended, the config collected so far was written")}
+	case incompleteDiscard:
+		removeDraft(target)
+		return &exitError{code: exitIncomplete, err: errors.New("input ended, the config was discarded")}
+	default:
+		if err := saveDraft(target, draft{Section: section, Config: cfg}); err != nil {
+			return &exitError{code: exitIncomplete, err: fmt.Errorf("input ended and %w", err)}
+		}
+		return &exitError{code: exitIncomplete, err: fmt.Errorf("input ended, the config collected so far was saved as a draft: continue with gg-config %s --resume", name)}
+	}
+}
+
+// interruptedDraft saves d, collected by the wizard of the command name
+// writing to target before it was interrupted, and returns the error telling how to resume.
+func interruptedDraft(name, target string, d draft) error {
+	if err := saveDraft(target, d); err != nil {
+		return &exitError{code: exitInterrupted, err: fmt.Errorf("%w and %w", errInterrupted, err)}
+	}
+	return &exitError{code: exitInterrupted, err: fmt.Errorf("%w, the config collected so far was saved as a draft: continue with gg-config %s --resume", errInterrupted, name)}
+}
+
+var errNotTerminal = errors.New("stdin is not a terminal: provide the config through --answers (--answers - reads it from stdin) or the --global, --file and --cmd flags")
@@ -0,0 +1,238 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/omerkaya1/gg-config/internal/input"
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+const (
+	strategyLastWins = "last-wins"
+	strategyError    = "error"
+	strategyPrompt   = "prompt"
+)
+
+func isMergeStrategy(s string) bool {
+	switch s {
+	case strategyLastWins, strategyError, strategyPrompt:
+		return true
+	default:
+		return false
+	}
+}
+
+type mergeOptions struct {
+	globals string
+	files   string
+}
+
+// runMerge combines two or more configs into a single one.
+func runMerge(fs *flag.FlagSet) func(args []string) error {
+	var (
+		path  string
+		opts  outputOptions
+		mopts mergeOptions
+	)
+	addOutputFlags(fs, &path, &opts)
+	fs.StringVar(&mopts.globals, "globals", strategyLastWins, "conflicting global keys strategy: last-wins, error or prompt")
+	fs.StringVar(&mopts.files, "files", strategyLastWins, "duplicate file entries strategy: last-wins, error or prompt")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gg-config merge [flags] config.json config.json...")
+		fs.PrintDefaults()
+	}
+	return func(args []string) error {
+		// Flags may follow the configs, as in merge a.json b.json --compact.
+		inputs, err := parseInterspersed(fs, args)
+		if err != nil {
+			return err
+		}
+		if len(inputs) < 2 {
+			return usageError(fs, "merge: expected at least two config files")
+		}
+		if err := opts.validate(); err != nil {
+			return fmt.Errorf("merge: %w", err)
+		}
+		if err := checkOverwrite(path); err != nil {
+			return fmt.Errorf("merge: %w", err)
+		}
+		for _, s := range []string{mopts.globals, mopts.files} {
+			if !isMergeStrategy(s) {
+				return usageError(fs, "merge: unknown strategy: %s", s)
+			}
+		}
+
+		var result Config
+		for _, p := range inputs {
+			cfg, err := config.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			if result, err = mergeConfigs(result, cfg, mopts); err != nil {
+				return fmt.Errorf("merge %s: %w", p, err)
+			}
+		}
+		return writeConfig(path, opts, result)
+	}
+}
+
+// mergeConfigs merges src into dst. Global keys and files (identified by name
+// and path) present in both are resolved using the configured strategies,
+// globals holding maps in both being merged key by key;
+// commands are appended unless an identical one already exists. Secrets of
+// either config stay secret.
+func mergeConfigs(dst, src Config, opts mergeOptions) (Config, error) {
+	// Secrets come first for the conflicts to redact those of either config.
+	for _, name := range src.Secrets {
+		dst.Secrets = input.AddSecret(dst.Secrets, name)
+	}
+	var err error
+	dst.Global, err = mergeVars(dst.Global, src.Global, "", opts.globals, dst.Secrets, func(k string) string {
+		return fmt.Sprintf("global %q", k)
+	})
+	if err != nil {
+		return dst, err
+	}
+	for _, k := range sortedKeys(src.Computed) {
+		expr := src.Computed[k]
+		if cur, ok := dst.Computed[k]; ok && cur != expr {
+			replace, err := resolveConflict(opts.globals, fmt.Sprintf("computed %q", k), cur, expr)
+			if err != nil {
+				return dst, err
+			}
+			if !replace {
+				continue
+			}
+		}
+		dst.Computed = input.SetComputed(dst.Computed, k, expr)
+	}
+	for _, k := range sortedKeys(src.Choices) {
+		choices := src.Choices[k]
+		if cur, ok := dst.Choices[k]; ok && !reflect.DeepEqual(cur, choices) {
+			replace, err := resolveConflict(opts.globals, fmt.Sprintf("choices of %q", k), cur, choices)
+			if err != nil {
+				return dst, err
+			}
+			if !replace {
+				continue
+			}
+		}
+		dst.Choices = input.SetChoices(dst.Choices, k, choices)
+	}
+	for _, name := range sortedKeys(src.Environments) {
+		env := dst.Environments[name]
+		env.Global, err = mergeVars(env.Global, src.Environments[name].Global, "", opts.globals, dst.Secrets, func(k string) string {
+			return fmt.Sprintf("global %q of environment %s", k, name)
+		})
+		if err != nil {
+			return dst, err
+		}
+		if dst.Environments == nil {
+			dst.Environments = make(map[string]Environment)
+		}
+		dst.Environments[name] = env
+	}
+
+Files:
+	for _, f := range src.Files {
+		for i, cur := range dst.Files {
+			if cur.Name != f.Name || cur.Path != f.Path {
+				continue
+			}
+			if reflect.DeepEqual(cur, f) {
+				continue Files
+			}
+			// Locals secret in either file are redacted from both.
+			secrets := append(append([]string(nil), cur.Secrets...), f.Secrets...)
+			shown := func(f File) File {
+				f.Secrets = secrets
+				return redactFile(f)
+			}
+			replace, err := resolveConflict(opts.files, fmt.Sprintf("file %q (%s)", f.Name, f.Path), shown(cur), shown(f))
+			if err != nil {
+				return dst, err
+			}
+			if replace {
+				dst.Files[i] = f
+			}
+			continue Files
+		}
+		dst.Files = append(dst.Files, f)
+	}
+
+	dst.PreCmds = appendCommands(dst.PreCmds, src.PreCmds)
+	dst.Cmds = appendCommands(dst.Cmds, src.Cmds)
+	return dst, nil
+}
+
+// mergeVars merges the variables of src into dst, recursing into maps present
+// in both so that only conflicting leaves are resolved using strategy. Keys
+// of nested variables are joined with dots, after prefix, and passed to
+// describe to name the conflict, and the values of secrets are redacted from
+// it.
+func mergeVars(dst, src map[string]any, prefix, strategy string, secrets []string, describe func(key string) string) (map[string]any, error) {
+	for _, k := range sortedKeys(src) {
+		v := src[k]
+		key := prefix + k
+		cur, ok := dst[k]
+		if ok {
+			curMap, curIsMap := cur.(map[string]any)
+			srcMap, srcIsMap := v.(map[string]any)
+			if curIsMap && srcIsMap {
+				merged, err := mergeVars(curMap, srcMap, key+".", strategy, secrets, describe)
+				if err != nil {
+					return dst, err
+				}
+				dst[k] = merged
+				continue
+			}
+		}
+		if ok && !reflect.DeepEqual(cur, v) {
+			replace, err := resolveConflict(strategy, describe(key), redactValue(secrets, key, cur), redactValue(secrets, key, v))
+			if err != nil {
+				return dst, err
+			}
+			if !replace {
+				continue
+			}
+		}
+		if dst == nil {
+			dst = make(map[string]any)
+		}
+		dst[k] = v
+	}
+	return dst, nil
+}
+
+// appendCommands appends the commands of src missing from dst.
+func appendCommands(dst, src []Command) []Command {
+Cmds:
+	for _, c := range src {
+		for _, cur := range dst {
+			if reflect.DeepEqual(cur, c) {
+				continue Cmds
+			}
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
+
+// resolveConflict reports whether the incoming value should replace the
+// current one. The values are shown as given, so secrets must be redacted
+// beforehand, and prompts go to stderr to keep them out of the merged config
+// written to stdout.
+func resolveConflict(strategy, what string, current, incoming any) (bool, error) {
+	switch strategy {
+	case strategyError:
+		return false, fmt.Errorf("conflicting %s", what)
+	case strategyPrompt:
+		fmt.Fprintf(os.Stderr, "Conflicting %s\n\tcurrent:  %+v\n\tincoming: %+v\n", what, current, incoming)
+		return confirm(os.Stderr, "Use incoming value: y/n? ")
+	default:
+		return true, nil
+	}
+}
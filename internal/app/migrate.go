@@ -0,0 +1,81 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+// runMigrate rewrites configs in an older layout to the current version.
+func runMigrate(fs *flag.FlagSet) func(args []string) error {
+	var (
+		path string
+		opts outputOptions
+	)
+	addOutputFlags(fs, &path, &opts)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gg-config migrate [flags] config.json...")
+		fmt.Fprintln(fs.Output(), "Configs are rewritten in place, in their own format, unless -o or --format is given.")
+		fs.PrintDefaults()
+	}
+	return func(args []string) error {
+		if err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		if fs.NArg() == 0 {
+			return usageError(fs, "migrate: expected at least one config file")
+		}
+		if path != "" && fs.NArg() > 1 {
+			return usageError(fs, "migrate: -o can only be used with a single config file")
+		}
+		if path != "" && filepath.Clean(path) != filepath.Clean(fs.Arg(0)) {
+			if err := checkOverwrite(path); err != nil {
+				return fmt.Errorf("migrate: %w", err)
+			}
+		}
+		formatSet := flagPassed(fs, "format", "f")
+
+		for _, p := range fs.Args() {
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return fmt.Errorf("migrate: %w", err)
+			}
+			format := config.DetectFormat(p, data)
+			from, err := config.DetectVersion(data, format)
+			if err != nil {
+				return fmt.Errorf("migrate %s: %w", p, err)
+			}
+			if from == config.Version && path == "" && !formatSet {
+				logger.Info(fmt.Sprintf("%s: already at version %d", p, config.Version), "event", "unchanged", "config", p, "version", config.Version)
+				continue
+			}
+
+			cfg, err := config.Decode(data, format)
+			if err != nil {
+				return fmt.Errorf("migrate %s: %w", p, err)
+			}
+			out, wopts := path, opts
+			if out == "" {
+				out = p
+			}
+			if !formatSet {
+				wopts.format = format
+			}
+			if err := wopts.validate(); err != nil {
+				return fmt.Errorf("migrate %s: %w, pick another one with --format", p, err)
+			}
+			if err := writeConfig(out, wopts, cfg); err != nil {
+				return fmt.Errorf("migrate %s: %w", p, err)
+			}
+			if from == config.Version {
+				logger.Info(fmt.Sprintf("%s: rewritten at version %d", p, config.Version), "event", "rewritten", "config", p, "version", config.Version)
+			} else {
+				logger.Info(fmt.Sprintf("%s: migrated from version %d to %d", p, from, config.Version), "event", "migrated", "config", p, "from", from, "version", config.Version)
+			}
+		}
+		return nil
+	}
+}
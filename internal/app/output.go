@@ -0,0 +1,149 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/omerkaya1/gg-config/pkg/config"
+	"golang.org/x/term"
+)
+
+type outputOptions struct {
+	format  string
+	compact bool
+	indent  int
+}
+
+// addOutputFlags registers the flags controlling where and how a config is
+// written.
+func addOutputFlags(fs *flag.FlagSet, path *string, opts *outputOptions) {
+	fs.StringVar(path, "output", "", "output destination path")
+	fs.StringVar(path, "o", "", "output destination path (shortened)")
+	formats := strings.Join(config.EncodeFormats(), ", ")
+	fs.StringVar(&opts.format, "format", config.FormatJSON, "output format: "+formats)
+	fs.StringVar(&opts.format, "f", config.FormatJSON, "output format: "+formats+" (shortened)")
+	fs.BoolVar(&opts.compact, "compact", false, "write JSON output on a single line")
+	fs.IntVar(&opts.indent, "indent", config.DefaultIndent, "indentation width for JSON, JSONC and YAML output")
+}
+
+// validate checks the output flags, failing with exitUsage.
+func (opts outputOptions) validate() error {
+	if !config.CanEncode(opts.format) {
+		return &exitError{code: exitUsage, err: fmt.Errorf("unsupported output format: %s, expected one of %s", opts.format, strings.Join(config.EncodeFormats(), ", "))}
+	}
+	if opts.indent < 0 {
+		return &exitError{code: exitUsage, err: fmt.Errorf("invalid indentation width: %d", opts.indent)}
+	}
+	return nil
+}
+
+// encodeOptions returns the options configs are encoded with.
+func (opts outputOptions) encodeOptions() config.EncodeOptions {
+	return config.EncodeOptions{Format: opts.format, Compact: opts.compact, Indent: opts.indent}
+}
+
+// writeConfig encodes cfg into the file at path, or to stdout if path is empty.
+func writeConfig(path string, opts outputOptions, cfg Config) error {
+	out := newOutputWriter(path, false)
+	if err := config.Encode(out, cfg, opts.encodeOptions()); err != nil {
+		return fmt.Errorf("failed to produce output: %w", err)
+	}
+	return out.Close()
+}
+
+// outputWriter is the last stage of the commands producing a file. What is
+// written to it is kept in memory, so that a command failing half-way
+// leaves everything untouched by just not closing it. Close then writes the
+// output out: to the file at path, replaced atomically once backed up, to
+// stdout if path is empty, or to both with tee.
+type outputWriter struct {
+	path string
+	tee  bool
+	buf  bytes.Buffer
+}
+
+func newOutputWriter(path string, tee bool) *outputWriter {
+	return &outputWriter{path: path, tee: tee}
+}
+
+func (o *outputWriter) Write(p []byte) (int, error) {
+	return o.buf.Write(p)
+}
+
+// Close writes the output out and returns the first failure, the file
+// being written, synced and closed before stdout is written to.
+func (o *outputWriter) Close() error {
+	if o.path != "" {
+		if err := commitFile(o.path, o.buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	if o.path == "" || o.tee {
+		if _, err := os.Stdout.Write(o.buf.Bytes()); err != nil {
+			return fmt.Errorf("write output: %w", err)
+		}
+	}
+	return nil
+}
+
+// commitFile creates or replaces the file at path with data. An existing
+// file is backed up with a timestamp first, unless --no-backup is given or
+// its content stays the same, and replaced atomically.
+func commitFile(path string, data []byte) error {
+	if keepBackups {
+		current, err := os.ReadFile(path)
+		if err == nil && !bytes.Equal(current, data) {
+			backup, err := config.BackupFile(path)
+			if err != nil {
+				return fmt.Errorf("back up %s: %w", path, err)
+			}
+			logger.Info(fmt.Sprintf("backed up %s to %s", path, backup), "event", "backed_up", "file", path, "backup", backup)
+		}
+	}
+	return config.WriteFileAtomic(path, 0o644, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// checkOverwrite makes sure that writing to path replaces no file by
+// accident: an existing file is only overwritten with --force or once the
+// user confirmed it. Nobody being there to confirm, as with --yes or when
+// stdin is no terminal, it is left alone.
+func checkOverwrite(path string) error {
+	if path == "" || forceOverwrite {
+		return nil
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if assumeYes || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("%s already exists, use --force to overwrite it", path)
+	}
+	// Prompts go to stderr, stdout possibly being piped with --tee.
+	ok, err := confirm(os.Stderr, fmt.Sprintf("%s already exists. Overwrite it: y/n? ", path))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &exitError{code: exitCanceled, err: fmt.Errorf("%s already exists, not overwritten", path)}
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,13 @@
+package app
+
+import "github.com/omerkaya1/gg-config/pkg/config"
+
+// portableFiles returns files with their paths and names in portable form.
+func portableFiles(files []File) []File {
+	out := make([]File, len(files))
+	for i, f := range files {
+		f.Path, f.Name = config.PortablePath(f.Path), config.PortablePath(f.Name)
+		out[i] = f
+	}
+	return out
+}
@@ -0,0 +1,383 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/omerkaya1/gg-config/internal/variables"
+	"github.com/omerkaya1/gg-config/pkg/config"
+	"github.com/omerkaya1/gg-config/pkg/render"
+)
+
+const defaultTemplatesDir = "templates"
+
+// Policies for rendered files whose target already exists.
+const (
+	conflictOverwrite = "overwrite"
+	conflictSkip      = "skip"
+	conflictPrompt    = "prompt"
+	conflictBackup    = "backup"
+)
+
+func isConflictPolicy(s string) bool {
+	switch s {
+	case conflictOverwrite, conflictSkip, conflictPrompt, conflictBackup:
+		return true
+	default:
+		return false
+	}
+}
+
+type renderOptions struct {
+	// templates is where the templates referenced by files are read from.
+	templates fs.FS
+	// dryRun renders in memory only, printing a diff against the files on
+	// disk instead of writing them.
+	dryRun bool
+	// noExec skips the pre- and post-generation commands.
+	noExec bool
+	// onConflict is the policy applied to targets that already exist.
+	onConflict string
+	// jobs is the number of templates executed concurrently.
+	jobs int
+	// keepGoing carries on after failed commands, only skipping the ones
+	// depending on them, and reports the failures at the end.
+	keepGoing bool
+	// secrets are the values of the secret variables, redacted from the
+	// diffs of dry runs.
+	secrets []string
+	// allowEmptyFiles accepts configs without files.
+	allowEmptyFiles bool
+	// env names the environment whose globals override the ones of the
+	// config, if any.
+	env string
+	// journal records the files written, for them to be rolled back when
+	// the render is interrupted.
+	journal *renderJournal
+}
+
+// runRender generates the files described by a config out of their templates.
+func runRender(fs *flag.FlagSet) func(args []string) error {
+	var (
+		opts   renderOptions
+		tplDir string
+	)
+	fs.StringVar(&tplDir, "templates-dir", defaultTemplatesDir, "directory the templates are read from")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "print a diff of the changes instead of writing any file or running commands")
+	fs.BoolVar(&opts.noExec, "no-exec", false, "do not run the pre- and post-generation commands")
+	fs.IntVar(&opts.jobs, "jobs", runtime.NumCPU(), "number of templates rendered concurrently")
+	fs.StringVar(&opts.env, "env", "", "environment of the config, such as prod, whose globals override the base ones")
+	fs.BoolVar(&opts.allowEmptyFiles, "allow-empty-files", false, "render configs without files, running their commands only")
+	fs.BoolVar(&opts.keepGoing, "keep-going", false, "carry on after failed commands, skipping only the ones depending on them")
+	fs.StringVar(&opts.onConflict, "on-conflict", conflictOverwrite, "what to do with existing files: overwrite, skip, prompt or backup (keeping a timestamped .bak copy)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gg-config render [flags] config.json")
+		fs.PrintDefaults()
+	}
+	return func(args []string) error {
+		if err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return usageError(fs, "render: expected exactly one config file")
+		}
+		if opts.jobs < 1 {
+			return usageError(fs, "render: invalid number of jobs: %d", opts.jobs)
+		}
+		if !isConflictPolicy(opts.onConflict) {
+			return usageError(fs, "render: unknown conflict policy: %s", opts.onConflict)
+		}
+		// --force overwrites whatever the policy, except that backups are still
+		// kept when asked for.
+		if forceOverwrite && opts.onConflict != conflictBackup {
+			opts.onConflict = conflictOverwrite
+		}
+		opts.templates = os.DirFS(tplDir)
+
+		path := fs.Arg(0)
+		cfg, err := config.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if opts.env != "" {
+			if cfg, err = config.WithEnvironment(cfg, opts.env); err != nil {
+				return fmt.Errorf("render: %w", err)
+			}
+			logger.Debug("using environment "+opts.env, "event", "environment", "environment", opts.env)
+		}
+		opts.secrets = secretValues(cfg)
+		// Interrupted, the render leaves no half-generated tree behind; what the
+		// commands did is not undone though.
+		opts.journal = new(renderJournal)
+		stop := interruptible(opts.journal.rollback)
+		defer stop()
+		logger.Debug(fmt.Sprintf("loaded %s: %d file(s), %d pre-command(s), %d command(s)", path, len(cfg.Files), len(cfg.PreCmds), len(cfg.Cmds)),
+			"event", "loaded", "config", path, "files", len(cfg.Files), "pre_commands", len(cfg.PreCmds), "commands", len(cfg.Cmds))
+		// Pre-commands may well produce the templates, so the config is only
+		// checked against them once they ran; nothing runs unless the rest of
+		// the config is valid though.
+		var (
+			hooks    = !opts.dryRun && !opts.noExec
+			captured = make(map[string]string)
+			failures []error
+		)
+		if hooks && len(cfg.PreCmds) != 0 {
+			if err := checkRenderConfig(path, cfg, validateOptions{allowEmptyFiles: opts.allowEmptyFiles}); err != nil {
+				return err
+			}
+		}
+		if hooks {
+			err = runHooks("pre_commands", cfg.PreCmds, hookContext{cfg: cfg, captured: captured, keepGoing: opts.keepGoing})
+			if err != nil && !opts.keepGoing {
+				return fmt.Errorf("render: %w", err)
+			}
+			failures = append(failures, err)
+		} else {
+			for _, c := range cfg.PreCmds {
+				if c.Capture != "" {
+					captured[c.Capture] = ""
+					logger.Info(fmt.Sprintf("pre-commands not run, %s left empty", c.Capture), "event", "capture_skipped", "variable", c.Capture)
+				}
+			}
+		}
+		cfg.Global = variables.WithCaptured(cfg.Global, captured)
+		if err := checkRenderConfig(path, cfg, validateOptions{templates: opts.templates, allowEmptyFiles: opts.allowEmptyFiles}); err != nil {
+			return err
+		}
+		if cfg, err = config.Resolve(cfg); err != nil {
+			return fmt.Errorf("render: %w", err)
+		}
+		failed, err := renderConfig(cfg, opts, captured)
+		if err != nil {
+			return err
+		}
+		failures = append(failures, failed)
+		if hooks {
+			err = runHooks("commands", cfg.Cmds, hookContext{cfg: cfg, captured: captured, keepGoing: opts.keepGoing})
+			failures = append(failures, err)
+		}
+		if err = errors.Join(failures...); err != nil {
+			return fmt.Errorf("render: %w", err)
+		}
+		return nil
+	}
+}
+
+// checkRenderConfig validates cfg, read from path, logging the errors found
+// and failing with exitInvalid if there are any.
+func checkRenderConfig(path string, cfg Config, opts validateOptions) error {
+	problems := validateConfig(cfg, opts)
+	n := countErrors(problems)
+	if n == 0 {
+		return nil
+	}
+	for _, p := range problems {
+		if p.Severity == severityError {
+			logger.Error(fmt.Sprintf("%s: %s", path, p), "event", "invalid", "config", path, "path", p.Path, "message", p.Message)
+		}
+	}
+	return &exitError{code: exitInvalid, err: fmt.Errorf("render: %d error(s) found in %s", n, path)}
+}
+
+// renderConfig executes the template of every file of cfg and writes the
+// results to their targets, creating missing directories on the way.
+// Templates are executed concurrently by up to opts.jobs workers; nothing is
+// written unless all of them succeed, in which case the files are written in
+// declaration order, each followed by its hooks if any of its outputs
+// changed. The hooks share the variables captured with the other commands;
+// with opts.keepGoing, their failures do not stop the rendering but are
+// returned separately.
+func renderConfig(cfg Config, opts renderOptions, captured map[string]string) (hookErr, err error) {
+	results, err := render.Execute(cfg, opts.templates, opts.jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	var hookErrs []error
+	for i, r := range results {
+		if r.Skipped {
+			logger.Info("skipped "+cfg.Files[i].Target(), "event", "skipped", "file", cfg.Files[i].Target(), "reason", "skip_if")
+			continue
+		}
+		var written bool
+		for _, out := range r.Outputs {
+			ok, err := writeRendered(filepath.FromSlash(out.Name), out.Data, opts)
+			if err != nil {
+				return nil, fmt.Errorf("render files[%d]: %w", i, err)
+			}
+			written = written || ok
+		}
+		if written && !opts.dryRun && !opts.noExec {
+			hc := hookContext{cfg: cfg, file: &cfg.Files[i], captured: captured, keepGoing: opts.keepGoing}
+			if err := runHooks(fmt.Sprintf("files[%d].hooks", i), cfg.Files[i].Hooks, hc); err != nil {
+				if !opts.keepGoing {
+					return nil, fmt.Errorf("render: %w", err)
+				}
+				hookErrs = append(hookErrs, err)
+			}
+		}
+	}
+	return errors.Join(hookErrs...), nil
+}
+
+// writeRendered writes data to target or, in dry-run mode, previews it.
+// Files already existing are handled according to the conflict policy,
+// unless their content is unchanged. It reports whether the file was
+// written.
+func writeRendered(target string, data []byte, opts renderOptions) (bool, error) {
+	current, err := os.ReadFile(target)
+	exists := err == nil
+	switch {
+	case err != nil && !errors.Is(err, os.ErrNotExist):
+		return false, err
+	case exists && bytes.Equal(current, data):
+		logger.Info("unchanged "+target, "event", "unchanged", "file", target)
+		return false, nil
+	case exists && opts.onConflict == conflictSkip:
+		logger.Info("skipped existing "+target, "event", "skipped", "file", target, "reason", "exists")
+		return false, nil
+	}
+	if opts.dryRun {
+		return false, previewFile(os.Stdout, target, data, opts.secrets)
+	}
+
+	if exists {
+		switch opts.onConflict {
+		case conflictPrompt:
+			overwrite, err := confirm(os.Stdout, fmt.Sprintf("%s already exists, overwrite: y/n? ", target))
+			if err != nil {
+				return false, err
+			}
+			if !overwrite {
+				logger.Info("skipped existing "+target, "event", "skipped", "file", target, "reason", "exists")
+				return false, nil
+			}
+		case conflictBackup:
+			backup, err := opts.journal.backup(target)
+			if err != nil {
+				return false, err
+			}
+			logger.Info(fmt.Sprintf("backed up %s to %s", target, backup), "event", "backed_up", "file", target, "backup", backup)
+		}
+	}
+	err = opts.journal.write(target, current, func() error {
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		return config.WriteFileAtomic(target, 0o644, func(w io.Writer) error {
+			_, err := w.Write(data)
+			return err
+		})
+	})
+	if err != nil {
+		return false, err
+	}
+	logger.Info("wrote "+target, "event", "wrote", "file", target, "bytes", len(data))
+	return true, nil
+}
+
+// renderJournal records the files a render created or replaced, backups
+// included, along with the directories created for them, so that they can be
+// rolled back.
+type renderJournal struct {
+	mu      sync.Mutex
+	entries []journalEntry
+}
+
+type journalEntry struct {
+	path string
+	// previous is the content replaced, nil for files created.
+	previous []byte
+	// dirs are the directories created for the file, innermost first.
+	dirs []string
+}
+
+// write runs write, creating or replacing the file at path whose current
+// content is previous, nil if there is none, and records it.
+func (j *renderJournal) write(path string, previous []byte, write func() error) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	e := journalEntry{path: path, previous: previous}
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); !errors.Is(err, os.ErrNotExist) || dir == filepath.Dir(dir) {
+			break
+		}
+		e.dirs = append(e.dirs, dir)
+	}
+	// Recorded first, a file written half-way is rolled back too.
+	j.entries = append(j.entries, e)
+	return write()
+}
+
+// backup backs the file at path up, as config.BackupFile does, and records
+// the backup for it to be removed on rollback.
+func (j *renderJournal) backup(path string) (string, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	backup, err := config.BackupFile(path)
+	if err == nil && backup != "" {
+		j.entries = append(j.entries, journalEntry{path: backup})
+	}
+	return backup, err
+}
+
+// rollback restores the files recorded, most recent first, and removes the
+// directories created for them as far as they are left empty. It waits for
+// the file being written and keeps any other from being written after it,
+// as gg-config exits next.
+func (j *renderJournal) rollback() error {
+	j.mu.Lock()
+	var errs []error
+	for i := len(j.entries) - 1; i >= 0; i-- {
+		e := j.entries[i]
+		var err error
+		if e.previous == nil {
+			err = os.Remove(e.path)
+		} else {
+			err = config.WriteFileAtomic(e.path, 0o644, func(w io.Writer) error {
+				_, err := w.Write(e.previous)
+				return err
+			})
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+			continue
+		}
+		for _, dir := range e.dirs {
+			os.Remove(dir)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("%w, rolling back the files written failed: %w", errInterrupted, err)
+	}
+	if len(j.entries) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w, the %d file(s) written were rolled back", errInterrupted, len(j.entries))
+}
+
+// previewFile writes the diff between the file at target and its rendered
+// content data, listing the file as new if it does not exist yet. Secrets
+// are redacted from both sides.
+func previewFile(w io.Writer, target string, data []byte, secrets []string) error {
+	current, err := os.ReadFile(target)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		fmt.Fprintf(w, "new file %s\n", target)
+		return writeUnifiedDiff(w, os.DevNull, target, "", redactText(string(data), secrets))
+	case err != nil:
+		return err
+	case bytes.Equal(current, data):
+		logger.Info("unchanged "+target, "event", "unchanged", "file", target)
+		return nil
+	}
+	return writeUnifiedDiff(w, target, target, redactText(string(current), secrets), redactText(string(data), secrets))
+}
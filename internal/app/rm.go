@@ -0,0 +1,197 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/omerkaya1/gg-config/internal/input"
+	"github.com/omerkaya1/gg-config/internal/variables"
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+// runRm removes file entries, commands and global variables from an
+// existing config and writes the config back in place.
+func runRm(fs *flag.FlagSet) func(args []string) error {
+	var (
+		files   stringList
+		cmds    stringList
+		globals stringList
+		dryRun  bool
+		empty   bool
+	)
+	fs.Var(&files, "file", "file entry to remove, by the name or path of the file it generates; repeatable")
+	fs.Var(&cmds, "cmd", "commands and pre-commands to remove, by executable or ID; repeatable")
+	fs.Var(&globals, "global", "global variable to remove, dotted for nested ones; repeatable")
+	fs.BoolVar(&dryRun, "dry-run", false, "print what would be removed without writing the config")
+	fs.BoolVar(&empty, "allow-empty-files", false, "allow removing the last file entry")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gg-config rm config.json [flags] [file ...]")
+		fmt.Fprintln(fs.Output(), "Files are named by the path of the file they generate, as in gg-config rm config.json cmd/main.go.")
+		fs.PrintDefaults()
+	}
+	return func(args []string) error {
+		targets, err := parseInterspersed(fs, args)
+		if err != nil {
+			return err
+		}
+		if len(targets) == 0 {
+			return usageError(fs, "rm: expected a config file")
+		}
+		path := targets[0]
+		files = append(files, targets[1:]...)
+		if len(files) == 0 && len(cmds) == 0 && len(globals) == 0 {
+			return usageError(fs, "rm: nothing to remove")
+		}
+
+		before, format, err := readConfigFile(path)
+		if err != nil {
+			return fmt.Errorf("rm: %w", err)
+		}
+		// The config is read again rather than copied, removing variables
+		// changing the nested maps in place.
+		cfg, _, err := readConfigFile(path)
+		if err != nil {
+			return fmt.Errorf("rm: %w", err)
+		}
+		var (
+			removedFiles = make(map[int]bool)
+			removedPre   = make(map[int]bool)
+			removedCmds  = make(map[int]bool)
+		)
+		for _, name := range files {
+			if !matchFiles(cfg.Files, name, removedFiles) {
+				return fmt.Errorf("rm: no file entry generates %s", name)
+			}
+		}
+		for _, name := range cmds {
+			pre := matchCommands(cfg.PreCmds, name, removedPre)
+			if post := matchCommands(cfg.Cmds, name, removedCmds); !pre && !post {
+				return fmt.Errorf("rm: no command runs %q or has it as ID", name)
+			}
+		}
+		for _, name := range globals {
+			if err := removeGlobal(&cfg, name); err != nil {
+				return fmt.Errorf("rm: %w", err)
+			}
+		}
+
+		// Removals are listed the way diff lists them, except that commands
+		// keep their positions in the config as it was.
+		changes := config.Diff(before, cfg)
+		changes.Files, changes.Commands = nil, nil
+		cfg.Files = removeEntries(cfg.Files, removedFiles, func(i int, f File) {
+			loc := fmt.Sprintf("files[%s]", filepath.ToSlash(f.Target()))
+			changes.Files = append(changes.Files, config.Change{Kind: config.Removed, Path: loc, Old: f})
+		})
+		for _, list := range []struct {
+			section string
+			cmds    *[]Command
+			removed map[int]bool
+		}{{"pre_commands", &cfg.PreCmds, removedPre}, {"commands", &cfg.Cmds, removedCmds}} {
+			*list.cmds = removeEntries(*list.cmds, list.removed, func(i int, c Command) {
+				loc := fmt.Sprintf("%s[%d]", list.section, i)
+				changes.Commands = append(changes.Commands, config.Change{Kind: config.Removed, Path: loc, Old: c})
+			})
+		}
+
+		if err := checkFilesLeft(before, cfg, empty); err != nil {
+			return fmt.Errorf("rm: %w", err)
+		}
+		if dryRun {
+			return writeChanges(os.Stdout, redactChanges(changes, before.Secrets))
+		}
+		if err := writeConfigFile(path, format, cfg); err != nil {
+			return fmt.Errorf("rm: %w", err)
+		}
+		return nil
+	}
+}
+
+// matchFiles adds the indexes of the entries generating the file name, or
+// generating files called name anywhere if name has no directory, to
+// matched, and reports whether there were any.
+func matchFiles(files []File, name string, matched map[int]bool) bool {
+	target := filepath.Clean(name)
+	found := false
+	for i, f := range files {
+		if f.Target() == target || filepath.Base(target) == name && f.Name == name {
+			matched[i], found = true, true
+		}
+	}
+	return found
+}
+
+// matchCommands adds the indexes of the commands running the executable
+// name, or having it as ID, to matched, and reports whether there were any.
+func matchCommands(cmds []Command, name string, matched map[int]bool) bool {
+	found := false
+	for i, c := range cmds {
+		if c.Name == name || c.ID == name {
+			matched[i], found = true, true
+		}
+	}
+	return found
+}
+
+// removeEntries returns entries without the ones at the indexes in removed,
+// calling removal for each of them.
+func removeEntries[T any](entries []T, removed map[int]bool, removal func(i int, entry T)) []T {
+	var kept []T
+	for i, e := range entries {
+		if removed[i] {
+			removal(i, e)
+			continue
+		}
+		kept = append(kept, e)
+	}
+	return kept
+}
+
+// removeGlobal removes the global variable name from cfg, along with its
+// choices, expression and secret mark, and those of the variables nested in
+// it.
+func removeGlobal(cfg *Config, name string) error {
+	_, isVar := variables.Lookup(cfg.Global, name)
+	_, isComputed := cfg.Computed[name]
+	if !isVar && !isComputed {
+		return fmt.Errorf("no global variable %s", name)
+	}
+	variables.Delete(cfg.Global, name)
+	forgetGlobal(cfg, name)
+	return nil
+}
+
+// forgetGlobal drops the choices, computed variable and secrets of the
+// removed global variable name, and of the variables nested in it.
+func forgetGlobal(cfg *Config, name string) {
+	within := func(key string) bool {
+		return key == name || strings.HasPrefix(key, name+".")
+	}
+	for key := range cfg.Choices {
+		if within(key) {
+			delete(cfg.Choices, key)
+		}
+	}
+	for key := range cfg.Computed {
+		if within(key) {
+			delete(cfg.Computed, key)
+		}
+	}
+	for _, s := range cfg.Secrets {
+		if within(s) {
+			cfg.Secrets = input.RemoveSecret(cfg.Secrets, s)
+		}
+	}
+}
+
+// checkFilesLeft refuses to write cfg, which had the files of before, without
+// any left unless allowEmpty is set, see --allow-empty-files.
+func checkFilesLeft(before, cfg Config, allowEmpty bool) error {
+	if len(cfg.Files) == 0 && len(before.Files) != 0 && !allowEmpty {
+		return &exitError{code: exitInvalid, err: fmt.Errorf("removing every file entry: %w, pass --allow-empty-files to remove them anyway", config.ErrNoFiles)}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/omerkaya1/gg-config/internal/input"
+	"github.com/omerkaya1/gg-config/internal/variables"
+)
+
+// redactValue returns v, the value of the variable key, redacted if it is a
+// secret and with its secret entries redacted if it holds any.
+func redactValue(secrets []string, key string, v any) any {
+	if !holdsSecret(secrets, key) {
+		return v
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return input.Redacted
+	}
+	out := make(map[string]any, len(m))
+	for k, e := range m {
+		out[k] = redactValue(secrets, key+"."+k, e)
+	}
+	return out
+}
+
+// secretValues returns the non-empty values of the secret variables of cfg,
+// the longest first so that redactText replaces overlapping ones entirely.
+func secretValues(cfg Config) []string {
+	var values []string
+	collect := func(vars map[string]any, secrets []string) {
+		for _, name := range secrets {
+			if v, ok := variables.Lookup(vars, name); ok && v != nil {
+				if s := variables.String(v); s != "" {
+					values = append(values, s)
+				}
+			}
+		}
+	}
+	collect(cfg.Global, cfg.Secrets)
+	for _, f := range cfg.Files {
+		collect(f.Local, f.Secrets)
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	return values
+}
+
+// redactText replaces every occurrence of values in s.
+func redactText(s string, values []string) string {
+	for _, v := range values {
+		s = strings.ReplaceAll(s, v, input.Redacted)
+	}
+	return s
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+const (
+	splitIndexName    = "index"
+	splitGlobalsName  = "globals"
+	splitFilesName    = "files"
+	splitPreCmdsName  = "pre_commands"
+	splitCommandsName = "commands"
+)
+
+// splitIndex ties the separately written sections of a config together.
+// config.ReadFile reads it along with the sections as the config.
+type splitIndex struct {
+	Version     int    `json:"version" yaml:"version"`
+	Global      string `json:"global" yaml:"global"`
+	Files       string `json:"files" yaml:"files"`
+	PreCommands string `json:"pre_commands" yaml:"pre_commands"`
+	Commands    string `json:"commands" yaml:"commands"`
+	// Secrets names the secret global variables, which the globals file
+	// cannot tell.
+	Secrets []string `json:"secrets,omitempty" yaml:"secrets,omitempty"`
+	// Computed holds the expressions of the computed global variables.
+	Computed map[string]string `json:"computed,omitempty" yaml:"computed,omitempty"`
+	// Choices holds the values the restricted global variables may take.
+	Choices map[string][]string `json:"choices,omitempty" yaml:"choices,omitempty"`
+	// Environments holds the overlays of the globals by environment.
+	Environments map[string]Environment `json:"environments,omitempty" yaml:"environments,omitempty"`
+}
+
+func isSplitFormat(format string) bool {
+	return format == config.FormatJSON || format == config.FormatYAML
+}
+
+// splitPaths returns the paths of the files writeSplit writes into dir.
+func splitPaths(dir, format string) []string {
+	if dir == "" {
+		dir = "."
+	}
+	var paths []string
+	for _, name := range []string{splitGlobalsName, splitFilesName, splitPreCmdsName, splitCommandsName, splitIndexName} {
+		paths = append(paths, filepath.Join(dir, name+"."+format))
+	}
+	return paths
+}
+
+// writeSplit writes every section of cfg into its own file inside dir, along
+// with an index file referencing them.
+func writeSplit(dir string, opts outputOptions, cfg Config) error {
+	if !isSplitFormat(opts.format) {
+		return fmt.Errorf("split output is not supported for format: %s", opts.format)
+	}
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("split output: %w", err)
+	}
+
+	ext := "." + opts.format
+	index := splitIndex{
+		Version:      config.Version,
+		Global:       splitGlobalsName + ext,
+		Files:        splitFilesName + ext,
+		PreCommands:  splitPreCmdsName + ext,
+		Commands:     splitCommandsName + ext,
+		Secrets:      cfg.Secrets,
+		Computed:     cfg.Computed,
+		Choices:      cfg.Choices,
+		Environments: cfg.Environments,
+	}
+	// Empty sections are written as such rather than as null.
+	if cfg.Global == nil {
+		cfg.Global = map[string]any{}
+	}
+	if cfg.Files == nil {
+		cfg.Files = []File{}
+	}
+	if cfg.PreCmds == nil {
+		cfg.PreCmds = []Command{}
+	}
+	if cfg.Cmds == nil {
+		cfg.Cmds = []Command{}
+	}
+	for _, section := range []struct {
+		name  string
+		value any
+	}{
+		{index.Global, cfg.Global},
+		{index.Files, cfg.Files},
+		{index.PreCommands, cfg.PreCmds},
+		{index.Commands, cfg.Cmds},
+		{splitIndexName + ext, index},
+	} {
+		if err := writeSection(filepath.Join(dir, section.name), opts, section.value); err != nil {
+			return fmt.Errorf("split output: %w", err)
+		}
+	}
+	return nil
+}
+
+func writeSection(path string, opts outputOptions, v any) error {
+	out := newOutputWriter(path, false)
+	if err := config.EncodeValue(out, v, opts.encodeOptions()); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return out.Close()
+}
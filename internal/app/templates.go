@@ -0,0 +1,28 @@
+package app
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/omerkaya1/gg-config/pkg/templates"
+)
+
+// templatesFS returns the file system of the templates beneath dir, nil if
+// dir is empty.
+func templatesFS(dir string) fs.FS {
+	if dir == "" {
+		return nil
+	}
+	return os.DirFS(dir)
+}
+
+// templateNames returns the names of the templates beneath dir, which the
+// wizards offer as template choices.
+func templateNames(dir string) ([]string, error) {
+	names, err := templates.Names(os.DirFS(dir))
+	if err != nil {
+		return nil, fmt.Errorf("list templates in %s: %w", dir, err)
+	}
+	return names, nil
+}
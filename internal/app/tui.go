@@ -0,0 +1,480 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/omerkaya1/gg-config/internal/input"
+	"github.com/omerkaya1/gg-config/internal/variables"
+	"github.com/omerkaya1/gg-config/pkg/config"
+	"github.com/omerkaya1/gg-config/pkg/templates"
+	"github.com/omerkaya1/gg-config/pkg/wizard"
+)
+
+const tuiHelp = "tab/shift+tab: section • ↑/↓: select • a: add • enter: edit • d: delete • ctrl+s: save • esc: quit"
+
+var tuiSections = [...]string{"Globals", "Files", "Pre-commands", "Commands"}
+
+// runTUI collects the config through a full-screen form, starting from the
+// values of initial. If check is set, saving is refused while it reports
+// errors. Left with Ctrl-C, it returns errInterrupted along with the config
+// entered so far.
+func runTUI(initial Config, check func(Config) config.ValidationErrors, settings tuiSettings) (Config, error) {
+	// The form is drawn on stderr so the config itself can be redirected.
+	final, err := tea.NewProgram(newTUIModel(initial, check, settings), tea.WithAltScreen(), tea.WithOutput(os.Stderr)).Run()
+	if errors.Is(err, tea.ErrProgramKilled) {
+		return initial, errInterrupted
+	}
+	if err != nil {
+		return initial, fmt.Errorf("tui: %w", err)
+	}
+	m := final.(tuiModel)
+	switch {
+	case m.interrupted:
+		return m.cfg, errInterrupted
+	case !m.saved:
+		return initial, errQuit
+	}
+	return m.cfg, nil
+}
+
+// tuiSettings are the policies the full-screen wizard enters configs by.
+type tuiSettings struct {
+	// allowEmptyFiles accepts configs without files.
+	allowEmptyFiles bool
+	// rules are what variables are entered by.
+	rules input.Rules
+	// templates, if any, are the names the templates of files are
+	// restricted to.
+	templates []string
+}
+
+type tuiModel struct {
+	cfg      Config
+	check    func(Config) config.ValidationErrors
+	settings tuiSettings
+	section  wizard.Section
+	cursor   [len(tuiSections)]int
+
+	// editing is set while the entry form is shown; index is the entry being
+	// edited or -1 for a new one.
+	editing bool
+	index   int
+	inputs  []textinput.Model
+	focus   int
+
+	err   string
+	saved bool
+	// interrupted is set when the form was left with Ctrl-C.
+	interrupted bool
+}
+
+func newTUIModel(cfg Config, check func(Config) config.ValidationErrors, settings tuiSettings) tuiModel {
+	return tuiModel{cfg: cfg, check: check, settings: settings}
+}
+
+func (m tuiModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if key.Type == tea.KeyCtrlC {
+		m.interrupted = true
+		return m, tea.Quit
+	}
+	if m.editing {
+		return m.updateForm(key)
+	}
+
+	m.err = ""
+	switch key.String() {
+	case "esc", "q":
+		return m, tea.Quit
+	case "ctrl+s":
+		if len(m.cfg.Files) == 0 && !m.settings.allowEmptyFiles {
+			m.err = "cannot save, " + config.ErrNoFiles.Error()
+			return m, nil
+		}
+		if m.check != nil {
+			if problems := m.check(m.cfg); countErrors(problems) != 0 {
+				m.err = fmt.Sprintf("cannot save, %d problem(s) found, e.g. %s", countErrors(problems), firstError(problems))
+				return m, nil
+			}
+		}
+		m.saved = true
+		return m, tea.Quit
+	case "tab", "right":
+		m.section = (m.section + 1) % wizard.Section(len(tuiSections))
+	case "shift+tab", "left":
+		m.section = (m.section + wizard.Section(len(tuiSections)) - 1) % wizard.Section(len(tuiSections))
+	case "up", "k":
+		if m.cursor[m.section] > 0 {
+			m.cursor[m.section]--
+		}
+	case "down", "j":
+		if m.cursor[m.section] < m.entries()-1 {
+			m.cursor[m.section]++
+		}
+	case "a":
+		return m.openForm(-1)
+	case "enter":
+		if m.entries() != 0 {
+			return m.openForm(m.cursor[m.section])
+		}
+	case "d":
+		if m.entries() != 0 {
+			m.delete(m.cursor[m.section])
+			if m.cursor[m.section] >= m.entries() && m.cursor[m.section] > 0 {
+				m.cursor[m.section]--
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m tuiModel) entries() int {
+	switch m.section {
+	case globals:
+		return len(variables.Flatten(m.cfg.Global))
+	case files:
+		return len(m.cfg.Files)
+	default:
+		return len(*m.commands())
+	}
+}
+
+// commands returns the command list edited in the current section.
+func (m *tuiModel) commands() *[]Command {
+	if m.section == preCmds {
+		return &m.cfg.PreCmds
+	}
+	return &m.cfg.Cmds
+}
+
+func (m *tuiModel) delete(i int) {
+	switch m.section {
+	case globals:
+		name := sortedKeys(variables.Flatten(m.cfg.Global))[i]
+		variables.Delete(m.cfg.Global, name)
+		m.cfg.Secrets = input.RemoveSecret(m.cfg.Secrets, name)
+		delete(m.cfg.Choices, name)
+	case files:
+		m.cfg.Files = append(m.cfg.Files[:i:i], m.cfg.Files[i+1:]...)
+	default:
+		list := m.commands()
+		*list = append((*list)[:i:i], (*list)[i+1:]...)
+	}
+}
+
+func (m tuiModel) openForm(index int) (tea.Model, tea.Cmd) {
+	var labels, values []string
+	switch m.section {
+	case globals:
+		labels = []string{"Key", "Value"}
+		values = make([]string, 2)
+		if index >= 0 {
+			flat := variables.Flatten(m.cfg.Global)
+			k := sortedKeys(flat)[index]
+			key := m.settings.rules.AnnotatedKey(k, flat[k])
+			if input.IsSecret(m.cfg.Secrets, k) {
+				key = k + ":" + input.SecretType
+			}
+			values = []string{key, variables.String(flat[k])}
+		}
+	case files:
+		labels = []string{"File name", "File path", "Template name", "Local variables (key=value ...)"}
+		values = make([]string, 4)
+		if index >= 0 {
+			f := m.cfg.Files[index]
+			values = []string{f.Name, f.Path, f.Template, joinAssignments(f.Local, f.Secrets, m.settings.rules)}
+		}
+	default:
+		labels = []string{"Command", "Working directory", "Environment (KEY=value ...)"}
+		values = make([]string, 3)
+		if index >= 0 {
+			c := (*m.commands())[index]
+			values = []string{input.JoinTokens(append([]string{c.Name}, c.Args...)), c.Dir, input.JoinEnv(c.Env)}
+		}
+	}
+
+	m.inputs = make([]textinput.Model, len(labels))
+	for i := range labels {
+		in := textinput.New()
+		in.Prompt = labels[i] + ": "
+		in.SetValue(values[i])
+		m.inputs[i] = in
+	}
+	m.editing, m.index, m.focus = true, index, 0
+	m.hideSecret()
+	return m, m.inputs[0].Focus()
+}
+
+// hideSecret masks the value input of globals whose key is annotated as a
+// secret.
+func (m *tuiModel) hideSecret() {
+	if m.section != globals {
+		return
+	}
+	m.inputs[1].EchoMode = textinput.EchoNormal
+	if _, secret := input.SecretKey(strings.TrimSpace(m.inputs[0].Value())); secret {
+		m.inputs[1].EchoMode = textinput.EchoPassword
+	}
+}
+
+func (m tuiModel) updateForm(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key.String() {
+	case "esc":
+		m.editing, m.err = false, ""
+		return m, nil
+	case "up", "shift+tab":
+		return m.focusInput(m.focus - 1)
+	case "down", "tab":
+		return m.focusInput(m.focus + 1)
+	case "enter":
+		if m.focus < len(m.inputs)-1 {
+			return m.focusInput(m.focus + 1)
+		}
+		if err := m.submit(); err != nil {
+			m.err = err.Error()
+			return m, nil
+		}
+		m.editing, m.err = false, ""
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focus], cmd = m.inputs[m.focus].Update(key)
+	return m, cmd
+}
+
+func (m tuiModel) focusInput(i int) (tea.Model, tea.Cmd) {
+	if i < 0 || i >= len(m.inputs) {
+		return m, nil
+	}
+	m.inputs[m.focus].Blur()
+	m.focus = i
+	m.hideSecret()
+	return m, m.inputs[i].Focus()
+}
+
+// submit validates the form and stores its values in the config.
+func (m *tuiModel) submit() error {
+	values := make([]string, len(m.inputs))
+	for i, in := range m.inputs {
+		values[i] = strings.TrimSpace(in.Value())
+	}
+
+	switch m.section {
+	case globals:
+		if name, restricted := input.ChoiceKey(values[0]); restricted {
+			scope := input.GlobalScope(m.cfg)
+			scope.Rules = m.settings.rules
+			if err := scope.Restrict(name, values[1]); err != nil {
+				return err
+			}
+			scope.SetGlobal(&m.cfg)
+			return nil
+		}
+		_, secret := input.SecretKey(values[0])
+		key, v, err := m.settings.rules.Value(values[0], values[1])
+		if err != nil {
+			return err
+		}
+		if choices, ok := m.cfg.Choices[key]; ok && !variables.IsChoice(v, choices) {
+			return fmt.Errorf("%s is not one of %s", values[1], strings.Join(choices, ", "))
+		}
+		if m.cfg.Global == nil {
+			m.cfg.Global = make(map[string]any)
+		}
+		var old string
+		if m.index >= 0 {
+			old = sortedKeys(variables.Flatten(m.cfg.Global))[m.index]
+		}
+		prev, _ := variables.Lookup(m.cfg.Global, old)
+		variables.Delete(m.cfg.Global, old)
+		if err := variables.Set(m.cfg.Global, key, v); err != nil {
+			if old != "" {
+				variables.Set(m.cfg.Global, old, prev)
+			}
+			return err
+		}
+		m.cfg.Secrets = input.RemoveSecret(m.cfg.Secrets, old)
+		if secret {
+			m.cfg.Secrets = input.AddSecret(m.cfg.Secrets, key)
+		}
+	case files:
+		if values[0] == "" || values[1] == "" || values[2] == "" {
+			return fmt.Errorf("file name, path and template are required")
+		}
+		if len(m.settings.templates) != 0 && !templates.Match(m.settings.templates, values[2]) {
+			return fmt.Errorf("unknown template %q", values[2])
+		}
+		local, secrets, err := parseAssignments(values[3], m.settings.rules)
+		if err != nil {
+			return err
+		}
+		var f File
+		if m.index >= 0 {
+			// Keep what the form does not cover, such as hooks.
+			f = m.cfg.Files[m.index]
+		}
+		f.Name, f.Path, f.Template, f.Local, f.Secrets = values[0], values[1], values[2], local, secrets
+		if m.index >= 0 {
+			m.cfg.Files[m.index] = f
+		} else {
+			m.cfg.Files = append(m.cfg.Files, f)
+		}
+	default:
+		parts, err := input.Tokenize(values[0])
+		if err != nil {
+			return err
+		}
+		if len(parts) == 0 {
+			return fmt.Errorf("incorrect command declaration length")
+		}
+		env, err := input.ParseEnv(values[2])
+		if err != nil {
+			return err
+		}
+		c := Command{Name: parts[0], Args: parts[1:], Dir: values[1], Env: env}
+		if list := m.commands(); m.index >= 0 {
+			(*list)[m.index] = c
+		} else {
+			*list = append(*list, c)
+		}
+	}
+	return nil
+}
+
+func (m tuiModel) View() string {
+	var b strings.Builder
+
+	for i, name := range tuiSections {
+		if wizard.Section(i) == m.section {
+			fmt.Fprintf(&b, "[ %s ]  ", name)
+		} else {
+			fmt.Fprintf(&b, "  %s    ", name)
+		}
+	}
+	b.WriteString("\n\n")
+
+	if m.editing {
+		action := "Edit"
+		if m.index < 0 {
+			action = "New"
+		}
+		fmt.Fprintf(&b, "%s entry\n\n", action)
+		for _, in := range m.inputs {
+			b.WriteString(in.View() + "\n")
+		}
+		if names := m.settings.templates; m.section == files && len(names) != 0 {
+			fmt.Fprintf(&b, "\nAvailable templates: %s\n", strings.Join(names, ", "))
+		}
+		if m.err != "" {
+			fmt.Fprintf(&b, "\n! %s\n", m.err)
+		}
+		b.WriteString("\nenter: next/submit • tab: move • esc: cancel\n")
+		return b.String()
+	}
+
+	rows := m.rows()
+	if len(rows) == 0 {
+		b.WriteString("  (empty, press a to add)\n")
+	}
+	for i, r := range rows {
+		marker := "  "
+		if i == m.cursor[m.section] {
+			marker = "> "
+		}
+		b.WriteString(marker + r + "\n")
+	}
+	if m.err != "" {
+		fmt.Fprintf(&b, "\n! %s\n", m.err)
+	}
+	b.WriteString("\n" + tuiHelp + "\n")
+	return b.String()
+}
+
+func (m tuiModel) rows() []string {
+	var rows []string
+	switch m.section {
+	case globals:
+		flat := variables.Flatten(m.cfg.Global)
+		for _, k := range sortedKeys(flat) {
+			v := variables.String(flat[k])
+			if input.IsSecret(m.cfg.Secrets, k) {
+				v = input.Redacted
+			}
+			if choices, ok := m.cfg.Choices[k]; ok {
+				v += " (one of " + strings.Join(choices, ", ") + ")"
+			}
+			rows = append(rows, fmt.Sprintf("%s = %s", k, v))
+		}
+	case files:
+		for _, f := range m.cfg.Files {
+			rows = append(rows, fmt.Sprintf("%s (%s) <- %s  %s", f.Name, f.Path, f.Template, joinAssignments(input.RedactVars(f.Local, f.Secrets), nil, m.settings.rules)))
+		}
+	default:
+		for _, c := range *m.commands() {
+			rows = append(rows, input.JoinTokens(append([]string{c.Name}, c.Args...)))
+		}
+	}
+	return rows
+}
+
+// joinAssignments formats vars as key=value tokens, annotating the keys of
+// secrets and the ones whose type rules would not guess.
+func joinAssignments(vars map[string]any, secrets []string, rules input.Rules) string {
+	flat := variables.Flatten(vars)
+	parts := make([]string, 0, len(flat))
+	for _, k := range sortedKeys(flat) {
+		key := rules.AnnotatedKey(k, flat[k])
+		if input.IsSecret(secrets, k) {
+			key = k + ":" + input.SecretType
+		}
+		parts = append(parts, variables.Quote(key+"="+variables.String(flat[k])))
+	}
+	return strings.Join(parts, " ")
+}
+
+// parseAssignments parses the tokens written by joinAssignments, returning
+// the names of the secrets along with the variables converted by rules.
+func parseAssignments(s string, rules input.Rules) (map[string]any, []string, error) {
+	parts, err := input.Tokenize(s)
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		vars    map[string]any
+		secrets []string
+	)
+	for _, part := range parts {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || key == "" {
+			return nil, nil, fmt.Errorf("local variable %q: expected key=value", part)
+		}
+		_, secret := input.SecretKey(key)
+		key, v, err := rules.Value(key, value)
+		if err != nil {
+			return nil, nil, err
+		}
+		if vars == nil {
+			vars = make(map[string]any)
+		}
+		if err := variables.Set(vars, key, v); err != nil {
+			return nil, nil, err
+		}
+		if secret {
+			secrets = input.AddSecret(secrets, key)
+		}
+	}
+	return vars, secrets, nil
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"runtime"
+
+	"github.com/omerkaya1/gg-config/internal/probe"
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+// Problems found in configs are reported as the validation errors of the
+// config package; these aliases keep the checks short.
+type (
+	problem  = config.ValidationError
+	severity = config.Severity
+)
+
+const (
+	severityError   = config.SeverityError
+	severityWarning = config.SeverityWarning
+	severityInfo    = config.SeverityInfo
+)
+
+func countErrors(problems []problem) int {
+	var n int
+	for _, p := range problems {
+		if p.Severity == severityError {
+			n++
+		}
+	}
+	return n
+}
+
+func firstError(problems []problem) problem {
+	for _, p := range problems {
+		if p.Severity == severityError {
+			return p
+		}
+	}
+	return problem{}
+}
+
+type validateOptions struct {
+	// templates, if set, is where file templates are looked up and
+	// parsed.
+	templates fs.FS
+	// strict turns every warning into an error.
+	strict bool
+	// targetOS is the system the paths of files are checked for, the one
+	// gg-config runs on if empty.
+	targetOS string
+	// allowEmptyFiles accepts configs without files.
+	allowEmptyFiles bool
+}
+
+// validateConfig checks cfg as config.Check does, with the names of
+// variables checked against variableKeys, and checks besides that the files
+// can be written and the commands are found in PATH.
+func validateConfig(cfg Config, opts validateOptions) []problem {
+	return config.Check(cfg, config.CheckOptions{
+		Templates:       opts.templates,
+		Strict:          opts.strict,
+		TargetOS:        opts.targetOS,
+		AllowEmptyFiles: opts.allowEmptyFiles,
+		Keys:            variableKeys,
+		CheckPath:       probe.CheckFilePath,
+		CheckCommand:    probe.CheckCommand,
+	})
+}
+
+// runValidate checks a config file and reports every problem found.
+func runValidate(fs *flag.FlagSet) func(args []string) error {
+	var (
+		opts   validateOptions
+		tplDir string
+		asJSON bool
+	)
+	var keys keyRuleFlags
+	fs.StringVar(&tplDir, "templates-dir", "", "directory to check the referenced templates against")
+	fs.BoolVar(&opts.strict, "strict", false, "treat warnings as errors")
+	fs.BoolVar(&opts.allowEmptyFiles, "allow-empty-files", false, "accept configs without files, which are otherwise an error")
+	fs.StringVar(&opts.targetOS, "target-os", runtime.GOOS, "system the paths of files are checked for, e.g. linux, darwin or windows")
+	fs.BoolVar(&asJSON, "json", false, "print the problems found to stdout as a JSON array of path, severity and message objects")
+	keys.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gg-config validate [flags] config.json")
+		fs.PrintDefaults()
+	}
+	return func(args []string) error {
+		if err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return usageError(fs, "validate: expected exactly one config file")
+		}
+		if err := keys.apply(); err != nil {
+			return fmt.Errorf("validate: %w", err)
+		}
+		opts.templates = templatesFS(tplDir)
+
+		path := fs.Arg(0)
+		cfg, err := config.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		problems := validateConfig(cfg, opts)
+		if asJSON {
+			if problems == nil {
+				problems = []problem{}
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(problems); err != nil {
+				return fmt.Errorf("validate: %w", err)
+			}
+		} else {
+			for _, p := range problems {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", path, p)
+			}
+		}
+		if n := countErrors(problems); n != 0 {
+			return &exitError{code: exitInvalid, err: fmt.Errorf("validate: %d error(s) found in %s", n, path)}
+		}
+		return nil
+	}
+}
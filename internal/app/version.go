@@ -0,0 +1,126 @@
+package app
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+// Build information, set by release builds with
+//
+//	go build -ldflags "-X github.com/omerkaya1/gg-config/internal/app.version=v1.2.3 ..."
+//
+// and otherwise taken from the module and VCS information embedded by the
+// go command.
+var (
+	version string
+	commit  string
+	date    string
+)
+
+// buildInfo describes the running gg-config binary.
+type buildInfo struct {
+	Version       string `json:"version"`
+	Commit        string `json:"commit,omitempty"`
+	Date          string `json:"date,omitempty"`
+	Modified      bool   `json:"modified,omitempty"`
+	GoVersion     string `json:"go_version"`
+	Platform      string `json:"platform"`
+	ConfigVersion int    `json:"config_version"`
+}
+
+// readBuildInfo gathers the build information, ldflags taking precedence
+// over the information embedded by the go command.
+func readBuildInfo() buildInfo {
+	b := buildInfo{
+		Version:       version,
+		Commit:        commit,
+		Date:          date,
+		GoVersion:     runtime.Version(),
+		Platform:      runtime.GOOS + "/" + runtime.GOARCH,
+		ConfigVersion: config.Version,
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if b.Version == "" && info.Main.Version != "" {
+			b.Version = info.Main.Version
+		}
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if b.Commit == "" {
+					b.Commit = s.Value
+				}
+			case "vcs.time":
+				if b.Date == "" {
+					b.Date = s.Value
+				}
+			case "vcs.modified":
+				b.Modified = commit == "" && s.Value == "true"
+			}
+		}
+	}
+	if b.Version == "" {
+		b.Version = "(devel)"
+	}
+	return b
+}
+
+// String formats b on a single line, e.g.
+// gg-config v1.2.3 (commit 0a1b2c3, built 2024-05-01T10:00:00Z, go1.22.2 linux/amd64, config version 1).
+func (b buildInfo) String() string {
+	details := make([]string, 0, 4)
+	if b.Commit != "" {
+		c := b.Commit
+		if len(c) > 12 {
+			c = c[:12]
+		}
+		if b.Modified {
+			c += "-dirty"
+		}
+		details = append(details, "commit "+c)
+	}
+	if b.Date != "" {
+		details = append(details, "built "+b.Date)
+	}
+	details = append(details, b.GoVersion+" "+b.Platform, fmt.Sprintf("config version %d", b.ConfigVersion))
+	return fmt.Sprintf("gg-config %s (%s)", b.Version, strings.Join(details, ", "))
+}
+
+// runVersion prints the version of gg-config, the commit and date it was
+// built from and the version of the config layout it writes.
+func runVersion(fs *flag.FlagSet) func(args []string) error {
+	var (
+		asJSON bool
+	)
+	fs.BoolVar(&asJSON, "json", false, "print the build information as a JSON object")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gg-config version [flags]")
+		fs.PrintDefaults()
+	}
+	return func(args []string) error {
+		if err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		if fs.NArg() != 0 {
+			return usageError(fs, "version: unexpected arguments: %s", strings.Join(fs.Args(), " "))
+		}
+
+		b := readBuildInfo()
+		if asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(b); err != nil {
+				return fmt.Errorf("version: %w", err)
+			}
+			return nil
+		}
+		fmt.Println(b)
+		return nil
+	}
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"io"
+	"os"
+
+	"github.com/omerkaya1/gg-config/pkg/wizard"
+)
+
+// The errors of the wizard the exit code is picked by.
+var (
+	errQuit   = wizard.ErrQuit
+	errStrict = wizard.ErrInvalid
+)
+
+// newWizard returns a wizard reading stdin and writing to out, answering
+// confirmations itself with --yes, and configured further by opts.
+func newWizard(out io.Writer, opts ...wizard.Option) (*wizard.Wizard, error) {
+	base := []wizard.Option{wizard.WithIO(os.Stdin, out)}
+	if assumeYes {
+		base = append(base, wizard.WithAssumeYes())
+	}
+	return wizard.New(append(base, opts...)...)
+}
+
+// colorOptions styles the wizard as colorEnabled tells.
+func colorOptions(noColor bool) []wizard.Option {
+	if !colorEnabled(noColor) {
+		return nil
+	}
+	return []wizard.Option{wizard.WithColor()}
+}
+
+// confirm asks the yes/no question prompt on out, answered yes with --yes.
+func confirm(out io.Writer, prompt string) (bool, error) {
+	w, err := newWizard(out)
+	if err != nil {
+		return false, err
+	}
+	return w.Confirm(prompt)
+}
+
+// checkStrict validates cfg before it is written in strict mode, printing
+// every problem found to stderr, and reports whether it passed.
+func checkStrict(cfg Config, opts validateOptions, noColor bool) bool {
+	w, err := newWizard(os.Stderr, colorOptions(noColor)...)
+	if err != nil {
+		return false
+	}
+	return w.Report(validateConfig(cfg, opts))
+}
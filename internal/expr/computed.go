@@ -0,0 +1,81 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omerkaya1/gg-config/internal/variables"
+)
+
+// Compute returns a copy of vars extended with the values of the computed
+// variables, which may refer to vars, to the variables of outer, to builtins
+// and to each other.
+func Compute(vars, outer map[string]any, computed map[string]string) (map[string]any, error) {
+	if len(computed) == 0 {
+		return vars, nil
+	}
+	order, err := Order(computed)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		all    = variables.Data(outer, vars)
+		result = make(map[string]any, len(vars)+len(computed))
+	)
+	for k, v := range vars {
+		result[k] = v
+	}
+	for _, name := range order {
+		c, _ := Parse("expression", computed[name])
+		v, err := c.Eval(all)
+		if err == nil {
+			if err = variables.Set(all, name, v); err == nil {
+				err = variables.Set(result, name, v)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return result, nil
+}
+
+// Order parses the expressions of computed and returns the order in which they
+// have to be evaluated, every computed variable coming after the ones it
+// refers to.
+func Order(computed map[string]string) ([]string, error) {
+	var (
+		order []string
+		state = make(map[string]int) // 1 while visiting, 2 once done
+		visit func(name string, path []string) error
+	)
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case 1:
+			return fmt.Errorf("computed variables refer to each other: %s", strings.Join(append(path, name), " -> "))
+		case 2:
+			return nil
+		}
+		state[name] = 1
+		c, err := Parse("expression", computed[name])
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		for _, ref := range sortedKeys(c.Variables()) {
+			if _, ok := computed[ref]; ok {
+				if err := visit(ref, append(path, name)); err != nil {
+					return err
+				}
+			}
+		}
+		state[name] = 2
+		order = append(order, name)
+		return nil
+	}
+	for _, name := range sortedKeys(computed) {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
@@ -0,0 +1,636 @@
+// Package expr implements the expressions of gg-config configs: the
+// conditions of commands and files, such as os == "linux", and the
+// expressions computed variables are evaluated from.
+package expr
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/omerkaya1/gg-config/internal/variables"
+)
+
+// Expr is a parsed expression over config variables, such as
+// `UseDocker == false || Arch != "amd64"`. Supported are the literals true,
+// false, null, numbers and double quoted strings, variable names, the arithmetic
+// operators +, -, *, / and %, + concatenating strings as well, the comparison
+// operators ==, !=, <, <=, > and >=, the logical operators !, && and ||,
+// calls of the functions upper, lower, title, camel, snake, kebab, trim and
+// string, such as upper(Name), and parentheses. Conditions have to evaluate
+// to true or false, computed variables to any value. Undefined variables
+// compare equal to null, and are an error anywhere else.
+type Expr struct {
+	// what the expression is, such as a condition, naming it in errors.
+	what   string
+	source string
+	root   node
+}
+
+type node interface {
+	eval(vars map[string]any) (any, error)
+}
+
+type (
+	literal  struct{ value any }
+	variable struct{ name string }
+	not      struct{ operand node }
+	neg      struct{ operand node }
+	binary   struct {
+		op          string
+		left, right node
+	}
+	call struct {
+		name string
+		args []node
+	}
+)
+
+// Parse parses the expression s. what tells what it is, such as a condition,
+// and names it in errors.
+func Parse(what, s string) (Expr, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return Expr{}, fmt.Errorf("%s %q: %w", what, s, err)
+	}
+	p := parser{tokens: tokens}
+	root, err := p.or()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return Expr{}, fmt.Errorf("%s %q: %w", what, s, err)
+	}
+	return Expr{what: what, source: s, root: root}, nil
+}
+
+// Eval evaluates the expression against vars.
+func (c Expr) Eval(vars map[string]any) (any, error) {
+	v, err := c.root.eval(vars)
+	if err != nil {
+		return nil, fmt.Errorf("%s %q: %w", c.what, c.source, err)
+	}
+	return v, nil
+}
+
+// Holds evaluates the expression as a condition against vars, to true or
+// false.
+func (c Expr) Holds(vars map[string]any) (bool, error) {
+	v, err := c.root.eval(vars)
+	if err != nil {
+		return false, fmt.Errorf("%s %q: %w", c.what, c.source, err)
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("%s %q: evaluates to %v instead of true or false", c.what, c.source, v)
+	}
+	return b, nil
+}
+
+// Variables returns the names of the variables the expression refers to.
+func (c Expr) Variables() map[string]bool {
+	vars := make(map[string]bool)
+	var walk func(n node)
+	walk = func(n node) {
+		switch n := n.(type) {
+		case variable:
+			vars[n.name] = true
+		case not:
+			walk(n.operand)
+		case neg:
+			walk(n.operand)
+		case binary:
+			walk(n.left)
+			walk(n.right)
+		case call:
+			for _, arg := range n.args {
+				walk(arg)
+			}
+		}
+	}
+	walk(c.root)
+	return vars
+}
+
+type tokenKind uint8
+
+const (
+	identToken tokenKind = iota
+	numberToken
+	stringToken
+	operatorToken
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")", "+", "-", "*", "/", "%", ","}
+
+// operand reports whether the last of tokens ends an operand, after which
+// a - is a subtraction rather than the sign of a number.
+func operand(tokens []token) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	last := tokens[len(tokens)-1]
+	return last.kind != operatorToken || last.text == ")"
+}
+
+func lex(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case c == utf8.RuneError && size == 1:
+			return nil, fmt.Errorf("invalid UTF-8 at offset %d", i)
+		case unicode.IsSpace(c):
+			i += size
+		case c == '"':
+			end := i + 1
+			for ; end < len(s) && s[end] != '"'; end++ {
+				if s[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, token{stringToken, s[i : end+1]})
+			i = end + 1
+		case c == '_' || unicode.IsLetter(c):
+			end := i + size
+			for end < len(s) {
+				r, n := utf8.DecodeRuneInString(s[end:])
+				if r != '_' && r != '.' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				end += n
+			}
+			tokens = append(tokens, token{identToken, s[i:end]})
+			i = end
+		case isDigit(c) || c == '-' && i+1 < len(s) && isDigit(rune(s[i+1])) && !operand(tokens):
+			end := i + 1
+			for end < len(s) && (isDigit(rune(s[end])) || s[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{numberToken, s[i:end]})
+			i = end
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(s[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q", c)
+			}
+			tokens = append(tokens, token{operatorToken, op})
+			i += len(op)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	return tokens, nil
+}
+
+// isDigit reports whether c is an ASCII digit, the only ones of numbers.
+func isDigit(c rune) bool {
+	return '0' <= c && c <= '9'
+}
+
+// parser is a recursive descent parser; from the lowest precedence up,
+// the levels are ||, &&, comparisons, additions, multiplications and unary
+// operands.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) accept(ops ...string) (string, bool) {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != operatorToken {
+		return "", false
+	}
+	for _, op := range ops {
+		if p.tokens[p.pos].text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	for err == nil {
+		if _, ok := p.accept("||"); !ok {
+			break
+		}
+		var right node
+		if right, err = p.and(); err == nil {
+			left = binary{"||", left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.comparison()
+	for err == nil {
+		if _, ok := p.accept("&&"); !ok {
+			break
+		}
+		var right node
+		if right, err = p.comparison(); err == nil {
+			left = binary{"&&", left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) comparison() (node, error) {
+	left, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.accept("==", "!=", "<=", ">=", "<", ">")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+	return binary{op, left, right}, nil
+}
+
+func (p *parser) additive() (node, error) {
+	left, err := p.multiplicative()
+	for err == nil {
+		op, ok := p.accept("+", "-")
+		if !ok {
+			break
+		}
+		var right node
+		if right, err = p.multiplicative(); err == nil {
+			left = binary{op, left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) multiplicative() (node, error) {
+	left, err := p.unary()
+	for err == nil {
+		op, ok := p.accept("*", "/", "%")
+		if !ok {
+			break
+		}
+		var right node
+		if right, err = p.unary(); err == nil {
+			left = binary{op, left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) unary() (node, error) {
+	if op, ok := p.accept("!", "-"); ok {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if op == "-" {
+			return neg{operand}, nil
+		}
+		return not{operand}, nil
+	}
+	if _, ok := p.accept("("); ok {
+		n, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.accept(")"); !ok {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return n, nil
+	}
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	t := p.tokens[p.pos]
+	p.pos++
+	switch t.kind {
+	case stringToken:
+		s, err := strconv.Unquote(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s", t.text)
+		}
+		return literal{s}, nil
+	case numberToken:
+		if !strings.Contains(t.text, ".") {
+			n, err := strconv.ParseInt(t.text, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("integer %s out of range", t.text)
+			}
+			return literal{n}, nil
+		}
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", t.text)
+		}
+		return literal{f}, nil
+	case identToken:
+		switch t.text {
+		case "true":
+			return literal{true}, nil
+		case "false":
+			return literal{false}, nil
+		case "null":
+			return literal{nil}, nil
+		}
+		if _, ok := p.accept("("); ok {
+			return p.call(t.text)
+		}
+		return variable{t.text}, nil
+	default:
+		return nil, fmt.Errorf("unexpected %q", t.text)
+	}
+}
+
+// call parses the arguments of a call of the function name up to the
+// closing parenthesis.
+func (p *parser) call(name string) (node, error) {
+	if _, ok := funcs[name]; !ok {
+		return nil, fmt.Errorf("unknown function %q, expected one of %s", name, strings.Join(sortedKeys(funcs), ", "))
+	}
+	var args []node
+	if _, ok := p.accept(")"); ok {
+		return call{name, args}, nil
+	}
+	for {
+		arg, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if _, ok := p.accept(")"); ok {
+			return call{name, args}, nil
+		}
+		if _, ok := p.accept(","); !ok {
+			return nil, fmt.Errorf("missing closing parenthesis of %s", name)
+		}
+	}
+}
+
+func (n literal) eval(map[string]any) (any, error) {
+	return n.value, nil
+}
+
+func (n variable) eval(vars map[string]any) (any, error) {
+	v, ok := variables.Lookup(vars, n.name)
+	if !ok {
+		return nil, fmt.Errorf("variable %q is not defined", n.name)
+	}
+	return v, nil
+}
+
+func (n not) eval(vars map[string]any) (any, error) {
+	v, err := n.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return nil, fmt.Errorf("cannot negate %v", v)
+	}
+	return !b, nil
+}
+
+func (n neg) eval(vars map[string]any) (any, error) {
+	v, err := n.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	switch x := v.(type) {
+	case int64:
+		if x == math.MinInt64 {
+			return nil, fmt.Errorf("integer overflow negating %d", x)
+		}
+		return -x, nil
+	case float64:
+		return -x, nil
+	default:
+		return nil, fmt.Errorf("cannot negate %v", v)
+	}
+}
+
+func (n call) eval(vars map[string]any) (any, error) {
+	args := make([]any, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	v, err := funcs[n.name](args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+	return v, nil
+}
+
+func (n binary) eval(vars map[string]any) (any, error) {
+	equality := n.op == "==" || n.op == "!="
+	left, err := evalOperand(n.left, vars, equality)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "&&" || n.op == "||" {
+		l, ok := left.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%v is not true or false", left)
+		}
+		// Short-circuit, so that the right operand may refer to variables
+		// only defined when it matters.
+		if l == (n.op == "||") {
+			return l, nil
+		}
+		right, err := n.right.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		r, ok := right.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%v is not true or false", right)
+		}
+		return r, nil
+	}
+
+	right, err := evalOperand(n.right, vars, equality)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "+", "-", "*", "/", "%":
+		return arithmetic(n.op, left, right)
+	}
+	if left == nil || right == nil {
+		if !equality {
+			return nil, fmt.Errorf("cannot order null with %s", n.op)
+		}
+		return (left == nil && right == nil) == (n.op == "=="), nil
+	}
+	if _, ok := left.(bool); ok && !equality {
+		return nil, fmt.Errorf("cannot order %v with %s", left, n.op)
+	}
+	cmp, err := compareValues(left, right)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return cmp == 0, nil
+	case "!=":
+		return cmp != 0, nil
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+// evalOperand evaluates the operand n, an undefined variable being null if
+// undefinedNull is set.
+func evalOperand(n node, vars map[string]any, undefinedNull bool) (any, error) {
+	if v, ok := n.(variable); ok && undefinedNull {
+		value, _ := variables.Lookup(vars, v.name)
+		return value, nil
+	}
+	return n.eval(vars)
+}
+
+// compareValues orders two values of the same kind, numbers of different
+// types being compared by value. Booleans are only told apart, unequal ones
+// comparing greater.
+func compareValues(a, b any) (int, error) {
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			switch {
+			case x < y:
+				return -1, nil
+			case x > y:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), nil
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			if x == y {
+				return 0, nil
+			}
+			return 1, nil
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %v (%T) with %v (%T)", a, a, b, b)
+}
+
+// arithmetic applies op to two numbers, the result being an integer if both
+// are. + concatenates the text of its operands if either is a string.
+func arithmetic(op string, a, b any) (any, error) {
+	_, aString := a.(string)
+	_, bString := b.(string)
+	if op == "+" && (aString || bString) {
+		return variables.String(a) + variables.String(b), nil
+	}
+	if x, ok := a.(int64); ok {
+		if y, ok := b.(int64); ok {
+			switch op {
+			case "+":
+				if r := x + y; (r > x) == (y > 0) {
+					return r, nil
+				}
+			case "-":
+				if r := x - y; (r < x) == (y > 0) {
+					return r, nil
+				}
+			case "*":
+				if r := x * y; x == 0 || r/x == y && !(x == -1 && y == math.MinInt64) {
+					return r, nil
+				}
+			default:
+				if y == 0 {
+					return nil, fmt.Errorf("division by zero")
+				}
+				if op == "%" {
+					return x % y, nil
+				}
+				if x != math.MinInt64 || y != -1 {
+					return x / y, nil
+				}
+			}
+			return nil, fmt.Errorf("integer overflow computing %d %s %d", x, op, y)
+		}
+	}
+	x, xok := toFloat(a)
+	y, yok := toFloat(b)
+	if !xok || !yok || op == "%" {
+		return nil, fmt.Errorf("cannot compute %v %s %v", a, op, b)
+	}
+	switch op {
+	case "+":
+		return x + y, nil
+	case "-":
+		return x - y, nil
+	case "*":
+		return x * y, nil
+	}
+	if y == 0 {
+		return nil, fmt.Errorf("division by zero")
+	}
+	return x / y, nil
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,237 @@
+package expr
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLex(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []token
+		wantErr string
+	}{
+		{
+			in:   `Arch != "amd64"`,
+			want: []token{{identToken, "Arch"}, {operatorToken, "!="}, {stringToken, `"amd64"`}},
+		},
+		{
+			in:   "db.port>=1024&&!Debug",
+			want: []token{{identToken, "db.port"}, {operatorToken, ">="}, {numberToken, "1024"}, {operatorToken, "&&"}, {operatorToken, "!"}, {identToken, "Debug"}},
+		},
+		{
+			in:   "-1 - -2.5",
+			want: []token{{numberToken, "-1"}, {operatorToken, "-"}, {numberToken, "-2.5"}},
+		},
+		{
+			in:   "N-1",
+			want: []token{{identToken, "N"}, {operatorToken, "-"}, {numberToken, "1"}},
+		},
+		{
+			in:   "Größe == Ünits_2",
+			want: []token{{identToken, "Größe"}, {operatorToken, "=="}, {identToken, "Ünits_2"}},
+		},
+		{
+			in:   `"a \" b" + "ü"`,
+			want: []token{{stringToken, `"a \" b"`}, {operatorToken, "+"}, {stringToken, `"ü"`}},
+		},
+		{in: "", wantErr: "empty expression"},
+		{in: `Name == "demo`, wantErr: "unterminated string"},
+		{in: "A § B", wantErr: `unexpected character '§'`},
+		{in: "A == \xff", wantErr: "invalid UTF-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := lex(tt.in)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got tokens %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEval(t *testing.T) {
+	vars := map[string]any{
+		"N":      int64(7),
+		"Ratio":  2.5,
+		"Name":   "demo",
+		"Debug":  false,
+		"Nil":    nil,
+		"db":     map[string]any{"port": int64(5432)},
+		"Größe":  int64(3),
+		"MaxInt": int64(math.MaxInt64),
+		"MinInt": int64(math.MinInt64),
+	}
+	tests := []struct {
+		in   string
+		want any
+	}{
+		// Precedence, from the lowest up: ||, &&, comparisons, additions,
+		// multiplications and unary operands.
+		{"1 + 2 * 3", int64(7)},
+		{"(1 + 2) * 3", int64(9)},
+		{"10 - 4 - 3", int64(3)},
+		{"N % 4 * 2", int64(6)},
+		{"-N + 1", int64(-6)},
+		{"N-1", int64(6)},
+		{"N / 2", int64(3)},
+		{"N / 2.0", 3.5},
+		{"Ratio * 2", 5.0},
+		{"true || false && false", true},
+		{"(true || false) && false", false},
+		{"!Debug && N > 5", true},
+		{"1 + 1 == 2 && Name == \"demo\"", true},
+		{`"v" + N`, "v7"},
+		{`upper(Name) + "-" + string(db.port)`, "DEMO-5432"},
+		{"Größe * 2", int64(6)},
+		{"N == 7.0", true},
+		{`Name < "e"`, true},
+		// Undefined variables compare equal to null only.
+		{"Missing == null", true},
+		{"Missing != null", false},
+		{`Missing == "demo"`, false},
+		{`Missing != "demo"`, true},
+		{"Nil == null", true},
+		{"Name == null", false},
+		{"null == null", true},
+		{"Missing == null || Missing > 1", true},
+		{"MaxInt - 1", int64(math.MaxInt64 - 1)},
+		{"MinInt + 1", int64(math.MinInt64 + 1)},
+		{"MinInt % -1", int64(0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			c, err := Parse("expression", tt.in)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			got, err := c.Eval(vars)
+			if err != nil {
+				t.Fatalf("eval: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	vars := map[string]any{
+		"N":      int64(7),
+		"Name":   "demo",
+		"Debug":  false,
+		"MaxInt": int64(math.MaxInt64),
+		"MinInt": int64(math.MinInt64),
+	}
+	tests := []struct {
+		in      string
+		wantErr string
+	}{
+		// Type errors.
+		{`Name * 2`, "cannot compute demo * 2"},
+		{`N && true`, "7 is not true or false"},
+		{`!N`, "cannot negate 7"},
+		{`-Name`, "cannot negate demo"},
+		{`Name < 1`, "cannot compare demo (string) with 1 (int64)"},
+		{`Debug < true`, "cannot order false with <"},
+		{`Missing < 1`, `variable "Missing" is not defined`},
+		{`null < 1`, "cannot order null with <"},
+		{`Missing + 1`, `variable "Missing" is not defined`},
+		{`upper(N)`, "upper: 7 is not a string"},
+		{`N / 0`, "division by zero"},
+		{`N % 0.5`, "cannot compute 7 % 0.5"},
+		// Integer overflow.
+		{"MaxInt + 1", "integer overflow computing 9223372036854775807 + 1"},
+		{"MinInt - 1", "integer overflow computing -9223372036854775808 - 1"},
+		{"MaxInt * 2", "integer overflow computing 9223372036854775807 * 2"},
+		{"MinInt * -1", "integer overflow computing -9223372036854775808 * -1"},
+		{"-1 * MinInt", "integer overflow computing -1 * -9223372036854775808"},
+		{"MinInt / -1", "integer overflow computing -9223372036854775808 / -1"},
+		{"-MinInt", "integer overflow negating -9223372036854775808"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			c, err := Parse("expression", tt.in)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			_, err = c.Eval(vars)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+			if want := "expression " + strconv.Quote(tt.in) + ": "; !strings.HasPrefix(err.Error(), want) {
+				t.Errorf("error %q does not start with %q", err, want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		what, in string
+		wantErr  string
+	}{
+		{"condition", "N ==", `condition "N ==": unexpected end of expression`},
+		{"skip_if", "(N == 1", `skip_if "(N == 1": missing closing parenthesis`},
+		{"expression", "N N", `expression "N N": unexpected "N"`},
+		{"expression", "shout(Name)", `expression "shout(Name)": unknown function "shout"`},
+		{"expression", "99999999999999999999 + 1", `expression "99999999999999999999 + 1": integer 99999999999999999999 out of range`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			_, err := Parse(tt.what, tt.in)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHolds(t *testing.T) {
+	vars := map[string]any{"OS": "linux", "N": int64(1)}
+	tests := []struct {
+		in      string
+		want    bool
+		wantErr string
+	}{
+		{in: `OS == "linux"`, want: true},
+		{in: `OS == "linux" && Arch == "arm64"`, want: false},
+		{in: `Arch == null || Arch == "amd64"`, want: true},
+		{in: `N + 1`, wantErr: `when "N + 1": evaluates to 2 instead of true or false`},
+		{in: `Arch`, wantErr: `when "Arch": variable "Arch" is not defined`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			c, err := Parse("when", tt.in)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			got, err := c.Holds(vars)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
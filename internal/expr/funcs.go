@@ -0,0 +1,100 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/omerkaya1/gg-config/internal/variables"
+)
+
+// funcs are the functions expressions may call.
+var funcs = map[string]func(args []any) (any, error){
+	"upper": stringFunc(strings.ToUpper),
+	"lower": stringFunc(strings.ToLower),
+	"title": stringFunc(titleCase),
+	"camel": stringFunc(camelCase),
+	"snake": stringFunc(func(s string) string { return joinWords(s, "_") }),
+	"kebab": stringFunc(func(s string) string { return joinWords(s, "-") }),
+	"trim":  stringFunc(strings.TrimSpace),
+	"string": func(args []any) (any, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expected 1 argument, got %d", len(args))
+		}
+		return variables.String(args[0]), nil
+	},
+}
+
+func stringFunc(f func(string) string) func(args []any) (any, error) {
+	return func(args []any) (any, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expected 1 argument, got %d", len(args))
+		}
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("%v is not a string", args[0])
+		}
+		return f(s), nil
+	}
+}
+
+// words splits s into words at spaces, punctuation and the lower to upper
+// case transitions of camel case.
+func words(s string) []string {
+	var (
+		result []string
+		word   []rune
+	)
+	for _, r := range s {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			if len(word) != 0 {
+				result, word = append(result, string(word)), nil
+			}
+			continue
+		case unicode.IsUpper(r) && len(word) != 0 && !unicode.IsUpper(word[len(word)-1]):
+			result, word = append(result, string(word)), nil
+		}
+		word = append(word, r)
+	}
+	if len(word) != 0 {
+		result = append(result, string(word))
+	}
+	return result
+}
+
+// joinWords joins the lower cased words of s with sep, as in snake_case.
+func joinWords(s, sep string) string {
+	w := words(s)
+	for i := range w {
+		w[i] = strings.ToLower(w[i])
+	}
+	return strings.Join(w, sep)
+}
+
+func camelCase(s string) string {
+	w := words(s)
+	for i := range w {
+		w[i] = strings.ToLower(w[i])
+		if i > 0 {
+			w[i] = capitalize(w[i])
+		}
+	}
+	return strings.Join(w, "")
+}
+
+// titleCase capitalizes every word of s, leaving the rest untouched.
+func titleCase(s string) string {
+	fields := strings.Fields(s)
+	for i := range fields {
+		fields[i] = capitalize(fields[i])
+	}
+	return strings.Join(fields, " ")
+}
+
+func capitalize(s string) string {
+	for i, r := range s {
+		return string(unicode.ToUpper(r)) + s[i+len(string(r)):]
+	}
+	return s
+}
@@ -0,0 +1,135 @@
+package input
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/omerkaya1/gg-config/internal/variables"
+)
+
+// SecretType annotates the keys of secret variables, as in Token:secret.
+// Their values are strings, read without echo when left out in the wizard,
+// and their names are listed in the secrets of the config or file so that
+// summaries and render logs redact them.
+const SecretType = "secret"
+
+// ChoiceType annotates the keys declaring the values a variable may take,
+// as in License:choice MIT|Apache-2.0|GPL-3.0, or License: for short. The
+// wizard lets the user pick one of them; elsewhere the variable takes the
+// first one unless it is set separately.
+const ChoiceType = "choice"
+
+// ComputedType annotates the keys of computed variables, as in
+// Endpoint:expr Host + ":" + Port. Their expressions, rather than values,
+// are stored in the config and evaluated when the files are rendered, so
+// that they follow changes of the variables they are computed from.
+const ComputedType = "expr"
+
+// Redacted replaces the values of secret variables in any output.
+const Redacted = "********"
+
+// SecretKey reports whether key is annotated as a secret and returns its
+// name.
+func SecretKey(key string) (string, bool) {
+	name, typ, _ := strings.Cut(key, ":")
+	return name, typ == SecretType
+}
+
+// ChoiceKey reports whether key is annotated as declaring choices and
+// returns its name.
+func ChoiceKey(key string) (string, bool) {
+	name, typ, annotated := strings.Cut(key, ":")
+	return name, typ == ChoiceType || annotated && typ == ""
+}
+
+// ComputedKey reports whether key is annotated as computed and returns its
+// name.
+func ComputedKey(key string) (string, bool) {
+	name, typ, _ := strings.Cut(key, ":")
+	return name, typ == ComputedType
+}
+
+// AddSecret adds name to secrets unless it is already listed.
+func AddSecret(secrets []string, name string) []string {
+	if IsSecret(secrets, name) {
+		return secrets
+	}
+	secrets = append(secrets, name)
+	sort.Strings(secrets)
+	return secrets
+}
+
+// RemoveSecret removes name from secrets.
+func RemoveSecret(secrets []string, name string) []string {
+	kept := secrets[:0:0]
+	for _, s := range secrets {
+		if s != name {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+// IsSecret reports whether name is listed among secrets.
+func IsSecret(secrets []string, name string) bool {
+	for _, s := range secrets {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactVars returns a copy of the flattened vars whose secrets are
+// replaced by Redacted.
+func RedactVars(vars map[string]any, secrets []string) map[string]any {
+	flat := variables.Flatten(vars)
+	for k := range flat {
+		if IsSecret(secrets, k) {
+			flat[k] = Redacted
+		}
+	}
+	return flat
+}
+
+// SetChoices sets the choices of the variable name, creating choices if
+// needed.
+func SetChoices(choices map[string][]string, name string, values []string) map[string][]string {
+	if choices == nil {
+		choices = make(map[string][]string)
+	}
+	choices[name] = values
+	return choices
+}
+
+// ParseChoices parses the | separated choices of a variable.
+func ParseChoices(s string) ([]string, error) {
+	var choices []string
+	for _, c := range strings.Split(s, "|") {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			return nil, fmt.Errorf("empty choice in %q", s)
+		}
+		for _, prev := range choices {
+			if prev == c {
+				return nil, fmt.Errorf("duplicate choice %q", c)
+			}
+		}
+		choices = append(choices, c)
+	}
+	if len(choices) < 2 {
+		return nil, fmt.Errorf("expected at least two choices separated by |, got %q", s)
+	}
+	return choices, nil
+}
+
+// SetComputed sets the expression of the computed variable name, creating
+// computed if needed.
+func SetComputed(computed map[string]string, name, expr string) map[string]string {
+	if computed == nil {
+		computed = make(map[string]string)
+	}
+	computed[name] = expr
+	return computed
+}
@@ -0,0 +1,199 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omerkaya1/gg-config/internal/expr"
+	"github.com/omerkaya1/gg-config/internal/variables"
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+// Scope holds the global or the local variables of a file along with the
+// names of the secret ones, the expressions of the computed ones and the
+// choices of the ones restricted to some values.
+type Scope struct {
+	Vars     map[string]any
+	Secrets  []string
+	Computed map[string]string
+	Choices  map[string][]string
+	// Rules are what the variables added are checked and converted by.
+	Rules Rules
+	// Ask, if set, lets the user pick choices and enter secrets without
+	// echo.
+	Ask Asker
+}
+
+// Asker asks the user for what the variables added to a Scope leave open.
+type Asker interface {
+	// ReadSecret reads the value of the secret variable name without
+	// echoing it.
+	ReadSecret(name string) (string, error)
+	// PickChoice asks for one of the choices of the variable name, def
+	// being the one offered.
+	PickChoice(name string, choices []string, def string) (string, error)
+}
+
+// GlobalScope returns the scope of the global variables of cfg.
+func GlobalScope(cfg config.Config) Scope {
+	return Scope{Vars: cfg.Global, Secrets: cfg.Secrets, Computed: cfg.Computed, Choices: cfg.Choices}
+}
+
+// SetGlobal sets the global variables of cfg to the ones of s.
+func (s Scope) SetGlobal(cfg *config.Config) {
+	cfg.Global, cfg.Secrets, cfg.Computed, cfg.Choices = s.Vars, s.Secrets, s.Computed, s.Choices
+}
+
+// LocalScope returns the scope of the local variables of f.
+func LocalScope(f config.File) Scope {
+	return Scope{Vars: f.Local, Secrets: f.Secrets, Computed: f.Computed, Choices: f.Choices}
+}
+
+// SetLocal sets the local variables of f to the ones of s.
+func (s Scope) SetLocal(f *config.File) {
+	f.Local, f.Secrets, f.Computed, f.Choices = s.Vars, s.Secrets, s.Computed, s.Choices
+}
+
+// Add adds the variable declared by key and value to s: the expression of a
+// computed one, the choices of a restricted one or the value of any other.
+// Interactively, the value of restricted variables is picked and the one of
+// secrets declared without a value is read without echo. Values of variables
+// entered repeatedly, as recorded in entered, are collected in a list.
+func (s *Scope) Add(key, value string, entered map[string]bool) error {
+	if name, computed := ComputedKey(key); computed {
+		if _, err := expr.Parse("expression", value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := s.Rules.Keys.CheckName(name); err != nil {
+			return err
+		}
+		s.Computed = SetComputed(s.Computed, name, value)
+		return nil
+	}
+	if name, restricted := ChoiceKey(key); restricted {
+		return s.Restrict(name, value)
+	}
+	name, secret := SecretKey(key)
+	if secret && value == "" && s.Ask != nil {
+		var err error
+		if value, err = s.Ask.ReadSecret(name); err != nil {
+			return err
+		}
+	}
+	name, v, err := s.Rules.Value(key, value)
+	if err != nil {
+		return err
+	}
+	if s.Vars == nil {
+		s.Vars = make(map[string]any)
+	}
+	if err := addVariable(s.Vars, entered, name, v); err != nil {
+		return err
+	}
+	if secret {
+		s.Secrets = AddSecret(s.Secrets, name)
+	}
+	return nil
+}
+
+// Restrict declares the | separated choices of the variable name, setting
+// it to the picked one, or to the first one if it is not defined yet.
+func (s *Scope) Restrict(name, value string) error {
+	if err := s.Rules.Keys.CheckName(name); err != nil {
+		return err
+	}
+	choices, err := ParseChoices(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	def := choices[0]
+	cur, defined := variables.Lookup(s.Vars, name)
+	if defined && variables.IsChoice(cur, choices) {
+		def = variables.String(cur)
+	}
+	if s.Ask != nil {
+		if def, err = s.Ask.PickChoice(name, choices, def); err != nil {
+			return err
+		}
+	} else if defined {
+		s.Choices = SetChoices(s.Choices, name, choices)
+		return nil
+	}
+	if s.Vars == nil {
+		s.Vars = make(map[string]any)
+	}
+	_, v, err := s.Rules.Value(name, def)
+	if err == nil {
+		err = variables.Set(s.Vars, name, v)
+	}
+	if err != nil {
+		return err
+	}
+	s.Choices = SetChoices(s.Choices, name, choices)
+	return nil
+}
+
+// ParseVariable parses a variable declared either as "Key Value" or as
+// "Key=Value". List values, such as [1, "a b"], are taken verbatim.
+func ParseVariable(line string) (string, string, error) {
+	line = strings.TrimSpace(line)
+	if i := strings.IndexAny(line, " \t="); i > 0 {
+		rest := strings.TrimSpace(line[i:])
+		// So are the expressions of computed variables and choices.
+		_, computed := ComputedKey(line[:i])
+		_, restricted := ChoiceKey(line[:i])
+		if rest = strings.TrimSpace(strings.TrimPrefix(rest, "=")); strings.HasPrefix(rest, "[") || computed || restricted {
+			return line[:i], rest, nil
+		}
+	}
+	parts, err := Tokenize(line)
+	if err != nil {
+		return "", "", err
+	}
+	switch len(parts) {
+	case 1:
+		if key, value, ok := strings.Cut(parts[0], "="); ok && key != "" {
+			return key, value, nil
+		}
+		// Null annotated keys need no value, secret ones are asked for
+		// separately.
+		if strings.HasSuffix(parts[0], ":null") || strings.HasSuffix(parts[0], ":"+SecretType) {
+			return parts[0], "", nil
+		}
+	case 2:
+		return parts[0], parts[1], nil
+	}
+	return "", "", fmt.Errorf("incorrect number of tokens, expected: Key Value or Key=Value")
+}
+
+// MultiLineSentinel returns the sentinel of a multi-line value, entered as
+// <<END in place of the value, its lines following up to one holding just
+// END.
+func MultiLineSentinel(value string) (string, bool) {
+	sentinel, ok := strings.CutPrefix(value, "<<")
+	if !ok || sentinel == "" || strings.ContainsAny(sentinel, " \t") {
+		return "", false
+	}
+	return sentinel, true
+}
+
+// WithDefaults fills the global variables and (pre-)commands missing from
+// cfg with the ones of defaults.
+func WithDefaults(cfg, defaults config.Config) config.Config {
+	for k, v := range defaults.Global {
+		if _, ok := cfg.Global[k]; ok {
+			continue
+		}
+		if cfg.Global == nil {
+			cfg.Global = make(map[string]any, len(defaults.Global))
+		}
+		cfg.Global[k] = v
+	}
+	if len(cfg.PreCmds) == 0 {
+		cfg.PreCmds = defaults.PreCmds
+	}
+	if len(cfg.Cmds) == 0 {
+		cfg.Cmds = defaults.Cmds
+	}
+	return cfg
+}
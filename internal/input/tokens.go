@@ -0,0 +1,150 @@
+// Package input implements the syntax of what users enter for configs, in
+// the wizards as well as in flags and answer files: lines split into
+// tokens, variables declared with annotated keys such as Port:int 8080, and
+// the commands and environments built from them.
+package input
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/omerkaya1/gg-config/internal/variables"
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+// Tokenize splits a line into whitespace separated tokens. Double quoted
+// tokens may contain whitespace and the escape sequences \", \\, \n and \t;
+// single quoted tokens are taken literally. Outside of quotes a backslash
+// escapes the following character.
+func Tokenize(line string) ([]string, error) {
+	var (
+		tokens  []string
+		cur     strings.Builder
+		inToken bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+			if quote == '"' {
+				switch r {
+				case 'n':
+					r = '\n'
+				case 't':
+					r = '\t'
+				case '"', '\\':
+				default:
+					cur.WriteRune('\\')
+				}
+			}
+			cur.WriteRune(r)
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+				continue
+			}
+			cur.WriteRune(r)
+		case r == '\\':
+			escaped, inToken = true, true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+				continue
+			}
+			cur.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote, inToken = r, true
+		case unicode.IsSpace(r):
+			if inToken {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+				inToken = false
+			}
+		default:
+			cur.WriteRune(r)
+			inToken = true
+		}
+	}
+	switch {
+	case escaped:
+		return nil, fmt.Errorf("unfinished escape sequence")
+	case quote != 0:
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inToken {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens, nil
+}
+
+// JoinTokens is the inverse of Tokenize.
+func JoinTokens(tokens []string) string {
+	quoted := make([]string, len(tokens))
+	for i, t := range tokens {
+		quoted[i] = variables.Quote(t)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// ParseCommand splits a command line into the command and its arguments.
+func ParseCommand(line string) (config.Command, error) {
+	parts, err := Tokenize(line)
+	if err != nil {
+		return config.Command{}, err
+	}
+	if len(parts) == 0 {
+		return config.Command{}, fmt.Errorf("incorrect command declaration length")
+	}
+	return config.Command{Name: parts[0], Args: parts[1:]}, nil
+}
+
+// ParseEnv parses whitespace separated KEY=value pairs, as accepted by
+// Tokenize.
+func ParseEnv(s string) (map[string]string, error) {
+	parts, err := Tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	var env map[string]string
+	for _, part := range parts {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || !variables.IsEnvName(key) {
+			return nil, fmt.Errorf("environment variable %q: expected KEY=value", part)
+		}
+		if env == nil {
+			env = make(map[string]string)
+		}
+		env[key] = value
+	}
+	return env, nil
+}
+
+// JoinEnv is the inverse of ParseEnv.
+func JoinEnv(env map[string]string) string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = variables.Quote(k + "=" + env[k])
+	}
+	return strings.Join(parts, " ")
+}
+
+const shellMetachars = "|&;<>()$`*?[]{}~"
+
+// templateAction matches the template actions allowed in command arguments.
+var templateAction = regexp.MustCompile(`\{\{.*?\}\}`)
+
+// HasShellSyntax reports whether s contains shell syntax outside of template
+// actions.
+func HasShellSyntax(s string) bool {
+	return strings.ContainsAny(templateAction.ReplaceAllString(s, ""), shellMetachars)
+}
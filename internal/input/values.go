@@ -0,0 +1,245 @@
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/omerkaya1/gg-config/internal/variables"
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+// variableTypes convert the values of variables whose key is annotated with
+// a type, as in Port:string 8080, instead of guessing it with Rules.Format.
+var variableTypes = map[string]func(s string) (any, error){
+	"string": func(s string) (any, error) {
+		return s, nil
+	},
+	"int": func(s string) (any, error) {
+		return strconv.ParseInt(s, 10, 64)
+	},
+	"float": func(s string) (any, error) {
+		return strconv.ParseFloat(s, 64)
+	},
+	"bool": func(s string) (any, error) {
+		return strconv.ParseBool(s)
+	},
+	// Numbers are kept exactly as written, whatever their size.
+	"number": func(s string) (any, error) {
+		if !variables.IsNumber(s) {
+			return nil, fmt.Errorf("invalid number %q", s)
+		}
+		return json.Number(s), nil
+	},
+	// Times are kept as such, encoded in RFC 3339 format, whereas durations
+	// are normalized strings.
+	"time": func(s string) (any, error) {
+		return time.Parse(time.RFC3339, s)
+	},
+	"duration": func(s string) (any, error) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, err
+		}
+		return d.String(), nil
+	},
+	SecretType: func(s string) (any, error) {
+		return s, nil
+	},
+	"null": func(s string) (any, error) {
+		if s != "" && s != "null" && s != variables.Null {
+			return nil, fmt.Errorf("unexpected value %q", s)
+		}
+		return nil, nil
+	},
+}
+
+// Rules are what the variables entered are checked and converted by: their
+// names are checked against Keys and, unless NoInfer is set, the type of
+// values entered without a type annotation is guessed. The zero value holds
+// the default rules.
+type Rules struct {
+	Keys    config.KeyRules
+	NoInfer bool
+}
+
+// Format guesses the type of a value entered without a type annotation, as
+// variables.Guess does, unless r.NoInfer is set, in which case every value
+// is a string.
+func (r Rules) Format(v string) any {
+	if r.NoInfer {
+		return v
+	}
+	return variables.Guess(v)
+}
+
+func variableTypeNames() []string {
+	names := make([]string, 0, len(variableTypes))
+	for name := range variableTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Value checks the key of a variable, which may be dotted, such as db.host,
+// and annotated with a type, converts value accordingly and returns the key
+// without the annotation. Values enclosed in brackets, such as [8080, 8081],
+// are lists whose elements the annotation applies to, except for the string
+// and secret ones, which take values literally.
+func (r Rules) Value(key, value string) (string, any, error) {
+	name, typ, typed := strings.Cut(key, ":")
+	if err := r.Keys.CheckName(name); err != nil {
+		return "", nil, err
+	}
+	parse := func(s string) (any, error) {
+		if s == variables.Null {
+			return nil, nil
+		}
+		return r.Format(s), nil
+	}
+	if typed {
+		var ok bool
+		if parse, ok = variableTypes[typ]; !ok {
+			return "", nil, fmt.Errorf("%s: unknown type %q, expected one of %s", name, typ, strings.Join(variableTypeNames(), ", "))
+		}
+	}
+
+	body, isList := strings.CutPrefix(strings.TrimSpace(value), "[")
+	if body, isList = strings.CutSuffix(body, "]"); !isList || typ == "string" || typ == SecretType {
+		v, err := parse(value)
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: invalid %s %q", name, typ, value)
+		}
+		return name, v, nil
+	}
+	elems, err := splitList(body)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", name, err)
+	}
+	list := make([]any, len(elems))
+	for i, e := range elems {
+		// Quoted elements are strings unless annotated otherwise.
+		if e.quoted && !typed {
+			list[i] = e.text
+			continue
+		}
+		if list[i], err = parse(e.text); err != nil {
+			return "", nil, fmt.Errorf("%s: invalid %s %q", name, typ, e.text)
+		}
+	}
+	return name, list, nil
+}
+
+type listElem struct {
+	text   string
+	quoted bool
+}
+
+// splitList splits the comma separated elements of a list, which may be
+// double quoted to contain commas or brackets.
+func splitList(s string) ([]listElem, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var elems []listElem
+	for _, part := range SplitTopLevel(s, ',') {
+		part = strings.TrimSpace(part)
+		if !strings.HasPrefix(part, `"`) {
+			if part == "" || strings.ContainsAny(part, `[]"`) {
+				return nil, fmt.Errorf("invalid list element %q", part)
+			}
+			elems = append(elems, listElem{part, false})
+			continue
+		}
+		text, err := strconv.Unquote(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid list element %s", part)
+		}
+		elems = append(elems, listElem{text, true})
+	}
+	return elems, nil
+}
+
+// SplitTopLevel splits s at the occurrences of sep outside of double quotes
+// and brackets.
+func SplitTopLevel(s string, sep byte) []string {
+	var (
+		parts   []string
+		start   int
+		depth   int
+		quoted  bool
+		escaped bool
+	)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case escaped:
+			escaped = false
+		case c == '\\' && quoted:
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// addVariable sets the variable name of vars to v. Names already recorded in
+// seen collect their values in a list instead, so that a variable entered
+// repeatedly is a list of all its values.
+func addVariable(vars map[string]any, seen map[string]bool, name string, v any) error {
+	if seen[name] {
+		cur, _ := variables.Lookup(vars, name)
+		if list, ok := cur.([]any); ok {
+			v = append(list, v)
+		} else {
+			v = []any{cur, v}
+		}
+	}
+	if seen != nil {
+		seen[name] = true
+	}
+	return variables.Set(vars, name, v)
+}
+
+// AnnotatedKey returns name annotated with the type of v if r.Format would
+// guess another one from its text, so that r.Value reads the pair back
+// unchanged.
+func (r Rules) AnnotatedKey(name string, v any) string {
+	var typ string
+	switch v.(type) {
+	case string:
+		typ = "string"
+	case int64:
+		typ = "int"
+	case float64:
+		typ = "float"
+	case bool:
+		typ = "bool"
+	case json.Number:
+		typ = "number"
+	case time.Time:
+		return name + ":time"
+	default:
+		return name
+	}
+	if s, ok := v.(string); ok && (s == variables.Null || strings.HasPrefix(strings.TrimSpace(s), "[") && strings.HasSuffix(s, "]")) {
+		// Would be read back as null or a list otherwise.
+		return name + ":string"
+	}
+	if fmt.Sprintf("%T", r.Format(fmt.Sprint(v))) == fmt.Sprintf("%T", v) {
+		return name
+	}
+	return name + ":" + typ
+}
@@ -0,0 +1,128 @@
+// Package probe checks what users enter for configs against the system
+// gg-config runs on: whether the files can be written where they are to be
+// generated and the commands are found in PATH.
+package probe
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+// maxSuggestions is the number of similarly named executables suggested for
+// a command that cannot be found.
+const maxSuggestions = 3
+
+// CheckCommand warns if the executable of a command cannot be found in PATH,
+// suggesting similarly named ones.
+func CheckCommand(name string) config.ValidationErrors {
+	if name == "" {
+		return nil
+	}
+	if _, err := exec.LookPath(name); err == nil {
+		return nil
+	}
+	msg := fmt.Sprintf("command %q not found in PATH", name)
+	if s := suggestCommands(name); len(s) != 0 {
+		msg += fmt.Sprintf(", did you mean %s?", strings.Join(s, ", "))
+	}
+	return config.ValidationErrors{{Severity: config.SeverityWarning, Message: msg}}
+}
+
+// suggestCommands returns the executables in PATH closest to name.
+func suggestCommands(name string) []string {
+	type candidate struct {
+		name     string
+		distance int
+	}
+
+	var (
+		candidates []candidate
+		maxDist    = max(2, len(name)/3)
+	)
+	for _, n := range pathExecutables() {
+		if d := editDistance(name, n); d <= maxDist {
+			candidates = append(candidates, candidate{n, d})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].name < candidates[j].name
+	})
+	var names []string
+	for i := 0; i < len(candidates) && i < maxSuggestions; i++ {
+		names = append(names, candidates[i].name)
+	}
+	return names
+}
+
+// pathExecutables returns the sorted names of the files found in the
+// directories of PATH.
+func pathExecutables() []string {
+	var (
+		seen  = make(map[string]bool)
+		names []string
+	)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if n := e.Name(); !seen[n] && !e.IsDir() {
+				seen[n] = true
+				names = append(names, n)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CompleteCommand returns the executables in PATH whose name starts with
+// prefix.
+func CompleteCommand(prefix string) []string {
+	var matches []string
+	for _, n := range pathExecutables() {
+		if strings.HasPrefix(n, prefix) {
+			matches = append(matches, n)
+		}
+	}
+	return matches
+}
+
+// editDistance returns the optimal string alignment distance between a and
+// b: the Levenshtein distance with transpositions of adjacent characters
+// counted as a single edit.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
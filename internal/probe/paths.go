@@ -0,0 +1,83 @@
+package probe
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+// CheckFilePath verifies that a file called name can be written into the
+// directory dir: the path has to be well-formed, the directory has to exist
+// or be creatable, and both the directory and an already existing target
+// have to be writable.
+func CheckFilePath(dir, name string) config.ValidationErrors {
+	if dir == "" {
+		return nil
+	}
+	if strings.ContainsRune(dir, 0) || strings.ContainsRune(name, 0) {
+		return config.ValidationErrors{{Severity: config.SeverityError, Message: "path contains a NUL byte"}}
+	}
+
+	info, err := os.Stat(dir)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		ancestor := existingAncestor(dir)
+		if err := checkWritableDir(ancestor); err != nil {
+			return config.ValidationErrors{{Severity: config.SeverityError, Message: fmt.Sprintf("directory %s cannot be created: %s", dir, err)}}
+		}
+		return config.ValidationErrors{{Severity: config.SeverityWarning, Message: fmt.Sprintf("directory %s does not exist and will be created", dir)}}
+	case err != nil:
+		return config.ValidationErrors{{Severity: config.SeverityError, Message: err.Error()}}
+	case !info.IsDir():
+		return config.ValidationErrors{{Severity: config.SeverityError, Message: fmt.Sprintf("%s is not a directory", dir)}}
+	}
+	if err = checkWritableDir(dir); err != nil {
+		return config.ValidationErrors{{Severity: config.SeverityError, Message: fmt.Sprintf("directory %s is not writable: %s", dir, err)}}
+	}
+
+	if name == "" {
+		return nil
+	}
+	target := filepath.Join(dir, name)
+	info, err = os.Stat(target)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil
+	case err != nil:
+		return config.ValidationErrors{{Severity: config.SeverityError, Message: err.Error()}}
+	case info.IsDir():
+		return config.ValidationErrors{{Severity: config.SeverityError, Message: fmt.Sprintf("%s is a directory", target)}}
+	}
+	f, err := os.OpenFile(target, os.O_WRONLY, 0)
+	if err != nil {
+		return config.ValidationErrors{{Severity: config.SeverityError, Message: fmt.Sprintf("%s is not writable: %s", target, err)}}
+	}
+	f.Close()
+	return nil
+}
+
+// existingAncestor returns the closest existing parent of path.
+func existingAncestor(path string) string {
+	for {
+		parent := filepath.Dir(path)
+		if _, err := os.Stat(parent); err == nil || parent == path {
+			return parent
+		}
+		path = parent
+	}
+}
+
+// checkWritableDir tests whether files can be created in dir by creating and
+// removing a temporary one.
+func checkWritableDir(dir string) error {
+	f, err := os.CreateTemp(dir, ".gg-config-*")
+	if err != nil {
+		return errors.Unwrap(err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,105 @@
+package variables
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// referencePattern matches the references to global variables in the
+// values of local ones, such as ${ProjectName} or ${db.host}, and $$, which
+// stands for a literal $.
+var referencePattern = regexp.MustCompile(`\$\$|\$\{([^}]*)\}`)
+
+// Expand returns a copy of the local variables vars whose string values,
+// including nested and listed ones, have their references replaced by the
+// values of the variables of global. A value consisting of a single reference
+// takes the referenced value as is, keeping its type.
+func Expand(vars, global map[string]any) (map[string]any, error) {
+	if vars == nil {
+		return nil, nil
+	}
+	expanded := make(map[string]any, len(vars))
+	for k, v := range vars {
+		ev, err := expandValue(v, global)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		expanded[k] = ev
+	}
+	return expanded, nil
+}
+
+func expandValue(v any, global map[string]any) (any, error) {
+	switch val := v.(type) {
+	case string:
+		if m := referencePattern.FindStringSubmatch(val); m != nil && m[0] == val && m[0] != "$$" {
+			ref, ok := Lookup(global, strings.TrimSpace(m[1]))
+			if !ok {
+				return nil, fmt.Errorf("reference to undefined variable %q", strings.TrimSpace(m[1]))
+			}
+			return ref, nil
+		}
+		return expandString(val, global)
+	case map[string]any:
+		return Expand(val, global)
+	case []any:
+		list := make([]any, len(val))
+		for i, e := range val {
+			ev, err := expandValue(e, global)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = ev
+		}
+		return list, nil
+	default:
+		return v, nil
+	}
+}
+
+func expandString(s string, global map[string]any) (string, error) {
+	var err error
+	expanded := referencePattern.ReplaceAllStringFunc(s, func(m string) string {
+		if m == "$$" {
+			return "$"
+		}
+		name := strings.TrimSpace(m[2 : len(m)-1])
+		ref, ok := Lookup(global, name)
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("reference to undefined variable %q", name)
+			}
+			return m
+		}
+		return String(ref)
+	})
+	return expanded, err
+}
+
+// References returns the names of the variables referenced by the string
+// values of vars.
+func References(vars map[string]any) map[string]bool {
+	names := make(map[string]bool)
+	var walk func(v any)
+	walk = func(v any) {
+		switch val := v.(type) {
+		case string:
+			for _, m := range referencePattern.FindAllStringSubmatch(val, -1) {
+				if m[0] != "$$" {
+					names[strings.TrimSpace(m[1])] = true
+				}
+			}
+		case map[string]any:
+			for _, e := range val {
+				walk(e)
+			}
+		case []any:
+			for _, e := range val {
+				walk(e)
+			}
+		}
+	}
+	walk(vars)
+	return names
+}
@@ -0,0 +1,82 @@
+package variables
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	global := map[string]any{
+		"Name": "demo",
+		"Port": int64(8080),
+		"Tags": []any{"a", "b"},
+		"db":   map[string]any{"host": "localhost"},
+	}
+	tests := []struct {
+		name    string
+		vars    map[string]any
+		want    map[string]any
+		wantErr string
+	}{
+		{name: "nil", vars: nil, want: nil},
+		{
+			name: "strings",
+			vars: map[string]any{
+				"Image": "${Name}:latest",
+				"URL":   "http://${ db.host }:${Port}/",
+				"Price": "$$5 for ${Name}",
+				"Raw":   "$Name and $$",
+			},
+			want: map[string]any{
+				"Image": "demo:latest",
+				"URL":   "http://localhost:8080/",
+				"Price": "$5 for demo",
+				"Raw":   "$Name and $",
+			},
+		},
+		{
+			name: "whole values keep their type",
+			vars: map[string]any{"Port": "${Port}", "Tags": "${Tags}", "DB": "${db}", "Dollar": "$$"},
+			want: map[string]any{"Port": int64(8080), "Tags": []any{"a", "b"}, "DB": map[string]any{"host": "localhost"}, "Dollar": "$"},
+		},
+		{
+			name: "nested and listed",
+			vars: map[string]any{"svc": map[string]any{"name": "${Name}-svc"}, "List": []any{"${Port}", int64(1), "x${Name}"}},
+			want: map[string]any{"svc": map[string]any{"name": "demo-svc"}, "List": []any{int64(8080), int64(1), "xdemo"}},
+		},
+		{name: "undefined", vars: map[string]any{"A": "${Nope}"}, wantErr: `A: reference to undefined variable "Nope"`},
+		{name: "undefined inside", vars: map[string]any{"A": "x-${db.port}-${Other}"}, wantErr: `A: reference to undefined variable "db.port"`},
+		{name: "undefined nested", vars: map[string]any{"svc": map[string]any{"name": []any{"${Nope}"}}}, wantErr: `svc: name: reference to undefined variable "Nope"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Expand(tt.vars, global)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReferences(t *testing.T) {
+	vars := map[string]any{
+		"A":    "${Name}-${ db.host }",
+		"B":    "$$ and ${Port}",
+		"List": []any{"${Tags}", int64(1)},
+		"svc":  map[string]any{"name": "${Name}"},
+		"N":    int64(3),
+	}
+	want := map[string]bool{"Name": true, "db.host": true, "Port": true, "Tags": true}
+	if got := References(vars); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,255 @@
+// Package variables handles the variables of gg-config configs: nested
+// ones addressed by dotted names, such as db.host, the way their values are
+// guessed from and written as text, and the data templates and conditions
+// are evaluated against.
+package variables
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Null stands for null in variables without a type annotation.
+const Null = "null!"
+
+// Builtin holds the variables available to templates and conditions unless a
+// variable of the same name is defined: now is the time gg-config was started
+// at.
+var Builtin = map[string]any{
+	"now": time.Now(),
+}
+
+var (
+	// integerPattern and numberPattern match decimal integers and numbers
+	// as JSON spells them.
+	integerPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)$`)
+	numberPattern  = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+)
+
+// IsNumber reports whether s is a decimal number as JSON spells it.
+func IsNumber(s string) bool {
+	return numberPattern.MatchString(s)
+}
+
+// Guess guesses the type of a value entered without a type annotation, in
+// this order: true and false are booleans, decimal integers int64 values and
+// other decimal numbers float64 values, both spelled as in JSON. Numbers out
+// of range are kept as json.Number, so that no digit is lost, and anything
+// else, such as 007, 0x1F, 1_000 or NaN, is a string.
+func Guess(v string) any {
+	switch {
+	case v == "true" || v == "false":
+		return v == "true"
+	case integerPattern.MatchString(v):
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+		return json.Number(v)
+	case numberPattern.MatchString(v):
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+		return json.Number(v)
+	default:
+		return v
+	}
+}
+
+// Set sets the variable name of vars to v. Dotted names, such as db.host, set
+// the fields of nested maps, which are created as needed.
+func Set(vars map[string]any, name string, v any) error {
+	parent, last, found := strings.Cut(name, ".")
+	if !found {
+		vars[name] = v
+		return nil
+	}
+	nested, ok := vars[parent].(map[string]any)
+	if !ok {
+		if cur, set := vars[parent]; set {
+			return fmt.Errorf("%s: %s is %s, not a set of variables", name, parent, Display(cur))
+		}
+		nested = make(map[string]any)
+		vars[parent] = nested
+	}
+	if err := Set(nested, last, v); err != nil {
+		return fmt.Errorf("%s.%w", parent, err)
+	}
+	return nil
+}
+
+// Lookup returns the variable name of vars, following dotted names into nested
+// maps.
+func Lookup(vars map[string]any, name string) (any, bool) {
+	if v, ok := vars[name]; ok {
+		return v, true
+	}
+	parent, last, found := strings.Cut(name, ".")
+	if !found {
+		return nil, false
+	}
+	nested, ok := vars[parent].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	return Lookup(nested, last)
+}
+
+// Delete removes the variable name from vars, along with the nested maps left
+// empty.
+func Delete(vars map[string]any, name string) {
+	parent, last, found := strings.Cut(name, ".")
+	if !found {
+		delete(vars, name)
+		return
+	}
+	if nested, ok := vars[parent].(map[string]any); ok {
+		Delete(nested, last)
+		if len(nested) == 0 {
+			delete(vars, parent)
+		}
+	}
+}
+
+// Flatten returns the variables of vars with the ones of nested maps under
+// their dotted names.
+func Flatten(vars map[string]any) map[string]any {
+	flat := make(map[string]any, len(vars))
+	for k, v := range vars {
+		nested, ok := v.(map[string]any)
+		if !ok || len(nested) == 0 {
+			flat[k] = v
+			continue
+		}
+		for nk, nv := range Flatten(nested) {
+			flat[k+"."+nk] = nv
+		}
+	}
+	return flat
+}
+
+// String formats v the way values entered are read back, lists as [a, b] and
+// null as null!.
+func String(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return Null
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	}
+	list, ok := v.([]any)
+	if !ok {
+		return fmt.Sprint(v)
+	}
+	elems := make([]string, len(list))
+	for i, e := range list {
+		s, isString := e.(string)
+		switch {
+		case !isString:
+			elems[i] = String(e)
+		case s == "" || s == Null || strings.ContainsAny(s, `,[]" `) || fmt.Sprintf("%T", Guess(s)) != "string":
+			elems[i] = strconv.Quote(s)
+		default:
+			elems[i] = s
+		}
+	}
+	return "[" + strings.Join(elems, ", ") + "]"
+}
+
+// Display formats v for listings of variables, quoting it unless it is a list.
+func Display(v any) string {
+	if _, ok := v.([]any); ok {
+		return String(v)
+	}
+	return Quote(String(v))
+}
+
+// Quote quotes s, if needed, so that it is read back as a single token of
+// a command line.
+func Quote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\r\n\"'\\") {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// WithCaptured returns a copy of global extended with the captured variables,
+// which take precedence.
+func WithCaptured(global map[string]any, captured map[string]string) map[string]any {
+	if len(captured) == 0 {
+		return global
+	}
+	vars := make(map[string]any, len(global)+len(captured))
+	for k, v := range global {
+		vars[k] = v
+	}
+	for k, v := range captured {
+		vars[k] = v
+	}
+	return vars
+}
+
+// Data returns the variables templates and conditions are evaluated
+// against: the builtin ones, global and local, each taking precedence over
+// the former.
+func Data(global, local map[string]any) map[string]any {
+	data := make(map[string]any, len(Builtin)+len(global)+len(local))
+	for k, v := range Builtin {
+		data[k] = v
+	}
+	for k, v := range global {
+		data[k] = v
+	}
+	for k, v := range local {
+		data[k] = v
+	}
+	return data
+}
+
+// ForCommands returns the variables the when conditions of commands are
+// evaluated against: vars, the captured variables, which take precedence over
+// them, and os and arch, describing the running system, which no variable
+// overrides.
+func ForCommands(vars map[string]any, captured map[string]string) map[string]any {
+	all := make(map[string]any, len(vars)+len(captured)+2)
+	for k, v := range vars {
+		all[k] = v
+	}
+	for k, v := range captured {
+		all[k] = v
+	}
+	all["os"], all["arch"] = runtime.GOOS, runtime.GOARCH
+	return all
+}
+
+// IsEnvName reports whether s is a valid environment variable name.
+func IsEnvName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// IsChoice reports whether v is one of choices, which are compared by their
+// text.
+func IsChoice(v any, choices []string) bool {
+	for _, c := range choices {
+		if String(Guess(c)) == String(v) {
+			return true
+		}
+	}
+	return false
+}
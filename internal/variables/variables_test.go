@@ -0,0 +1,213 @@
+package variables
+
+import (
+	"encoding/json"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestGuess(t *testing.T) {
+	tests := []struct {
+		in   string
+		want any
+	}{
+		{"true", true},
+		{"false", false},
+		{"True", "True"},
+		{"42", int64(42)},
+		{"-7", int64(-7)},
+		{"0", int64(0)},
+		{"007", "007"},
+		{"9223372036854775808", json.Number("9223372036854775808")},
+		{"2.5", 2.5},
+		{"1e3", 1000.0},
+		{"1e999", json.Number("1e999")},
+		{".5", ".5"},
+		{"0x1F", "0x1F"},
+		{"1_000", "1_000"},
+		{"NaN", "NaN"},
+		{"", ""},
+		{"demo", "demo"},
+	}
+	for _, tt := range tests {
+		if got := Guess(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Guess(%q) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSetLookupDelete(t *testing.T) {
+	vars := map[string]any{"Name": "demo"}
+	for name, v := range map[string]any{"db.host": "localhost", "db.pool.max": int64(10), "Port": int64(80)} {
+		if err := Set(vars, name, v); err != nil {
+			t.Fatalf("Set(%s): %v", name, err)
+		}
+	}
+	want := map[string]any{
+		"Name": "demo",
+		"Port": int64(80),
+		"db":   map[string]any{"host": "localhost", "pool": map[string]any{"max": int64(10)}},
+	}
+	if !reflect.DeepEqual(vars, want) {
+		t.Fatalf("got %#v, want %#v", vars, want)
+	}
+	if err := Set(vars, "Name.first", "x"); err == nil || err.Error() != "Name.first: Name is demo, not a set of variables" {
+		t.Errorf("got error %v setting a field of a string", err)
+	}
+	if err := Set(vars, "db.host.name", "x"); err == nil || err.Error() != "db.host.name: host is localhost, not a set of variables" {
+		t.Errorf("got error %v setting a nested field of a string", err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		want any
+		ok   bool
+	}{
+		{"Name", "demo", true},
+		{"db.pool.max", int64(10), true},
+		{"db.pool", map[string]any{"max": int64(10)}, true},
+		{"db.port", nil, false},
+		{"Name.first", nil, false},
+		{"Missing", nil, false},
+	} {
+		got, ok := Lookup(vars, tt.name)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Lookup(%s) = %#v, %v, want %#v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+	// Dotted keys are found as they are first.
+	if got, ok := Lookup(map[string]any{"a.b": 1}, "a.b"); !ok || got != 1 {
+		t.Errorf("Lookup of a dotted key got %v, %v", got, ok)
+	}
+
+	Delete(vars, "db.pool.max")
+	Delete(vars, "Missing.field")
+	Delete(vars, "Port")
+	if want := map[string]any{"Name": "demo", "db": map[string]any{"host": "localhost"}}; !reflect.DeepEqual(vars, want) {
+		t.Errorf("after Delete got %#v, want %#v", vars, want)
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	vars := map[string]any{
+		"Name":  "demo",
+		"Empty": map[string]any{},
+		"db":    map[string]any{"host": "localhost", "pool": map[string]any{"max": int64(10)}},
+	}
+	want := map[string]any{
+		"Name":        "demo",
+		"Empty":       map[string]any{},
+		"db.host":     "localhost",
+		"db.pool.max": int64(10),
+	}
+	if got := Flatten(vars); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		v       any
+		str     string
+		display string
+	}{
+		{nil, Null, Null},
+		{"demo", "demo", "demo"},
+		{"a b", "a b", `"a b"`},
+		{"", "", `""`},
+		{int64(8080), "8080", "8080"},
+		{2.5, "2.5", "2.5"},
+		{true, "true", "true"},
+		{time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC), "2024-01-02T03:04:05.000000006Z", "2024-01-02T03:04:05.000000006Z"},
+		{[]any{"a", int64(1), "1", "a b", "", Null, "true", nil, []any{"x"}}, `[a, 1, "1", "a b", "", "null!", "true", null!, [x]]`, `[a, 1, "1", "a b", "", "null!", "true", null!, [x]]`},
+	}
+	for _, tt := range tests {
+		if got := String(tt.v); got != tt.str {
+			t.Errorf("String(%#v) = %s, want %s", tt.v, got, tt.str)
+		}
+		if got := Display(tt.v); got != tt.display {
+			t.Errorf("Display(%#v) = %s, want %s", tt.v, got, tt.display)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"", `""`},
+		{"a b", `"a b"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{"C:\\dir", `"C:\\dir"`},
+		{"line\nbreak\ttab", `"line\nbreak\ttab"`},
+		{"it's", `"it's"`},
+	}
+	for _, tt := range tests {
+		if got := Quote(tt.in); got != tt.want {
+			t.Errorf("Quote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestData(t *testing.T) {
+	global := map[string]any{"Name": "global", "Port": int64(80)}
+	local := map[string]any{"Name": "local"}
+	data := Data(global, local)
+	if data["Name"] != "local" || data["Port"] != int64(80) {
+		t.Errorf("got %v, want the local Name over the global one", data)
+	}
+	if _, ok := data["now"].(time.Time); !ok {
+		t.Errorf("got now %#v, want the builtin time", data["now"])
+	}
+	if data := Data(map[string]any{"now": "then"}, nil); data["now"] != "then" {
+		t.Errorf("got now %v, want the global overriding the builtin", data["now"])
+	}
+
+	captured := WithCaptured(global, map[string]string{"Name": "captured", "Rev": "abc"})
+	if want := map[string]any{"Name": "captured", "Port": int64(80), "Rev": "abc"}; !reflect.DeepEqual(captured, want) {
+		t.Errorf("WithCaptured got %v, want %v", captured, want)
+	}
+	if global["Name"] != "global" {
+		t.Error("WithCaptured changed the globals")
+	}
+
+	cmds := ForCommands(map[string]any{"os": "plan9", "Name": "demo"}, map[string]string{"Name": "captured"})
+	if want := map[string]any{"os": runtime.GOOS, "arch": runtime.GOARCH, "Name": "captured"}; !reflect.DeepEqual(cmds, want) {
+		t.Errorf("ForCommands got %v, want %v", cmds, want)
+	}
+}
+
+func TestIsEnvName(t *testing.T) {
+	for s, want := range map[string]bool{
+		"PATH": true, "_x1": true, "go_flags": true,
+		"": false, "1X": false, "GO-FLAGS": false, "A B": false, "ÄB": false,
+	} {
+		if got := IsEnvName(s); got != want {
+			t.Errorf("IsEnvName(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestIsChoice(t *testing.T) {
+	choices := []string{"dev", "1", "true", "2.50"}
+	tests := []struct {
+		v    any
+		want bool
+	}{
+		{"dev", true},
+		{int64(1), true},
+		{"1", true},
+		{true, true},
+		{2.5, true},
+		{"prod", false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsChoice(tt.v, choices); got != tt.want {
+			t.Errorf("IsChoice(%#v) = %v, want %v", tt.v, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Builder constructs a Config in Go code, as in
+//
+//	cfg, err := config.New().
+//		Global("ProjectName", "demo").
+//		File(config.File{Name: "main.go", Path: ".", Template: "main.tmpl"}).
+//		Command("go", "mod", "tidy").
+//		Build()
+//
+// Mistakes, such as a file without a template, are collected along the way
+// and reported by Build.
+type Builder struct {
+	cfg  Config
+	errs []error
+}
+
+// New returns a Builder for an empty config.
+func New() *Builder {
+	return &Builder{cfg: Config{Version: Version, Global: make(map[string]any)}}
+}
+
+// Global sets the global variable name to value. Numbers of any Go numeric
+// type are stored the way they are read from a config file.
+func (b *Builder) Global(name string, value any) *Builder {
+	if name == "" {
+		b.errs = append(b.errs, errors.New("global variable without a name"))
+		return b
+	}
+	b.cfg.Global[name] = NormalizeValue(value)
+	return b
+}
+
+// Secret sets the global variable name to value and lists it among the
+// secrets, whose values are redacted from summaries and logs.
+func (b *Builder) Secret(name, value string) *Builder {
+	b.Global(name, value)
+	for _, s := range b.cfg.Secrets {
+		if s == name {
+			return b
+		}
+	}
+	b.cfg.Secrets = append(b.cfg.Secrets, name)
+	sort.Strings(b.cfg.Secrets)
+	return b
+}
+
+// File adds a file to be generated out of a template.
+func (b *Builder) File(f File) *Builder {
+	switch {
+	case f.Name == "":
+		b.errs = append(b.errs, fmt.Errorf("files[%d]: no name", len(b.cfg.Files)))
+	case f.Template == "":
+		b.errs = append(b.errs, fmt.Errorf("files[%d]: no template", len(b.cfg.Files)))
+	}
+	if f.Local == nil {
+		f.Local = make(map[string]any)
+	}
+	f.Local = NormalizeVars(f.Local)
+	b.cfg.Files = append(b.cfg.Files, f)
+	return b
+}
+
+// PreCommand adds a command run before the files are rendered.
+func (b *Builder) PreCommand(name string, args ...string) *Builder {
+	b.cfg.PreCmds = append(b.cfg.PreCmds, b.command("pre_commands", len(b.cfg.PreCmds), name, args))
+	return b
+}
+
+// Command adds a command run after the files have been rendered.
+func (b *Builder) Command(name string, args ...string) *Builder {
+	b.cfg.Cmds = append(b.cfg.Cmds, b.command("commands", len(b.cfg.Cmds), name, args))
+	return b
+}
+
+func (b *Builder) command(section string, i int, name string, args []string) Command {
+	if name == "" {
+		b.errs = append(b.errs, fmt.Errorf("%s[%d]: no name", section, i))
+	}
+	if args == nil {
+		args = []string{}
+	}
+	return Command{Name: name, Args: args}
+}
+
+// Build returns the constructed config, or the mistakes made building it.
+func (b *Builder) Build() (Config, error) {
+	if err := errors.Join(b.errs...); err != nil {
+		return Config{}, fmt.Errorf("build config: %w", err)
+	}
+	return b.cfg, nil
+}
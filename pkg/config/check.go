@@ -0,0 +1,406 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/omerkaya1/gg-config/internal/expr"
+	"github.com/omerkaya1/gg-config/internal/variables"
+	"github.com/omerkaya1/gg-config/pkg/templates"
+)
+
+// ErrNoFiles reports a config without files, which Check only accepts with
+// CheckOptions.AllowEmptyFiles.
+var ErrNoFiles = errors.New("at least one file is required")
+
+// CheckOptions configures Check.
+type CheckOptions struct {
+	// Templates, if set, is where the templates of files are looked up and
+	// parsed.
+	Templates fs.FS
+	// Strict turns every warning into an error.
+	Strict bool
+	// TargetOS is the system, as in GOOS, the paths of files are checked
+	// for, the current one if empty.
+	TargetOS string
+	// AllowEmptyFiles accepts configs without files.
+	AllowEmptyFiles bool
+	// Keys are the rules the names of variables are checked against.
+	Keys KeyRules
+	// CheckPath, if set, checks that a file called name can be written
+	// into the directory dir, both native paths.
+	CheckPath func(dir, name string) ValidationErrors
+	// CheckCommand, if set, checks that the command called name can be
+	// run.
+	CheckCommand func(name string) ValidationErrors
+}
+
+// Check checks cfg beyond Validate: the names of variables, their choices,
+// secrets and references, the expressions of computed variables and
+// conditions, the settings of commands, the paths of files and, given the
+// templates, whether they parse and the variables they refer to are provided
+// and the ones provided are used. It returns every problem found, errors as
+// well as warnings and informative ones. Check does not look at the file
+// system or the commands installed unless told to by opts.
+func Check(cfg Config, opts CheckOptions) ValidationErrors {
+	var problems ValidationErrors
+	if len(cfg.Global) == 0 {
+		problems = append(problems, ValidationError{Severity: SeverityWarning, Path: "global", Message: "no global variables defined"})
+	}
+	if len(cfg.Files) == 0 && !opts.AllowEmptyFiles {
+		problems = append(problems, ValidationError{Severity: SeverityError, Path: "files", Message: ErrNoFiles.Error()})
+	}
+	// Missing fields and invalid command settings.
+	problems = append(problems, Validate(cfg)...)
+	problems = append(problems, checkKeys(opts.Keys, "global", cfg.Global)...)
+	problems = append(problems, checkEnvironments(cfg, opts.Keys)...)
+	problems = append(problems, checkSecrets("secrets", cfg.Secrets, cfg.Global)...)
+	problems = append(problems, checkChoices("choices", cfg.Choices, cfg.Global)...)
+
+	// Variables captured by pre-commands are available to the templates
+	// just like globals.
+	captured := make(map[string]string)
+	for i, c := range cfg.PreCmds {
+		if c.Capture == "" {
+			continue
+		}
+		if _, ok := cfg.Global[c.Capture]; ok {
+			problems = append(problems, ValidationError{Severity: SeverityWarning, Path: fmt.Sprintf("pre_commands[%d].capture", i), Message: fmt.Sprintf("overrides the global variable %q", c.Capture)})
+		}
+		captured[c.Capture] = ""
+	}
+	problems = append(problems, checkComputed(opts.Keys, "computed", cfg.Computed, cfg.Global, variables.WithCaptured(variables.Builtin, captured))...)
+	global := variables.WithCaptured(variables.Data(computedNames(cfg.Global, cfg.Computed), nil), captured)
+
+	var (
+		usedGlobals = make(map[string]bool)
+		allParsed   = true
+	)
+	markComputedRefs(cfg.Computed, usedGlobals)
+	targetOS := opts.TargetOS
+	if targetOS == "" {
+		targetOS = runtime.GOOS
+	}
+	for i, f := range cfg.Files {
+		if j := FindCollision(cfg.Files[:i], f, -1); j >= 0 {
+			msg := fmt.Sprintf("duplicates files[%d]", j)
+			if cfg.Files[j].Name != f.Name || cfg.Files[j].Path != f.Path {
+				msg = fmt.Sprintf("%s collides with files[%d]", f.Target(), j)
+			}
+			problems = append(problems, ValidationError{Severity: SeverityError, Path: fmt.Sprintf("files[%d]", i), Message: msg})
+		}
+		problems = append(problems, checkKeys(opts.Keys, fmt.Sprintf("files[%d].local", i), f.Local)...)
+		problems = append(problems, checkSecrets(fmt.Sprintf("files[%d].secrets", i), f.Secrets, f.Local)...)
+		problems = append(problems, checkChoices(fmt.Sprintf("files[%d].choices", i), f.Choices, f.Local)...)
+		problems = append(problems, checkComputed(opts.Keys, fmt.Sprintf("files[%d].computed", i), f.Computed, f.Local, global)...)
+		markComputedRefs(f.Computed, usedGlobals)
+		f.Local = computedNames(f.Local, f.Computed)
+		for _, name := range sortedKeys(variables.References(f.Local)) {
+			if _, ok := variables.Lookup(global, name); !ok {
+				problems = append(problems, ValidationError{Severity: SeverityError, Path: fmt.Sprintf("files[%d].local", i), Message: fmt.Sprintf("reference to undefined variable %q", name)})
+				continue
+			}
+			first, _, _ := strings.Cut(name, ".")
+			usedGlobals[first] = true
+		}
+		if !IsFormatter(f.Format) {
+			problems = append(problems, ValidationError{Severity: SeverityError, Path: fmt.Sprintf("files[%d].format", i), Message: fmt.Sprintf("unknown formatter %q, expected one of %s", f.Format, strings.Join(Formatters(), ", "))})
+		}
+		if f.SkipIf != "" {
+			problems = append(problems, checkCondition(fmt.Sprintf("files[%d].skip_if", i), f.SkipIf, global, f.Local)...)
+		}
+		problems = append(problems, checkCommands(fmt.Sprintf("files[%d].hooks", i), f.Hooks, variables.Data(global, f.Local), opts)...)
+		if opts.CheckPath != nil && f.Path != "" {
+			for _, p := range opts.CheckPath(filepath.FromSlash(f.Path), filepath.FromSlash(f.Name)) {
+				p.Path = fmt.Sprintf("files[%d].path", i)
+				problems = append(problems, p)
+			}
+		}
+		for _, p := range checkPathFor(targetOS, f.Path, false) {
+			p.Path = fmt.Sprintf("files[%d].path", i)
+			problems = append(problems, p)
+		}
+		for _, p := range checkPathFor(targetOS, f.Name, true) {
+			p.Path = fmt.Sprintf("files[%d].name", i)
+			problems = append(problems, p)
+		}
+		if opts.Templates != nil && f.Template != "" {
+			tfs, err := templates.Parse(opts.Templates, f.Template)
+			if err != nil {
+				problems = append(problems, ValidationError{Severity: SeverityError, Path: fmt.Sprintf("files[%d].template", i), Message: err.Error()})
+				allParsed = false
+				continue
+			}
+			hints := templates.Types(tfs)
+			referenced := make(map[string]bool, len(hints))
+			for k := range hints {
+				referenced[k] = true
+			}
+			markComputedRefs(f.Computed, referenced)
+			problems = append(problems, checkVariables(i, f, global, referenced, usedGlobals)...)
+			problems = append(problems, checkVariableTypes(i, f, cfg.Global, hints)...)
+		}
+	}
+	if opts.Templates != nil && allParsed {
+		for _, k := range sortedKeys(cfg.Global) {
+			if !usedGlobals[k] {
+				problems = append(problems, ValidationError{Severity: SeverityWarning, Path: "global." + k, Message: "not used by any template"})
+			}
+		}
+	}
+	problems = append(problems, checkCommands("pre_commands", cfg.PreCmds, global, opts)...)
+	problems = append(problems, checkCommands("commands", cfg.Cmds, global, opts)...)
+	if opts.Strict {
+		for i := range problems {
+			if problems[i].Severity == SeverityWarning {
+				problems[i].Severity = SeverityError
+			}
+		}
+	}
+	return problems
+}
+
+// checkKeys checks the names of vars, located at loc, and of the variables
+// nested in them.
+func checkKeys(keys KeyRules, loc string, vars map[string]any) ValidationErrors {
+	var problems ValidationErrors
+	for _, k := range sortedKeys(vars) {
+		if err := keys.Check(k); err != nil {
+			problems = append(problems, ValidationError{Severity: SeverityError, Path: loc + "." + k, Message: err.Error()})
+		}
+		if nested, ok := vars[k].(map[string]any); ok {
+			problems = append(problems, checkKeys(keys, loc+"."+k, nested)...)
+		}
+	}
+	return problems
+}
+
+// checkSecrets reports the secrets, located at loc, that name no variable
+// of vars or a variable that is not a string.
+func checkSecrets(loc string, secrets []string, vars map[string]any) ValidationErrors {
+	var problems ValidationErrors
+	for _, name := range secrets {
+		v, ok := variables.Lookup(vars, name)
+		switch {
+		case !ok:
+			problems = append(problems, ValidationError{Severity: SeverityWarning, Path: loc, Message: fmt.Sprintf("secret %q is not defined", name)})
+		case v != nil:
+			if _, ok := v.(string); !ok {
+				problems = append(problems, ValidationError{Severity: SeverityWarning, Path: loc, Message: fmt.Sprintf("secret %q is not a string", name)})
+			}
+		}
+	}
+	return problems
+}
+
+// checkChoices reports the variables of vars, located at loc, whose value,
+// or any element of it, is not one of their choices.
+func checkChoices(loc string, choices map[string][]string, vars map[string]any) ValidationErrors {
+	var problems ValidationErrors
+	for _, name := range sortedKeys(choices) {
+		v, ok := variables.Lookup(vars, name)
+		if !ok {
+			problems = append(problems, ValidationError{Severity: SeverityWarning, Path: loc + "." + name, Message: "choices declared for a variable that is not defined"})
+			continue
+		}
+		values, isList := v.([]any)
+		if !isList {
+			values = []any{v}
+		}
+		for _, e := range values {
+			if !variables.IsChoice(e, choices[name]) {
+				problems = append(problems, ValidationError{Severity: SeverityError, Path: loc + "." + name, Message: fmt.Sprintf("%s is not one of %s", variables.Display(e), strings.Join(choices[name], ", "))})
+			}
+		}
+	}
+	return problems
+}
+
+// checkComputed checks the computed variables, located at loc, which are
+// defined along with vars and may refer to them, to the variables of outer
+// and to each other.
+func checkComputed(keys KeyRules, loc string, computed map[string]string, vars, outer map[string]any) ValidationErrors {
+	var problems ValidationErrors
+	for _, name := range sortedKeys(computed) {
+		if err := keys.CheckName(name); err != nil {
+			problems = append(problems, ValidationError{Severity: SeverityError, Path: loc + "." + name, Message: err.Error()})
+		}
+		if _, ok := variables.Lookup(vars, name); ok {
+			problems = append(problems, ValidationError{Severity: SeverityError, Path: loc + "." + name, Message: "is defined as a variable as well"})
+		}
+		c, err := expr.Parse("expression", computed[name])
+		if err != nil {
+			problems = append(problems, ValidationError{Severity: SeverityError, Path: loc + "." + name, Message: err.Error()})
+			continue
+		}
+		for _, ref := range sortedKeys(c.Variables()) {
+			_, isComputed := computed[ref]
+			_, inOuter := variables.Lookup(outer, ref)
+			if _, ok := variables.Lookup(vars, ref); !ok && !inOuter && !isComputed {
+				problems = append(problems, ValidationError{Severity: SeverityWarning, Path: loc + "." + name, Message: fmt.Sprintf("variable %q is not defined", ref)})
+			}
+		}
+	}
+	if _, err := expr.Order(computed); err != nil && !problems.HasErrors() {
+		problems = append(problems, ValidationError{Severity: SeverityError, Path: loc, Message: err.Error()})
+	}
+	return problems
+}
+
+// computedNames returns a copy of vars in which the computed variables are
+// defined as null, for checks that only care about which variables exist.
+func computedNames(vars map[string]any, computed map[string]string) map[string]any {
+	if len(computed) == 0 {
+		return vars
+	}
+	result := make(map[string]any, len(vars)+len(computed))
+	for k, v := range vars {
+		result[k] = v
+	}
+	for name := range computed {
+		variables.Set(result, name, nil)
+	}
+	return result
+}
+
+// markComputedRefs records the variables the expressions of computed refer
+// to as used, as far as they are valid.
+func markComputedRefs(computed map[string]string, used map[string]bool) {
+	for _, e := range computed {
+		if c, err := expr.Parse("expression", e); err == nil {
+			for name := range c.Variables() {
+				first, _, _ := strings.Cut(name, ".")
+				used[first] = true
+			}
+		}
+	}
+}
+
+// checkCondition checks the syntax of a condition and whether the variables
+// it refers to are defined.
+func checkCondition(location, cond string, global, local map[string]any) ValidationErrors {
+	c, err := expr.Parse("condition", cond)
+	if err != nil {
+		return ValidationErrors{{Severity: SeverityError, Path: location, Message: err.Error()}}
+	}
+	var problems ValidationErrors
+	for _, name := range sortedKeys(c.Variables()) {
+		_, inLocal := variables.Lookup(local, name)
+		if _, inGlobal := variables.Lookup(global, name); !inLocal && !inGlobal {
+			problems = append(problems, ValidationError{Severity: SeverityWarning, Path: location, Message: fmt.Sprintf("variable %q is not defined", name)})
+		}
+	}
+	return problems
+}
+
+// checkCommands checks the commands of the section called section, vars
+// being the variables defined for their when conditions besides the
+// builtin and captured ones.
+func checkCommands(section string, cmds []Command, vars map[string]any, opts CheckOptions) ValidationErrors {
+	captured := make(map[string]string)
+	for _, c := range cmds {
+		if c.Capture != "" {
+			captured[c.Capture] = ""
+		}
+	}
+	vars = variables.ForCommands(vars, captured)
+
+	var problems ValidationErrors
+	if _, err := ScheduleCommands(cmds); err != nil {
+		problems = append(problems, ValidationError{Severity: SeverityError, Path: section, Message: err.Error()})
+	}
+	for i, c := range cmds {
+		// The name of a shell command is part of a script, which cannot be
+		// looked up.
+		if !c.Shell && opts.CheckCommand != nil {
+			for _, p := range opts.CheckCommand(c.Name) {
+				p.Path = fmt.Sprintf("%s[%d].name", section, i)
+				problems = append(problems, p)
+			}
+		}
+		for j, arg := range c.Args {
+			if err := checkArg(fmt.Sprintf("args[%d]", j), arg); err != nil {
+				problems = append(problems, ValidationError{Severity: SeverityError, Path: fmt.Sprintf("%s[%d].args[%d]", section, i, j), Message: err.Error()})
+			}
+		}
+		if err := checkArg("dir", c.Dir); err != nil {
+			problems = append(problems, ValidationError{Severity: SeverityError, Path: fmt.Sprintf("%s[%d].dir", section, i), Message: err.Error()})
+		}
+		if c.When != "" {
+			problems = append(problems, checkCondition(fmt.Sprintf("%s[%d].when", section, i), c.When, vars, nil)...)
+		}
+		if c.Capture != "" {
+			if err := opts.Keys.Check(c.Capture); err != nil {
+				problems = append(problems, ValidationError{Severity: SeverityError, Path: fmt.Sprintf("%s[%d].capture", section, i), Message: err.Error()})
+			}
+		}
+		for _, k := range sortedKeys(c.Env) {
+			if !variables.IsEnvName(k) {
+				problems = append(problems, ValidationError{Severity: SeverityError, Path: fmt.Sprintf("%s[%d].env.%s", section, i, k), Message: "not a valid environment variable name"})
+			}
+		}
+	}
+	return problems
+}
+
+// checkArg parses s, an argument or the working directory of a command, as
+// a template if it contains any action.
+func checkArg(name, s string) error {
+	if !strings.Contains(s, "{{") {
+		return nil
+	}
+	_, err := templates.New(name).Option("missingkey=error").Parse(s)
+	return err
+}
+
+// checkVariables compares the variables referenced by the template of the
+// i-th file with the ones provided for it, recording the globals in use.
+func checkVariables(i int, f File, global map[string]any, referenced, usedGlobals map[string]bool) ValidationErrors {
+	var problems ValidationErrors
+	for _, name := range sortedKeys(referenced) {
+		_, local := f.Local[name]
+		_, glob := global[name]
+		switch {
+		case local:
+		case glob:
+			usedGlobals[name] = true
+		default:
+			problems = append(problems, ValidationError{Severity: SeverityError, Path: fmt.Sprintf("files[%d].template", i), Message: fmt.Sprintf("variable %q is not provided", name)})
+		}
+	}
+	for _, k := range sortedKeys(f.Local) {
+		if !referenced[k] {
+			problems = append(problems, ValidationError{Severity: SeverityWarning, Path: fmt.Sprintf("files[%d].local.%s", i, k), Message: "not used by the template"})
+		}
+	}
+	return problems
+}
+
+// checkVariableTypes reports the variables provided to the template of the
+// i-th file, f, whose values do not fit the way the template uses them,
+// e.g. a string ranged over. Computed variables are not checked.
+func checkVariableTypes(i int, f File, global map[string]any, hints map[string]string) ValidationErrors {
+	var problems ValidationErrors
+	for _, name := range sortedKeys(hints) {
+		hint := hints[name]
+		if _, ok := f.Computed[name]; ok {
+			continue
+		}
+		loc := fmt.Sprintf("files[%d].local.%s", i, name)
+		v, ok := f.Local[name]
+		if !ok {
+			loc = "global." + name
+			if v, ok = global[name]; !ok {
+				continue
+			}
+		}
+		if !templates.Matches(v, hint) {
+			problems = append(problems, ValidationError{Severity: SeverityWarning, Path: loc, Message: fmt.Sprintf("used as a %s by the template of files[%d], but is a %s", hint, i, templates.TypeOf(v))})
+		}
+	}
+	return problems
+}
@@ -0,0 +1,241 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCheck(t *testing.T) {
+	templates := fstest.MapFS{
+		"main.tmpl":   {Data: []byte("package {{ .Pkg }} // {{ .Name }}")},
+		"list.tmpl":   {Data: []byte("{{ range .Items }}{{ . }}{{ end }}")},
+		"broken.tmpl": {Data: []byte("{{ .Name ")},
+	}
+	file := func(name, template string, local map[string]any) File {
+		return File{Name: name, Path: "out", Template: template, Local: local}
+	}
+	tests := []struct {
+		name string
+		cfg  Config
+		opts CheckOptions
+		want []string
+	}{
+		{
+			name: "empty",
+			want: []string{
+				"global: warning: no global variables defined",
+				"files: error: at least one file is required",
+			},
+		},
+		{
+			name: "empty files allowed",
+			cfg:  Config{Global: map[string]any{"Name": "demo"}},
+			opts: CheckOptions{AllowEmptyFiles: true},
+		},
+		{
+			name: "valid",
+			cfg: Config{
+				Global: map[string]any{"Name": "demo"},
+				Files:  []File{file("main.go", "main.tmpl", map[string]any{"Pkg": "main"})},
+			},
+			opts: CheckOptions{Templates: templates},
+		},
+		{
+			name: "structure",
+			cfg: Config{
+				Global: map[string]any{"Name": "demo"},
+				Files:  []File{{Name: "a"}},
+				Cmds:   []Command{{Timeout: "soon", Retries: -1, OnFailure: "ignore"}},
+			},
+			want: []string{
+				"files[0].path: error: must not be empty",
+				"files[0].template: error: must not be empty",
+				"commands[0].name: error: must not be empty",
+				"commands[0].timeout: error: invalid timeout: soon",
+				"commands[0].retries: error: must not be negative",
+				`commands[0].on_failure: error: unknown failure policy "ignore", expected abort, continue or warn`,
+			},
+		},
+		{
+			name: "variables",
+			cfg: Config{
+				Global:   map[string]any{"Name": "demo", "1st": 1, "db": map[string]any{"host-name": "x"}, "Env": "qa"},
+				Secrets:  []string{"Token", "Name", "db"},
+				Choices:  map[string][]string{"Env": {"dev", "prod"}, "Tier": {"a"}},
+				Computed: map[string]string{"Upper": "upper(Name)", "Bad": "Name +", "Name": `"x"`, "Ref": "Missing"},
+				Files:    []File{file("a", "t", map[string]any{"Link": "${Nope}"})},
+			},
+			want: []string{
+				`global.1st: error: variable name "1st" must not start with a digit`,
+				`global.db.host-name: error: variable name "host-name" must match ^[A-Za-z_][A-Za-z0-9_]*$`,
+				`secrets: warning: secret "Token" is not defined`,
+				`secrets: warning: secret "db" is not a string`,
+				"choices.Env: error: qa is not one of dev, prod",
+				"choices.Tier: warning: choices declared for a variable that is not defined",
+				`computed.Bad: error: expression "Name +": unexpected end of expression`,
+				"computed.Name: error: is defined as a variable as well",
+				`computed.Ref: warning: variable "Missing" is not defined`,
+				`files[0].local: error: reference to undefined variable "Nope"`,
+			},
+		},
+		{
+			name: "computed cycle",
+			cfg: Config{
+				Global:   map[string]any{"Name": "demo"},
+				Computed: map[string]string{"A": "B", "B": "A"},
+				Files:    []File{file("a", "t", nil)},
+			},
+			want: []string{"computed: error: computed variables refer to each other: A -> B -> A"},
+		},
+		{
+			name: "files",
+			cfg: Config{
+				Global: map[string]any{"Name": "demo"},
+				Files: []File{
+					file("a", "t", nil),
+					file("a", "t", nil),
+					{Name: "b", Path: "out/", Template: "t", Format: "prettier", SkipIf: "Debug =="},
+					{Name: "c", Path: `C:\out`, Template: "t", SkipIf: "Debug"},
+				},
+			},
+			opts: CheckOptions{TargetOS: "linux"},
+			want: []string{
+				"files[1]: error: duplicates files[0]",
+				`files[2].format: error: unknown formatter "prettier", expected one of none, auto, gofmt, goimports, json`,
+				`files[2].skip_if: error: condition "Debug ==": unexpected end of expression`,
+				`files[3].skip_if: warning: variable "Debug" is not defined`,
+				`files[3].path: error: C:\out is absolute on windows only, and would be a relative directory on linux`,
+				`files[3].path: warning: C:\out uses \ as a separator, which linux takes for part of a name; store it as C:/out`,
+			},
+		},
+		{
+			name: "collision",
+			cfg: Config{
+				Global: map[string]any{"Name": "demo"},
+				Files:  []File{file("a/b", "t", nil), {Name: "b", Path: "out/a", Template: "t"}},
+			},
+			want: []string{"files[1]: error: out/a/b collides with files[0]"},
+		},
+		{
+			name: "templates",
+			cfg: Config{
+				Global: map[string]any{"Name": "demo", "Unused": true, "Items": "abc"},
+				Files: []File{
+					file("main.go", "main.tmpl", map[string]any{"Extra": 1}),
+					file("list", "list.tmpl", nil),
+				},
+			},
+			opts: CheckOptions{Templates: templates},
+			want: []string{
+				`files[0].template: error: variable "Pkg" is not provided`,
+				"files[0].local.Extra: warning: not used by the template",
+				"global.Items: warning: used as a list by the template of files[1], but is a string",
+				"global.Unused: warning: not used by any template",
+			},
+		},
+		{
+			name: "broken template",
+			cfg: Config{
+				Global: map[string]any{"Name": "demo", "Unused": true},
+				Files:  []File{file("a", "broken.tmpl", nil), file("b", "missing.tmpl", nil)},
+			},
+			opts: CheckOptions{Templates: templates},
+			want: []string{
+				"files[0].template: error: template: broken.tmpl:1: unclosed action",
+				"files[1].template: error: open missing.tmpl: file does not exist",
+			},
+		},
+		{
+			name: "commands",
+			cfg: Config{
+				Global: map[string]any{"Name": "demo"},
+				Files:  []File{file("a", "t", nil)},
+				PreCmds: []Command{
+					{Name: "git", Capture: "Name"},
+					{Name: "date", Capture: "1x"},
+				},
+				Cmds: []Command{
+					{ID: "a", Name: "go", Args: []string{"{{ .Name "}, DependsOn: []string{"b"}},
+					{Name: "make", Dir: "{{ end }}", When: `os == "linux" && Arch == "amd64"`, Env: map[string]string{"GO-FLAGS": "x"}},
+				},
+			},
+			want: []string{
+				`pre_commands[0].capture: warning: overrides the global variable "Name"`,
+				`pre_commands[1].capture: error: variable name "1x" must not start with a digit`,
+				`commands: error: command 0 depends on unknown id "b"`,
+				"commands[0].args[0]: error: template: args[0]:1: unclosed action",
+				"commands[1].dir: error: template: dir:1: unexpected {{end}}",
+				`commands[1].when: warning: variable "Arch" is not defined`,
+				"commands[1].env.GO-FLAGS: error: not a valid environment variable name",
+			},
+		},
+		{
+			name: "probes",
+			cfg: Config{
+				Global: map[string]any{"Name": "demo"},
+				Files:  []File{file("a", "t", nil)},
+				Cmds:   []Command{{Name: "nope"}, {Name: "nope | cat", Shell: true}},
+			},
+			opts: CheckOptions{
+				CheckPath: func(dir, name string) ValidationErrors {
+					return ValidationErrors{{Severity: SeverityInfo, Message: dir + " " + name}}
+				},
+				CheckCommand: func(name string) ValidationErrors {
+					return ValidationErrors{{Severity: SeverityWarning, Message: name + " not found"}}
+				},
+			},
+			want: []string{
+				"files[0].path: info: out a",
+				"commands[0].name: warning: nope not found",
+			},
+		},
+		{
+			name: "strict",
+			cfg:  Config{Files: []File{file("a", "t", nil)}, Secrets: []string{"Token"}},
+			opts: CheckOptions{Strict: true},
+			want: []string{
+				"global: error: no global variables defined",
+				`secrets: error: secret "Token" is not defined`,
+			},
+		},
+		{
+			name: "key rules",
+			cfg: Config{
+				Global: map[string]any{"name": "demo", "Files": 1},
+				Files:  []File{file("a", "t", nil)},
+			},
+			opts: CheckOptions{Keys: mustKeyRules(`^[a-z]+$`, []string{"name"})},
+			want: []string{
+				"global.Files: error: variable name \"Files\" must match ^[a-z]+$",
+				`global.name: error: variable name "name" is reserved`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, p := range Check(tt.cfg, tt.opts) {
+				got = append(got, p.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got problems\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckHasErrors(t *testing.T) {
+	cfg := Config{
+		Global:  map[string]any{"Name": "demo"},
+		Files:   []File{{Name: "a", Path: "out", Template: "t"}},
+		Secrets: []string{"Token"},
+	}
+	problems := Check(cfg, CheckOptions{})
+	if len(problems) != 1 || problems.HasErrors() {
+		t.Fatalf("got %v, want a single warning", problems)
+	}
+	if problems = Check(cfg, CheckOptions{Strict: true}); !problems.HasErrors() {
+		t.Fatalf("got %v, want an error in strict mode", problems)
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ScheduleCommands returns the order in which cmds have to run: every command
+// comes after the ones it depends on and otherwise keeps its position.
+func ScheduleCommands(cmds []Command) ([]int, error) {
+	ids := make(map[string]int)
+	for i, c := range cmds {
+		if c.ID == "" {
+			continue
+		}
+		if j, ok := ids[c.ID]; ok {
+			return nil, fmt.Errorf("commands %d and %d share the id %q", j, i, c.ID)
+		}
+		ids[c.ID] = i
+	}
+
+	var (
+		pending    = make([]int, len(cmds))
+		dependents = make([][]int, len(cmds))
+	)
+	for i, c := range cmds {
+		for _, dep := range c.DependsOn {
+			j, ok := ids[dep]
+			if !ok {
+				return nil, fmt.Errorf("command %d depends on unknown id %q", i, dep)
+			}
+			pending[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	// Kahn's algorithm, always picking the first ready command in
+	// declaration order.
+	var (
+		order = make([]int, 0, len(cmds))
+		done  = make([]bool, len(cmds))
+	)
+	for len(order) < len(cmds) {
+		next := -1
+		for i := range cmds {
+			if !done[i] && pending[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			var stuck []string
+			for i := range cmds {
+				if !done[i] {
+					stuck = append(stuck, strconv.Itoa(i))
+				}
+			}
+			return nil, fmt.Errorf("dependency cycle between commands %s", strings.Join(stuck, ", "))
+		}
+		done[next] = true
+		order = append(order, next)
+		for _, d := range dependents[next] {
+			pending[d]--
+		}
+	}
+	return order, nil
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScheduleCommands(t *testing.T) {
+	cmd := func(id string, deps ...string) Command {
+		return Command{ID: id, Name: "true", DependsOn: deps}
+	}
+	tests := []struct {
+		name    string
+		cmds    []Command
+		want    []int
+		wantErr string
+	}{
+		{name: "none", want: []int{}},
+		{name: "declaration order", cmds: []Command{cmd(""), cmd("a"), cmd("")}, want: []int{0, 1, 2}},
+		{name: "dependency first", cmds: []Command{cmd("a", "b"), cmd("b")}, want: []int{1, 0}},
+		{
+			name: "stable",
+			cmds: []Command{cmd("test", "build"), cmd("lint"), cmd("build", "gen"), cmd("gen"), cmd("")},
+			want: []int{1, 3, 2, 0, 4},
+		},
+		{name: "diamond", cmds: []Command{cmd("d", "b", "c"), cmd("b", "a"), cmd("c", "a"), cmd("a")}, want: []int{3, 1, 2, 0}},
+		{name: "duplicate id", cmds: []Command{cmd("a"), cmd("b"), cmd("a")}, wantErr: `commands 0 and 2 share the id "a"`},
+		{name: "unknown id", cmds: []Command{cmd("a"), cmd("b", "c")}, wantErr: `command 1 depends on unknown id "c"`},
+		{name: "self", cmds: []Command{cmd("a", "a")}, wantErr: "dependency cycle between commands 0"},
+		{name: "cycle", cmds: []Command{cmd("x"), cmd("a", "c"), cmd("b", "a"), cmd("c", "b")}, wantErr: "dependency cycle between commands 1, 2, 3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ScheduleCommands(tt.cmds)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got order %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+// Package config defines the layout of gg-config files and reads and writes
+// them in the supported formats: JSON, JSON with comments, YAML, HCL and,
+// for reading only, TOML. Configs written in an older layout are migrated
+// to the current Version when they are read.
+//
+// Load and LoadFile read configs other programs are to act upon, rejecting
+// the ones that are not structurally sound, while ReadFile and Decode read
+// them as they are.
+package config
+
+import "sort"
+
+// Version is the version of the config layout written by this package.
+// Configs without a version field predate versioning and are version 0.
+const Version = 1
+
+// The policies for commands failing once their retries are exhausted, which
+// Command.OnFailure is one of. An empty policy means OnFailureAbort.
+const (
+	OnFailureAbort    = "abort"
+	OnFailureContinue = "continue"
+	OnFailureWarn     = "warn"
+)
+
+// IsFailurePolicy reports whether s is a valid Command.OnFailure.
+func IsFailurePolicy(s string) bool {
+	switch s {
+	case "", OnFailureAbort, OnFailureContinue, OnFailureWarn:
+		return true
+	default:
+		return false
+	}
+}
+
+// The formatters rendered files are post-processed with, which File.Format
+// is one of. An empty format means FormatterNone, and FormatterAuto picks
+// the formatter by the extension of the file.
+const (
+	FormatterNone      = "none"
+	FormatterAuto      = "auto"
+	FormatterGofmt     = "gofmt"
+	FormatterGoimports = "goimports"
+	FormatterJSON      = "json"
+)
+
+// Formatters returns the names of the formatters File.Format may be set to.
+func Formatters() []string {
+	return []string{FormatterNone, FormatterAuto, FormatterGofmt, FormatterGoimports, FormatterJSON}
+}
+
+// IsFormatter reports whether s is a valid File.Format.
+func IsFormatter(s string) bool {
+	switch s {
+	case "", FormatterNone, FormatterAuto, FormatterGofmt, FormatterGoimports, FormatterJSON:
+		return true
+	default:
+		return false
+	}
+}
+
+type (
+	// Config is the generator configuration written by the wizard and read
+	// by the render, validate and other subcommands.
+	Config struct {
+		Version int            `json:"version,omitempty" yaml:"version,omitempty" toml:"version,omitempty"`
+		Global  map[string]any `json:"global" yaml:"global" toml:"global"`
+		Files   []File         `json:"files,omitempty" yaml:"files,omitempty" toml:"files"`
+		PreCmds []Command      `json:"pre_commands,omitempty" yaml:"pre_commands,omitempty" toml:"pre_commands"`
+		Cmds    []Command      `json:"commands,omitempty" yaml:"commands,omitempty" toml:"commands"`
+		// Secrets names the global variables whose values are redacted
+		// in summaries and logs.
+		Secrets []string `json:"secrets,omitempty" yaml:"secrets,omitempty" toml:"secrets,omitempty"`
+		// Choices restricts global variables to some values.
+		Choices map[string][]string `json:"choices,omitempty" yaml:"choices,omitempty" toml:"choices,omitempty"`
+		// Computed maps the names of global variables to the expressions
+		// their values are computed from at render time.
+		Computed map[string]string `json:"computed,omitempty" yaml:"computed,omitempty" toml:"computed,omitempty"`
+		// Environments holds overlays of the globals by name, such as dev,
+		// staging and prod, one of which may be selected at render time.
+		Environments map[string]Environment `json:"environments,omitempty" yaml:"environments,omitempty" toml:"environments,omitempty"`
+	}
+	// Environment is an overlay of the globals of a config for a single
+	// deployment.
+	Environment struct {
+		// Global overrides the global variables of the same names and adds
+		// the others; nested maps are merged.
+		Global map[string]any `json:"global" yaml:"global" toml:"global"`
+	}
+	// File is a single file to be generated out of a template.
+	File struct {
+		Name     string         `json:"name" yaml:"name" toml:"name"`
+		Path     string         `json:"path" yaml:"path" toml:"path"`
+		Template string         `json:"template" yaml:"template" toml:"template"`
+		Local    map[string]any `json:"local,omitempty" yaml:"local,omitempty" toml:"local,omitempty"`
+		SkipIf   string         `json:"skip_if,omitempty" yaml:"skip_if,omitempty" toml:"skip_if,omitempty"`
+		Format   string         `json:"format,omitempty" yaml:"format,omitempty" toml:"format,omitempty"`
+		// Hooks run after the file has been written.
+		Hooks []Command `json:"hooks,omitempty" yaml:"hooks,omitempty" toml:"hooks,omitempty"`
+		// Secrets names the local variables whose values are redacted.
+		Secrets []string `json:"secrets,omitempty" yaml:"secrets,omitempty" toml:"secrets,omitempty"`
+		// Choices restricts local variables to some values.
+		Choices map[string][]string `json:"choices,omitempty" yaml:"choices,omitempty" toml:"choices,omitempty"`
+		// Computed holds the expressions of computed local variables.
+		Computed map[string]string `json:"computed,omitempty" yaml:"computed,omitempty" toml:"computed,omitempty"`
+	}
+	// Command is a command run before or after rendering, or after a file
+	// has been written.
+	Command struct {
+		ID   string            `json:"id,omitempty" yaml:"id,omitempty" toml:"id,omitempty"`
+		Name string            `json:"name" yaml:"name" toml:"name"`
+		Args []string          `json:"args" yaml:"args" toml:"args"`
+		Dir  string            `json:"dir,omitempty" yaml:"dir,omitempty" toml:"dir,omitempty"`
+		Env  map[string]string `json:"env,omitempty" yaml:"env,omitempty" toml:"env,omitempty"`
+		// Timeout is a duration such as 30s or 5m.
+		Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty" toml:"timeout,omitempty"`
+		Retries int    `json:"retries,omitempty" yaml:"retries,omitempty" toml:"retries,omitempty"`
+		// DependsOn lists the IDs of the commands of the same section that
+		// have to run first.
+		DependsOn []string `json:"depends_on,omitempty" yaml:"depends_on,omitempty" toml:"depends_on,omitempty"`
+		// Capture names the variable the standard output of the command is
+		// stored in, trailing newlines removed.
+		Capture string `json:"capture,omitempty" yaml:"capture,omitempty" toml:"capture,omitempty"`
+		// Shell runs the name and arguments, joined by spaces, as a shell
+		// script instead of executing name directly.
+		Shell bool `json:"shell,omitempty" yaml:"shell,omitempty" toml:"shell,omitempty"`
+		// When is a condition, such as os == "linux", the command only runs
+		// if it holds.
+		When string `json:"when,omitempty" yaml:"when,omitempty" toml:"when,omitempty"`
+		// OnFailure is abort, the default, continue or warn.
+		OnFailure string `json:"on_failure,omitempty" yaml:"on_failure,omitempty" toml:"on_failure,omitempty"`
+	}
+)
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,221 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+)
+
+// The formats configs are read and written in out of the box. TOML configs
+// can only be read, HCL ones only written. More can be added with
+// RegisterFormat.
+const (
+	FormatJSON  = "json"
+	FormatJSONC = "jsonc"
+	FormatYAML  = "yaml"
+	FormatHCL   = "hcl"
+	FormatTOML  = "toml"
+)
+
+// integerPattern matches the decimal integers NormalizeValue keeps as
+// json.Number when they do not fit into an int64.
+var integerPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)$`)
+
+// ReadFile reads a previously generated config from path. The input format
+// is detected from the file extension, falling back to sniffing the content.
+// The index of a config split into several files is read along with the
+// files it names.
+func ReadFile(path string) (Config, error) {
+	var cfg Config
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("load config: %w", err)
+	}
+	format := DetectFormat(path, data)
+	if cfg, err = Decode(data, format); errors.Is(err, ErrSplitIndex) {
+		// decodeRaw succeeded for Decode to recognize the index.
+		raw, _ := decodeRaw(data, format)
+		cfg, err = readSplit(path, raw)
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("load config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// DetectFormat returns the format of the config data read from path, by
+// the extension of path if a registered format claims it and by the content
+// otherwise.
+func DetectFormat(path string, data []byte) string {
+	if format, ok := formatByExtension(filepath.Ext(path)); ok {
+		return format
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		if !bytes.Equal(stripJSONComments(data), data) {
+			return FormatJSONC
+		}
+		return FormatJSON
+	case bytes.HasPrefix(trimmed, []byte("//")), bytes.HasPrefix(trimmed, []byte("/*")):
+		return FormatJSONC
+	}
+	var probe map[string]any
+	if _, err := toml.Decode(string(data), &probe); err == nil {
+		return FormatTOML
+	}
+	return FormatYAML
+}
+
+// Decode decodes data, written in format, into a Config, upgrading configs
+// written in an older layout to the current Version on the way.
+func Decode(data []byte, format string) (Config, error) {
+	var cfg Config
+
+	raw, err := decodeRaw(data, format)
+	if err != nil {
+		return cfg, err
+	}
+	if isSplitIndex(raw) {
+		return cfg, ErrSplitIndex
+	}
+	from, err := migrateRaw(raw)
+	if err != nil {
+		return cfg, err
+	}
+	if from != Version {
+		// The migrated document is mapped onto Config through JSON, which
+		// every decoder's output can be represented in.
+		if data, err = json.Marshal(raw); err != nil {
+			return cfg, err
+		}
+		err = unmarshalJSON(data, &cfg)
+	} else {
+		// decodeRaw succeeded, so the format is registered and readable.
+		c, _ := LookupFormat(format)
+		if err = c.Decode(data, &cfg); err == nil {
+			exactNumbers(&cfg, raw)
+		}
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	cfg.Global = NormalizeVars(cfg.Global)
+	for name, env := range cfg.Environments {
+		env.Global = NormalizeVars(env.Global)
+		cfg.Environments[name] = env
+	}
+	for i := range cfg.Files {
+		cfg.Files[i].Local = NormalizeVars(cfg.Files[i].Local)
+	}
+	return cfg, nil
+}
+
+// unmarshalJSON decodes data into v, keeping numbers as json.Number so that
+// NormalizeValue sees their exact text.
+func unmarshalJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// NormalizeVars converts the numeric types produced by the different decoders
+// into int64 and float64 values, so that variables have the same types
+// whatever format they were read from. Numbers whose text would not survive
+// the conversion, such as integers out of the range of int64 or 1.50, are
+// kept as json.Number. vars is modified in place and returned.
+func NormalizeVars(vars map[string]any) map[string]any {
+	for k, v := range vars {
+		vars[k] = NormalizeValue(v)
+	}
+	return vars
+}
+
+// NormalizeValue is NormalizeVars for a single value.
+func NormalizeValue(v any) any {
+	switch val := v.(type) {
+	case int:
+		return int64(val)
+	case int32:
+		return int64(val)
+	case uint64:
+		if val > math.MaxInt64 {
+			return json.Number(strconv.FormatUint(val, 10))
+		}
+		return int64(val)
+	case float32:
+		return float64(val)
+	case json.Number:
+		if integerPattern.MatchString(val.String()) {
+			if n, err := val.Int64(); err == nil {
+				return n
+			}
+			return val
+		}
+		if f, err := val.Float64(); err == nil && formatFloat(f) == val.String() {
+			return f
+		}
+		return val
+	case []any:
+		for i := range val {
+			val[i] = NormalizeValue(val[i])
+		}
+		return val
+	case map[string]any:
+		return NormalizeVars(val)
+	default:
+		return v
+	}
+}
+
+// stripJSONComments removes line and block comments from JSONC input, leaving
+// string literals untouched.
+func stripJSONComments(data []byte) []byte {
+	var (
+		out      = make([]byte, 0, len(data))
+		inString bool
+	)
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	want := Config{
+		Version: Version,
+		Global:  map[string]any{"Name": "demo", "Port": int64(8080), "Debug": true, "Ratio": 0.5, "Tags": []any{"a", int64(1)}},
+		Files:   []File{{Name: "main.go", Path: "cmd", Template: "main.tmpl", Local: map[string]any{"Pkg": "main"}}},
+		Cmds:    []Command{{Name: "go", Args: []string{"build"}}},
+	}
+	tests := []struct {
+		format string
+		data   string
+	}{
+		{FormatJSON, `{
+			"version": 1,
+			"global": {"Name": "demo", "Port": 8080, "Debug": true, "Ratio": 0.5, "Tags": ["a", 1]},
+			"files": [{"name": "main.go", "path": "cmd", "template": "main.tmpl", "local": {"Pkg": "main"}}],
+			"commands": [{"name": "go", "args": ["build"]}]
+		}`},
+		{FormatJSONC, `{
+			// The current layout.
+			"version": 1,
+			"global": {"Name": "demo", "Port": 8080, "Debug": true, "Ratio": 0.5, "Tags": ["a", 1]}, /* globals */
+			"files": [{"name": "main.go", "path": "cmd", "template": "main.tmpl", "local": {"Pkg": "main"}}],
+			"commands": [{"name": "go", "args": ["build"]}]
+		}`},
+		{FormatYAML, `
+version: 1
+global:
+  Name: demo
+  Port: 8080
+  Debug: true
+  Ratio: 0.5
+  Tags: [a, 1]
+files:
+  - name: main.go
+    path: cmd
+    template: main.tmpl
+    local:
+      Pkg: main
+commands:
+  - name: go
+    args: [build]
+`},
+		{FormatTOML, `
+version = 1
+
+[global]
+Name = "demo"
+Port = 8080
+Debug = true
+Ratio = 0.5
+Tags = ["a", 1]
+
+[[files]]
+name = "main.go"
+path = "cmd"
+template = "main.tmpl"
+local = { Pkg = "main" }
+
+[[commands]]
+name = "go"
+args = ["build"]
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := Decode([]byte(tt.data), tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got\n%#v\nwant\n%#v", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeMigrates(t *testing.T) {
+	// Configs predating the version field are version 0.
+	for _, format := range []string{FormatJSON, FormatYAML} {
+		data := `{"global": {"Name": "demo", "Big": 12345678901234567890}, "files": [{"name": "a", "path": ".", "template": "t", "local": {"N": 1.50}}]}`
+		got, err := Decode([]byte(data), format)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		want := Config{
+			Version: Version,
+			Global:  map[string]any{"Name": "demo", "Big": got.Global["Big"]},
+			Files:   []File{{Name: "a", Path: ".", Template: "t", Local: map[string]any{"N": got.Files[0].Local["N"]}}},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %#v, want %#v", format, got, want)
+		}
+		if s := valueText(got.Global["Big"]); s != "12345678901234567890" {
+			t.Errorf("%s: got Big %s", format, s)
+		}
+	}
+}
+
+// valueText formats a decoded number the way it was written.
+func valueText(v any) string {
+	var b strings.Builder
+	if err := EncodeValue(&b, v, EncodeOptions{Format: FormatJSON, Compact: true}); err != nil {
+		return err.Error()
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name, format, data string
+		wantErr            string
+	}{
+		{name: "newer", format: FormatJSON, data: `{"version": 2, "global": {}}`, wantErr: "config version 2 is newer than the supported version 1"},
+		{name: "negative", format: FormatJSON, data: `{"version": -1}`, wantErr: "invalid config version: -1"},
+		{name: "not a number", format: FormatYAML, data: "version: one\n", wantErr: "invalid config version: one"},
+		{name: "fraction", format: FormatJSON, data: `{"version": 1.5}`, wantErr: "invalid config version: 1.5"},
+		{name: "write only", format: FormatHCL, data: `version = 1`, wantErr: "reading hcl configs is not supported"},
+		{name: "unknown", format: "ini", data: `version = 1`, wantErr: "reading ini configs is not supported"},
+		{name: "syntax", format: FormatJSON, data: `{"global": `, wantErr: "unexpected EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode([]byte(tt.data), tt.format)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+	if _, err := Decode([]byte(`{"global": "global.json", "files": "files.json"}`), FormatJSON); !errors.Is(err, ErrSplitIndex) {
+		t.Errorf("got error %v for a split index, want ErrSplitIndex", err)
+	}
+}
+
+func TestDetectVersion(t *testing.T) {
+	tests := []struct {
+		format, data string
+		want         int
+	}{
+		{FormatJSON, `{"global": {}}`, 0},
+		{FormatJSON, `{"version": null}`, 0},
+		{FormatJSON, `{"version": 1}`, 1},
+		{FormatYAML, "version: 1\nglobal: {}\n", 1},
+		{FormatTOML, "version = 0\n", 0},
+	}
+	for _, tt := range tests {
+		got, err := DetectVersion([]byte(tt.data), tt.format)
+		if err != nil {
+			t.Errorf("DetectVersion(%q, %s): %v", tt.data, tt.format, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("DetectVersion(%q, %s) = %d, want %d", tt.data, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		path, data string
+		want       string
+	}{
+		{"config.json", "global: {}", FormatJSON},
+		{"config.YML", "{}", FormatYAML},
+		{"config.jsonc", "{}", FormatJSONC},
+		{"config.toml", "{}", FormatTOML},
+		{"config", `{"global": {}}`, FormatJSON},
+		{"config", "  {\n  // comment\n}", FormatJSONC},
+		{"config", `{"url": "http://example.com"}`, FormatJSON},
+		{"config", "/* header */ {}", FormatJSONC},
+		{"config", "version = 1\n[global]\nName = \"demo\"\n", FormatTOML},
+		{"config", "version: 1\nglobal:\n  Name: demo\n", FormatYAML},
+	}
+	for _, tt := range tests {
+		if got := DetectFormat(tt.path, []byte(tt.data)); got != tt.want {
+			t.Errorf("DetectFormat(%q, %q) = %s, want %s", tt.path, tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestStripJSONComments(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`{"a": 1} // trailing`, `{"a": 1} `},
+		{"// first\n{}", "\n{}"},
+		{`{"url": "http://x/*y*/"}`, `{"url": "http://x/*y*/"}`},
+		{`{"q": "say \"//hi\""}`, `{"q": "say \"//hi\""}`},
+		{`{/* a */"b": /* c */1}`, `{"b": 1}`},
+		{`{} /* unterminated`, `{} `},
+	}
+	for _, tt := range tests {
+		if got := string(stripJSONComments([]byte(tt.in))); got != tt.want {
+			t.Errorf("stripJSONComments(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestReadFileSplit(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"config.json": `{"version": 1, "global": "global.json", "files": "files.yaml"}`,
+		"global.json": `{"Name": "demo"}`,
+		"files.yaml":  "- name: a\n  path: out\n  template: t\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(dir, "config.json")
+	if !IsSplitIndex(path) {
+		t.Fatal("IsSplitIndex reports false")
+	}
+	got, err := ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Config{
+		Version: Version,
+		Global:  map[string]any{"Name": "demo"},
+		Files:   []File{{Name: "a", Path: "out", Template: "t"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// ChangeKind tells what happened to an item between two configs.
+type ChangeKind string
+
+// Kinds of changes reported by Diff.
+const (
+	Added   ChangeKind = "added"
+	Removed ChangeKind = "removed"
+	Changed ChangeKind = "changed"
+)
+
+// Change is a global variable, file or command added, removed or changed
+// between two configs.
+type Change struct {
+	Kind ChangeKind `json:"kind"`
+	// Path locates the item, e.g. global.Name, files[cmd/main.go] or
+	// commands[0]. Files are identified by their path and name, commands by
+	// their position.
+	Path string `json:"path"`
+	// Fields lists the fields of a changed file or command that differ,
+	// by their names in a config file.
+	Fields []string `json:"fields,omitempty"`
+	// Old is the item in the first config, nil if it was added.
+	Old any `json:"old,omitempty"`
+	// New is the item in the second config, nil if it was removed.
+	New any `json:"new,omitempty"`
+}
+
+// ChangeSet is the difference between two configs.
+type ChangeSet struct {
+	// Globals holds the changes to global variables, along with their
+	// choices and computed expressions, and the variables becoming or no
+	// longer being secrets, located at secrets.<name>.
+	Globals []Change `json:"globals"`
+	// Files holds the changes to file entries, in the order of the second
+	// config, removed files last.
+	Files []Change `json:"files"`
+	// Commands holds the changes to pre-commands and commands.
+	Commands []Change `json:"commands"`
+}
+
+// Empty reports whether the configs compared were the same.
+func (s ChangeSet) Empty() bool {
+	return len(s.Globals) == 0 && len(s.Files) == 0 && len(s.Commands) == 0
+}
+
+// Diff returns the changes turning a into b.
+func Diff(a, b Config) ChangeSet {
+	s := ChangeSet{Globals: []Change{}, Files: []Change{}, Commands: []Change{}}
+	s.Globals = diffMap(s.Globals, "global", a.Global, b.Global)
+	s.Globals = diffMap(s.Globals, "choices", a.Choices, b.Choices)
+	s.Globals = diffMap(s.Globals, "computed", a.Computed, b.Computed)
+	s.Globals = diffSet(s.Globals, "secrets", a.Secrets, b.Secrets)
+	envs := make(map[string]bool)
+	for name := range a.Environments {
+		envs[name] = true
+	}
+	for name := range b.Environments {
+		envs[name] = true
+	}
+	for _, name := range sortedKeys(envs) {
+		s.Globals = diffMap(s.Globals, "environments."+name+".global", a.Environments[name].Global, b.Environments[name].Global)
+	}
+	s.Files = diffFiles(a.Files, b.Files)
+	s.Commands = diffCommands(s.Commands, "pre_commands", a.PreCmds, b.PreCmds)
+	s.Commands = diffCommands(s.Commands, "commands", a.Cmds, b.Cmds)
+	return s
+}
+
+// diffMap appends the changes between the maps a and b, located at loc, to
+// changes.
+func diffMap[V any](changes []Change, loc string, a, b map[string]V) []Change {
+	for _, k := range sortedKeys(a) {
+		old := a[k]
+		v, ok := b[k]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Kind: Removed, Path: loc + "." + k, Old: old})
+		case !reflect.DeepEqual(old, v):
+			changes = append(changes, Change{Kind: Changed, Path: loc + "." + k, Old: old, New: v})
+		}
+	}
+	for _, k := range sortedKeys(b) {
+		if _, ok := a[k]; !ok {
+			changes = append(changes, Change{Kind: Added, Path: loc + "." + k, New: b[k]})
+		}
+	}
+	return changes
+}
+
+// diffSet appends the names of a missing from b, and of b missing from a,
+// located at loc, to changes.
+func diffSet(changes []Change, loc string, a, b []string) []Change {
+	in := func(names []string, name string) bool {
+		for _, n := range names {
+			if n == name {
+				return true
+			}
+		}
+		return false
+	}
+	for _, name := range a {
+		if !in(b, name) {
+			changes = append(changes, Change{Kind: Removed, Path: loc + "." + name})
+		}
+	}
+	for _, name := range b {
+		if !in(a, name) {
+			changes = append(changes, Change{Kind: Added, Path: loc + "." + name})
+		}
+	}
+	return changes
+}
+
+// fileKey identifies a file entry by the file it generates.
+func fileKey(f File) string {
+	return path.Clean(path.Join(f.Path, f.Name))
+}
+
+func diffFiles(a, b []File) []Change {
+	changes := []Change{}
+	old := make(map[string]File, len(a))
+	for _, f := range a {
+		old[fileKey(f)] = f
+	}
+	seen := make(map[string]bool, len(b))
+	for _, f := range b {
+		key := fileKey(f)
+		seen[key] = true
+		loc := fmt.Sprintf("files[%s]", key)
+		prev, ok := old[key]
+		if !ok {
+			changes = append(changes, Change{Kind: Added, Path: loc, New: f})
+			continue
+		}
+		if fields := changedFields(prev, f); len(fields) != 0 {
+			changes = append(changes, Change{Kind: Changed, Path: loc, Fields: fields, Old: prev, New: f})
+		}
+	}
+	for _, f := range a {
+		if key := fileKey(f); !seen[key] {
+			seen[key] = true
+			changes = append(changes, Change{Kind: Removed, Path: fmt.Sprintf("files[%s]", key), Old: f})
+		}
+	}
+	return changes
+}
+
+func diffCommands(changes []Change, section string, a, b []Command) []Change {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		loc := fmt.Sprintf("%s[%d]", section, i)
+		switch {
+		case i >= len(b):
+			changes = append(changes, Change{Kind: Removed, Path: loc, Old: a[i]})
+		case i >= len(a):
+			changes = append(changes, Change{Kind: Added, Path: loc, New: b[i]})
+		default:
+			if fields := changedFields(a[i], b[i]); len(fields) != 0 {
+				changes = append(changes, Change{Kind: Changed, Path: loc, Fields: fields, Old: a[i], New: b[i]})
+			}
+		}
+	}
+	return changes
+}
+
+// changedFields returns the names, as in a config file, of the fields of
+// the structs a and b that differ.
+func changedFields(a, b any) []string {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	var fields []string
+	for i := 0; i < va.NumField(); i++ {
+		if !equalValues(va.Field(i), vb.Field(i)) {
+			name, _, _ := strings.Cut(va.Type().Field(i).Tag.Get("json"), ",")
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// equalValues is reflect.DeepEqual, except that nil and empty maps and
+// slices are equal, as they are in config files.
+func equalValues(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Map, reflect.Slice:
+		if a.Len() == 0 && b.Len() == 0 {
+			return true
+		}
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
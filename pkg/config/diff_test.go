@@ -0,0 +1,85 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a := Config{
+		Global:       map[string]any{"Name": "demo", "Port": int64(80), "Old": true},
+		Secrets:      []string{"Token"},
+		Choices:      map[string][]string{"Env": {"dev", "prod"}},
+		Environments: map[string]Environment{"prod": {Global: map[string]any{"Port": int64(443)}}},
+		Files: []File{
+			{Name: "main.go", Path: "cmd", Template: "main.tmpl"},
+			{Name: "go.mod", Path: ".", Template: "mod.tmpl", Local: map[string]any{}},
+			{Name: "old.txt", Path: "out", Template: "old.tmpl"},
+		},
+		PreCmds: []Command{{Name: "git", Args: []string{"pull"}}},
+		Cmds:    []Command{{Name: "go", Args: []string{"build"}}, {Name: "go", Args: []string{"test"}}},
+	}
+	b := Config{
+		Global:       map[string]any{"Name": "demo", "Port": int64(8080), "New": "x"},
+		Secrets:      []string{"Password"},
+		Choices:      map[string][]string{"Env": {"dev", "prod"}},
+		Computed:     map[string]string{"Upper": "upper(Name)"},
+		Environments: map[string]Environment{"dev": {Global: map[string]any{"Port": int64(3000)}}},
+		Files: []File{
+			{Name: "new.txt", Path: "out", Template: "new.tmpl"},
+			{Name: "go.mod", Path: ".", Template: "mod.tmpl"},
+			{Name: "main.go", Path: "cmd", Template: "cli.tmpl", Format: FormatterGofmt},
+		},
+		PreCmds: []Command{{Name: "git", Args: []string{"pull"}}},
+		Cmds:    []Command{{Name: "go", Args: []string{"build", "./..."}, Retries: 1}},
+	}
+	want := ChangeSet{
+		Globals: []Change{
+			{Kind: Removed, Path: "global.Old", Old: true},
+			{Kind: Changed, Path: "global.Port", Old: int64(80), New: int64(8080)},
+			{Kind: Added, Path: "global.New", New: "x"},
+			{Kind: Added, Path: "computed.Upper", New: "upper(Name)"},
+			{Kind: Removed, Path: "secrets.Token"},
+			{Kind: Added, Path: "secrets.Password"},
+			{Kind: Added, Path: "environments.dev.global.Port", New: int64(3000)},
+			{Kind: Removed, Path: "environments.prod.global.Port", Old: int64(443)},
+		},
+		Files: []Change{
+			{Kind: Added, Path: "files[out/new.txt]", New: b.Files[0]},
+			{Kind: Changed, Path: "files[cmd/main.go]", Fields: []string{"template", "format"}, Old: a.Files[0], New: b.Files[2]},
+			{Kind: Removed, Path: "files[out/old.txt]", Old: a.Files[2]},
+		},
+		Commands: []Change{
+			{Kind: Changed, Path: "commands[0]", Fields: []string{"args", "retries"}, Old: a.Cmds[0], New: b.Cmds[0]},
+			{Kind: Removed, Path: "commands[1]", Old: a.Cmds[1]},
+		},
+	}
+	got := Diff(a, b)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got\n%+v\nwant\n%+v", got, want)
+	}
+	if got.Empty() {
+		t.Error("Empty reports no changes")
+	}
+}
+
+func TestDiffSame(t *testing.T) {
+	cfg := Config{
+		Global: map[string]any{"Name": "demo", "db": map[string]any{"port": int64(5432)}},
+		Files:  []File{{Name: "a", Path: "out", Template: "t", Local: map[string]any{}}},
+		Cmds:   []Command{{Name: "go", Args: []string{}}},
+	}
+	same := Config{
+		Global: map[string]any{"Name": "demo", "db": map[string]any{"port": int64(5432)}},
+		Files:  []File{{Name: "a", Path: "out", Template: "t"}},
+		Cmds:   []Command{{Name: "go"}},
+	}
+	got := Diff(cfg, same)
+	if !got.Empty() {
+		t.Errorf("got changes %+v, want none", got)
+	}
+	// Empty sections are lists rather than null when written as JSON.
+	if got.Globals == nil || got.Files == nil || got.Commands == nil {
+		t.Errorf("got nil sections in %+v", got)
+	}
+}
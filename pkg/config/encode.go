@@ -0,0 +1,60 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// DefaultIndent is the indentation width of JSON, JSONC and YAML output.
+const DefaultIndent = 2
+
+// EncodeOptions controls how a config is written.
+type EncodeOptions struct {
+	// Format is the name of a registered format, such as FormatJSON.
+	Format string
+	// Compact writes JSON output on a single line.
+	Compact bool
+	// Indent is the indentation width of JSON, JSONC and YAML output.
+	Indent int
+}
+
+// CanEncode reports whether configs can be written in format.
+func CanEncode(format string) bool {
+	c, ok := LookupFormat(format)
+	return ok && c.Encode != nil
+}
+
+// Encode writes cfg to w in the requested format. Variable maps are always
+// emitted with their keys in sorted order, so encoding the same Config twice
+// produces byte-identical output regardless of map iteration order. The
+// written config is always stamped with the current Version.
+func Encode(w io.Writer, cfg Config, opts EncodeOptions) error {
+	cfg.Version = Version
+	return EncodeValue(w, cfg, opts)
+}
+
+// EncodeValue writes an arbitrary value, such as a section of a config, in
+// a registered format. Some formats, such as HCL, only support whole
+// configs.
+func EncodeValue(w io.Writer, v any, opts EncodeOptions) error {
+	c, ok := LookupFormat(opts.Format)
+	if !ok || c.Encode == nil {
+		return fmt.Errorf("unsupported output format: %s", opts.Format)
+	}
+	// Floats are written so that they are read back as floats, whole ones
+	// included.
+	v = writtenValue(v, func(s string) any { return json.Number(s) })
+	return c.Encode(w, v, opts)
+}
+
+// WriteFile encodes cfg into the file at path, which is replaced only once
+// cfg was encoded completely, see WriteFileAtomic.
+func WriteFile(path string, cfg Config, opts EncodeOptions) error {
+	return WriteFileAtomic(path, 0o644, func(w io.Writer) error {
+		if err := Encode(w, cfg, opts); err != nil {
+			return fmt.Errorf("produce output: %w", err)
+		}
+		return nil
+	})
+}
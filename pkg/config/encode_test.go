@@ -0,0 +1,33 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEncodeEmptyLocal(t *testing.T) {
+	for _, local := range []map[string]any{nil, {}} {
+		cfg := Config{Files: []File{{Name: "main.go", Path: "cmd", Template: "main.tmpl", Local: local}}}
+		for _, format := range EncodeFormats() {
+			var b bytes.Buffer
+			if err := Encode(&b, cfg, EncodeOptions{Format: format}); err != nil {
+				t.Fatalf("%s: %v", format, err)
+			}
+			if strings.Contains(b.String(), "local") {
+				t.Errorf("%s with local %#v wrote an empty local:\n%s", format, local, b.String())
+			}
+			if format == FormatHCL {
+				// HCL configs are only written.
+				continue
+			}
+			got, err := Decode(b.Bytes(), format)
+			if err != nil {
+				t.Fatalf("%s: %v", format, err)
+			}
+			if len(got.Files) != 1 || len(got.Files[0].Local) != 0 {
+				t.Errorf("%s read back files %#v", format, got.Files)
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omerkaya1/gg-config/internal/variables"
+)
+
+// WithEnvironment returns cfg with the globals of its environment called
+// name laid over its own.
+func WithEnvironment(cfg Config, name string) (Config, error) {
+	env, ok := cfg.Environments[name]
+	if !ok {
+		if len(cfg.Environments) == 0 {
+			return cfg, fmt.Errorf("unknown environment %q: the config defines none", name)
+		}
+		return cfg, fmt.Errorf("unknown environment %q, expected one of %s", name, strings.Join(sortedKeys(cfg.Environments), ", "))
+	}
+	cfg.Global = overlayVars(cfg.Global, env.Global)
+	return cfg, nil
+}
+
+// overlayVars returns the variables of base with the ones of overlay laid
+// over them: nested maps present in both are merged, and any other value of
+// overlay replaces the one of base. Neither map is changed.
+func overlayVars(base, overlay map[string]any) map[string]any {
+	out := make(map[string]any, len(base)+len(overlay))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range overlay {
+		if inner, ok := v.(map[string]any); ok {
+			if current, ok := out[k].(map[string]any); ok {
+				out[k] = overlayVars(current, inner)
+				continue
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// checkEnvironments verifies the environments of cfg: their names, the keys
+// of their globals, that they leave computed variables alone and that they
+// respect the choices of the variables they override.
+func checkEnvironments(cfg Config, keys KeyRules) ValidationErrors {
+	var problems ValidationErrors
+	for _, name := range sortedKeys(cfg.Environments) {
+		var (
+			loc  = "environments." + name
+			vars = cfg.Environments[name].Global
+		)
+		if name == "" || strings.ContainsAny(name, ". \t") {
+			problems = append(problems, ValidationError{Severity: SeverityError, Path: loc, Message: "environment names must be neither empty nor contain dots or spaces"})
+		}
+		problems = append(problems, checkKeys(keys, loc+".global", vars)...)
+		for _, k := range sortedKeys(cfg.Computed) {
+			if _, ok := variables.Lookup(vars, k); ok {
+				problems = append(problems, ValidationError{Severity: SeverityError, Path: loc + ".global." + k, Message: "overrides a computed variable, whose value comes from its expression"})
+			}
+		}
+		choices := make(map[string][]string)
+		for k, values := range cfg.Choices {
+			if _, ok := variables.Lookup(vars, k); ok {
+				choices[k] = values
+			}
+		}
+		problems = append(problems, checkChoices(loc+".global", choices, vars)...)
+	}
+	return problems
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Codec reads and writes configs in a format. Either function may be nil
+// for formats that can only be read or only be written.
+type Codec struct {
+	// Encode writes v, a Config or a section of one, to w. Config carries
+	// json, yaml and toml struct tags for codecs built on encoders
+	// honouring them.
+	Encode func(w io.Writer, v any, opts EncodeOptions) error
+	// Decode decodes data into v, a *Config or a *map[string]any.
+	Decode func(data []byte, v any) error
+	// Extensions are the file extensions, such as ".yml", configs in the
+	// format are detected by.
+	Extensions []string
+}
+
+var (
+	formatsMu sync.RWMutex
+	formats   = make(map[string]Codec)
+	// extensions maps file extensions to the formats they stand for.
+	extensions = make(map[string]string)
+)
+
+func init() {
+	RegisterFormat(FormatJSON, Codec{Encode: encodeJSON, Decode: unmarshalJSON, Extensions: []string{".json"}})
+	RegisterFormat(FormatJSONC, Codec{
+		Encode: func(w io.Writer, v any, opts EncodeOptions) error {
+			if cfg, ok := v.(Config); ok {
+				return encodeJSONC(w, opts.Indent, cfg)
+			}
+			return encodeJSON(w, v, opts)
+		},
+		Decode: func(data []byte, v any) error {
+			return unmarshalJSON(stripJSONComments(data), v)
+		},
+		Extensions: []string{".jsonc", ".json5"},
+	})
+	RegisterFormat(FormatYAML, Codec{Encode: encodeYAML, Decode: decodeYAML, Extensions: []string{".yaml", ".yml"}})
+	RegisterFormat(FormatTOML, Codec{
+		Decode: func(data []byte, v any) error {
+			_, err := toml.Decode(string(data), v)
+			return err
+		},
+		Extensions: []string{".toml"},
+	})
+	RegisterFormat(FormatHCL, Codec{
+		Encode: func(w io.Writer, v any, _ EncodeOptions) error {
+			cfg, ok := v.(Config)
+			if !ok {
+				return fmt.Errorf("only whole configs can be written in %s", FormatHCL)
+			}
+			return encodeHCL(w, cfg)
+		},
+		Extensions: []string{".hcl"},
+	})
+}
+
+// RegisterFormat makes the format called name available to Encode, Decode
+// and DetectFormat, replacing the codec previously registered under name,
+// if any. Extensions claimed by several formats stand for the one
+// registered last.
+func RegisterFormat(name string, c Codec) {
+	if name == "" {
+		panic("config: RegisterFormat with an empty name")
+	}
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	formats[name] = c
+	for _, ext := range c.Extensions {
+		extensions[strings.ToLower(ext)] = name
+	}
+}
+
+// LookupFormat returns the codec registered under name.
+func LookupFormat(name string) (Codec, bool) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	c, ok := formats[name]
+	return c, ok
+}
+
+// Formats returns the names of the registered formats, sorted.
+func Formats() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	return sortedKeys(formats)
+}
+
+// EncodeFormats returns the names of the formats configs can be written
+// in, sorted.
+func EncodeFormats() []string {
+	var names []string
+	for _, name := range Formats() {
+		if CanEncode(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// formatByExtension returns the format registered for the extension ext.
+func formatByExtension(ext string) (string, bool) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	name, ok := extensions[strings.ToLower(ext)]
+	return name, ok
+}
+
+func encodeJSON(w io.Writer, v any, opts EncodeOptions) error {
+	enc := json.NewEncoder(w)
+	if !opts.Compact {
+		enc.SetIndent("", strings.Repeat(" ", opts.Indent))
+	}
+	return enc.Encode(v)
+}
+
+func encodeYAML(w io.Writer, v any, opts EncodeOptions) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(opts.Indent)
+	v = writtenValue(v, func(s string) any { return yamlNumber(s) })
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,292 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const hclIndent = "  "
+
+// encodeHCL writes cfg as a set of HCL blocks: a single global block, one
+// environment block per environment, holding its global block, one file
+// block per template, holding a hook block per file hook, and one
+// pre_command or command block per command.
+func encodeHCL(w io.Writer, cfg Config) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "version = %d\n", cfg.Version)
+	if len(cfg.Secrets) != 0 {
+		fmt.Fprintf(bw, "secrets = %s\n", hclList(cfg.Secrets))
+	}
+	fmt.Fprintln(bw)
+	if err := writeHCLBlock(bw, "global", cfg.Global, 0); err != nil {
+		return fmt.Errorf("global: %w", err)
+	}
+	if len(cfg.Computed) != 0 {
+		fmt.Fprintln(bw)
+		if err := writeHCLBlock(bw, "computed", hclExpressions(cfg.Computed), 0); err != nil {
+			return fmt.Errorf("computed: %w", err)
+		}
+	}
+	if len(cfg.Choices) != 0 {
+		fmt.Fprintln(bw)
+		if err := writeHCLBlock(bw, "choices", hclChoices(cfg.Choices), 0); err != nil {
+			return fmt.Errorf("choices: %w", err)
+		}
+	}
+	for _, name := range sortedKeys(cfg.Environments) {
+		fmt.Fprintln(bw)
+		fmt.Fprintf(bw, "environment %s {\n", quoteHCL(name))
+		if err := writeHCLBlock(bw, "global", cfg.Environments[name].Global, 1); err != nil {
+			return fmt.Errorf("environment %s: %w", name, err)
+		}
+		fmt.Fprintln(bw, "}")
+	}
+	for i, f := range cfg.Files {
+		fmt.Fprintln(bw)
+		fmt.Fprintf(bw, "file %s {\n", quoteHCL(f.Name))
+		fmt.Fprintf(bw, "%spath     = %s\n", hclIndent, quoteHCL(f.Path))
+		fmt.Fprintf(bw, "%stemplate = %s\n", hclIndent, quoteHCL(f.Template))
+		if f.SkipIf != "" {
+			fmt.Fprintf(bw, "%sskip_if  = %s\n", hclIndent, quoteHCL(f.SkipIf))
+		}
+		if f.Format != "" {
+			fmt.Fprintf(bw, "%sformat   = %s\n", hclIndent, quoteHCL(f.Format))
+		}
+		if len(f.Secrets) != 0 {
+			fmt.Fprintf(bw, "%ssecrets  = %s\n", hclIndent, hclList(f.Secrets))
+		}
+		if len(f.Local) != 0 {
+			fmt.Fprintln(bw)
+			if err := writeHCLBlock(bw, "local", f.Local, 1); err != nil {
+				return fmt.Errorf("file %d: %w", i, err)
+			}
+		}
+		if len(f.Computed) != 0 {
+			fmt.Fprintln(bw)
+			if err := writeHCLBlock(bw, "computed", hclExpressions(f.Computed), 1); err != nil {
+				return fmt.Errorf("file %d: %w", i, err)
+			}
+		}
+		if len(f.Choices) != 0 {
+			fmt.Fprintln(bw)
+			if err := writeHCLBlock(bw, "choices", hclChoices(f.Choices), 1); err != nil {
+				return fmt.Errorf("file %d: %w", i, err)
+			}
+		}
+		for _, h := range f.Hooks {
+			fmt.Fprintln(bw)
+			writeHCLCommand(bw, "hook", h, 1)
+		}
+		fmt.Fprintln(bw, "}")
+	}
+	for _, list := range []struct {
+		block string
+		cmds  []Command
+	}{{"pre_command", cfg.PreCmds}, {"command", cfg.Cmds}} {
+		for _, c := range list.cmds {
+			fmt.Fprintln(bw)
+			writeHCLCommand(bw, list.block, c, 0)
+		}
+	}
+	return bw.Flush()
+}
+
+// writeHCLCommand writes c as a block called block, nested depth levels
+// deep.
+func writeHCLCommand(w io.Writer, block string, c Command, depth int) {
+	var (
+		indent = strings.Repeat(hclIndent, depth)
+		inner  = indent + hclIndent
+	)
+	fmt.Fprintf(w, "%s%s %s {\n", indent, block, quoteHCL(c.Name))
+	fmt.Fprintf(w, "%sargs = %s\n", inner, hclList(c.Args))
+	if c.ID != "" {
+		fmt.Fprintf(w, "%sid   = %s\n", inner, quoteHCL(c.ID))
+	}
+	if c.Dir != "" {
+		fmt.Fprintf(w, "%sdir  = %s\n", inner, quoteHCL(c.Dir))
+	}
+	if c.Timeout != "" {
+		fmt.Fprintf(w, "%stimeout = %s\n", inner, quoteHCL(c.Timeout))
+	}
+	if c.Retries != 0 {
+		fmt.Fprintf(w, "%sretries = %d\n", inner, c.Retries)
+	}
+	if len(c.DependsOn) != 0 {
+		fmt.Fprintf(w, "%sdepends_on = %s\n", inner, hclList(c.DependsOn))
+	}
+	if c.Capture != "" {
+		fmt.Fprintf(w, "%scapture = %s\n", inner, quoteHCL(c.Capture))
+	}
+	if c.Shell {
+		fmt.Fprintf(w, "%sshell = true\n", inner)
+	}
+	if c.When != "" {
+		fmt.Fprintf(w, "%swhen = %s\n", inner, quoteHCL(c.When))
+	}
+	if c.OnFailure != "" {
+		fmt.Fprintf(w, "%son_failure = %s\n", inner, quoteHCL(c.OnFailure))
+	}
+	if len(c.Env) != 0 {
+		fmt.Fprintf(w, "%senv = {\n", inner)
+		for _, k := range sortedKeys(c.Env) {
+			key := k
+			if !isHCLIdentifier(k) {
+				key = quoteHCL(k)
+			}
+			fmt.Fprintf(w, "%s%s%s = %s\n", inner, hclIndent, key, quoteHCL(c.Env[k]))
+		}
+		fmt.Fprintf(w, "%s}\n", inner)
+	}
+	fmt.Fprintf(w, "%s}\n", indent)
+}
+
+// hclExpressions turns the expressions of computed variables into the
+// string attributes of a block.
+func hclExpressions(computed map[string]string) map[string]any {
+	attrs := make(map[string]any, len(computed))
+	for k, expr := range computed {
+		attrs[k] = expr
+	}
+	return attrs
+}
+
+// hclChoices turns the choices of variables into the list attributes of a
+// block.
+func hclChoices(choices map[string][]string) map[string]any {
+	attrs := make(map[string]any, len(choices))
+	for k, values := range choices {
+		list := make([]any, len(values))
+		for i, v := range values {
+			list[i] = v
+		}
+		attrs[k] = list
+	}
+	return attrs
+}
+
+func writeHCLBlock(w io.Writer, name string, vars map[string]any, depth int) error {
+	indent := strings.Repeat(hclIndent, depth)
+
+	fmt.Fprintf(w, "%s%s {\n", indent, name)
+
+	for _, k := range sortedKeys(vars) {
+		if !isHCLIdentifier(k) {
+			return fmt.Errorf("%q is not a valid HCL identifier", k)
+		}
+		v, err := hclValue(vars[k])
+		if err != nil {
+			return fmt.Errorf("%s: %w", k, err)
+		}
+		fmt.Fprintf(w, "%s%s%s = %s\n", indent, hclIndent, k, v)
+	}
+	fmt.Fprintf(w, "%s}\n", indent)
+	return nil
+}
+
+func hclValue(v any) (string, error) {
+	switch val := v.(type) {
+	case nil:
+		return "null", nil
+	case string:
+		return quoteHCL(val), nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	case int64:
+		return strconv.FormatInt(val, 10), nil
+	case float64:
+		return strconv.FormatFloat(val, 'g', -1, 64), nil
+	case json.Number:
+		return val.String(), nil
+	case time.Time:
+		return quoteHCL(val.Format(time.RFC3339Nano)), nil
+	case []any:
+		elems := make([]string, len(val))
+		for i, e := range val {
+			s, err := hclValue(e)
+			if err != nil {
+				return "", err
+			}
+			elems[i] = s
+		}
+		return "[" + strings.Join(elems, ", ") + "]", nil
+	case map[string]any:
+		fields := make([]string, 0, len(val))
+		for _, k := range sortedKeys(val) {
+			s, err := hclValue(val[k])
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", k, err)
+			}
+			key := k
+			if !isHCLIdentifier(k) {
+				key = quoteHCL(k)
+			}
+			fields = append(fields, key+" = "+s)
+		}
+		return "{ " + strings.Join(fields, ", ") + " }", nil
+	default:
+		return "", fmt.Errorf("unsupported value type %T", v)
+	}
+}
+
+func hclList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = quoteHCL(v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// quoteHCL produces an HCL string literal, escaping template sequences so
+// that values are never interpreted as interpolations.
+func quoteHCL(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case (r == '$' || r == '%') && strings.HasPrefix(s[i+1:], "{"):
+			b.WriteRune(r)
+			b.WriteRune(r)
+		case !unicode.IsPrint(r) && r > 0xFFFF:
+			// \u only takes four hex digits.
+			fmt.Fprintf(&b, `\U%08X`, r)
+		case !unicode.IsPrint(r):
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func isHCLIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case unicode.IsLetter(r), r == '_':
+		case i > 0 && (unicode.IsDigit(r) || r == '-'):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncodeHCL(t *testing.T) {
+	cfg := Config{
+		Global: map[string]any{
+			"Name":    "demo",
+			"Port":    int64(8080),
+			"Ratio":   0.5,
+			"Exact":   json.Number("1.50"),
+			"Debug":   false,
+			"Nothing": nil,
+			"Tags":    []any{"a", int64(1)},
+			"db":      map[string]any{"host": "localhost", "max-conns": int64(10)},
+			"Started": time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		Secrets:      []string{"Token"},
+		Computed:     map[string]string{"Upper": `upper(Name) + "!"`},
+		Choices:      map[string][]string{"Env": {"dev", "prod"}},
+		Environments: map[string]Environment{"prod": {Global: map[string]any{"Port": int64(443)}}},
+		Files: []File{{
+			Name:     "main.go",
+			Path:     "cmd",
+			Template: "main.tmpl",
+			SkipIf:   `Name == "skip"`,
+			Format:   FormatterGofmt,
+			Local:    map[string]any{"Pkg": "main"},
+			Hooks:    []Command{{Name: "gofmt", Args: []string{"-w", "cmd/main.go"}}},
+		}},
+		PreCmds: []Command{{ID: "rev", Name: "git", Args: []string{"rev-parse", "HEAD"}, Capture: "Rev"}},
+		Cmds: []Command{{
+			Name:      "go",
+			Args:      []string{"build"},
+			Dir:       "out",
+			Timeout:   "1m",
+			Retries:   2,
+			DependsOn: []string{"rev"},
+			Shell:     true,
+			When:      `os == "linux"`,
+			OnFailure: OnFailureWarn,
+			Env:       map[string]string{"CGO_ENABLED": "0", "GO-FLAGS": "-v"},
+		}},
+	}
+	want := `version = 1
+secrets = ["Token"]
+
+global {
+  Debug = false
+  Exact = 1.50
+  Name = "demo"
+  Nothing = null
+  Port = 8080
+  Ratio = 0.5
+  Started = "2024-01-02T03:04:05Z"
+  Tags = ["a", 1]
+  db = { host = "localhost", max-conns = 10 }
+}
+
+computed {
+  Upper = "upper(Name) + \"!\""
+}
+
+choices {
+  Env = ["dev", "prod"]
+}
+
+environment "prod" {
+  global {
+    Port = 443
+  }
+}
+
+file "main.go" {
+  path     = "cmd"
+  template = "main.tmpl"
+  skip_if  = "Name == \"skip\""
+  format   = "gofmt"
+
+  local {
+    Pkg = "main"
+  }
+
+  hook "gofmt" {
+    args = ["-w", "cmd/main.go"]
+  }
+}
+
+pre_command "git" {
+  args = ["rev-parse", "HEAD"]
+  id   = "rev"
+  capture = "Rev"
+}
+
+command "go" {
+  args = ["build"]
+  dir  = "out"
+  timeout = "1m"
+  retries = 2
+  depends_on = ["rev"]
+  shell = true
+  when = "os == \"linux\""
+  on_failure = "warn"
+  env = {
+    CGO_ENABLED = "0"
+    GO-FLAGS = "-v"
+  }
+}
+`
+	var b bytes.Buffer
+	if err := Encode(&b, cfg, EncodeOptions{Format: FormatHCL}); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestEncodeHCLErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{
+			name:    "key",
+			cfg:     Config{Global: map[string]any{"1st": 1}},
+			wantErr: `global: "1st" is not a valid HCL identifier`,
+		},
+		{
+			name:    "value",
+			cfg:     Config{Files: []File{{Name: "a", Local: map[string]any{"Ch": make(chan int)}}}},
+			wantErr: "file 0: Ch: unsupported value type chan int",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Encode(&bytes.Buffer{}, tt.cfg, EncodeOptions{Format: FormatHCL})
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+	if err := EncodeValue(&bytes.Buffer{}, []File{}, EncodeOptions{Format: FormatHCL}); err == nil || !strings.Contains(err.Error(), "only whole configs") {
+		t.Errorf("got error %v encoding a section, want only whole configs", err)
+	}
+}
+
+func TestQuoteHCL(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", `""`},
+		{"plain", `"plain"`},
+		{`a "b" \c`, `"a \"b\" \\c"`},
+		{"line\nbreak\r\ttab", `"line\nbreak\r\ttab"`},
+		{"${Name} %{if x}", `"$${Name} %%{if x}"`},
+		{"$Name 100%", `"$Name 100%"`},
+		{"größe 名前", `"größe 名前"`},
+		{"bell\a", `"bell\u0007"`},
+		{"zero\u200bwidth", `"zero\u200Bwidth"`},
+		{"tag\U000E0001", `"tag\U000E0001"`},
+	}
+	for _, tt := range tests {
+		if got := quoteHCL(tt.in); got != tt.want {
+			t.Errorf("quoteHCL(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
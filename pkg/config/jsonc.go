@@ -0,0 +1,121 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// encodeJSONC writes cfg as JSON annotated with comments taken from the
+// schema descriptions, so that a generated config documents itself.
+func encodeJSONC(w io.Writer, indent int, cfg Config) error {
+	bw := bufio.NewWriter(w)
+	e := jsoncEncoder{w: bw, step: strings.Repeat(" ", indent)}
+
+	e.comment(schemaDescriptions["Config"], 0)
+	if err := e.value(reflect.ValueOf(cfg), 0); err != nil {
+		return err
+	}
+	fmt.Fprintln(bw)
+	return bw.Flush()
+}
+
+type jsoncEncoder struct {
+	w    io.Writer
+	step string
+}
+
+func (e jsoncEncoder) indent(depth int) string {
+	return strings.Repeat(e.step, depth)
+}
+
+func (e jsoncEncoder) comment(text string, depth int) {
+	if text == "" {
+		return
+	}
+	fmt.Fprintf(e.w, "%s// %s\n", e.indent(depth), text)
+}
+
+func (e jsoncEncoder) value(v reflect.Value, depth int) error {
+	switch {
+	case v.Kind() == reflect.Struct:
+		return e.object(v, depth)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct && v.Len() != 0:
+		fmt.Fprintln(e.w, "[")
+		for i := 0; i < v.Len(); i++ {
+			fmt.Fprint(e.w, e.indent(depth+1))
+			if err := e.value(v.Index(i), depth+1); err != nil {
+				return err
+			}
+			if i < v.Len()-1 {
+				fmt.Fprint(e.w, ",")
+			}
+			fmt.Fprintln(e.w)
+		}
+		fmt.Fprintf(e.w, "%s]", e.indent(depth))
+		return nil
+	default:
+		b, err := json.MarshalIndent(v.Interface(), e.indent(depth), e.step)
+		if err != nil {
+			return err
+		}
+		_, err = e.w.Write(b)
+		return err
+	}
+}
+
+func (e jsoncEncoder) object(v reflect.Value, depth int) error {
+	type field struct {
+		name  string
+		value reflect.Value
+	}
+
+	t := v.Type()
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		if strings.Contains(opts, "omitempty") && isEmptyJSON(v.Field(i)) {
+			continue
+		}
+		fields = append(fields, field{name: name, value: v.Field(i)})
+	}
+
+	fmt.Fprintln(e.w, "{")
+	for i, f := range fields {
+		e.comment(schemaDescriptions[t.Name()+"."+f.name], depth+1)
+		fmt.Fprintf(e.w, "%s%q: ", e.indent(depth+1), f.name)
+		if err := e.value(f.value, depth+1); err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+		if i < len(fields)-1 {
+			fmt.Fprint(e.w, ",")
+		}
+		fmt.Fprintln(e.w)
+	}
+	fmt.Fprintf(e.w, "%s}", e.indent(depth))
+	return nil
+}
+
+// isEmptyJSON reports whether encoding/json omits v from fields tagged
+// omitempty: empty maps, slices and strings as well as zero values.
+func isEmptyJSON(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.String:
+		return v.Len() == 0
+	case reflect.Struct:
+		return false
+	default:
+		return v.IsZero()
+	}
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// DefaultKeyPattern is the regular expression the names of variables have to
+// match by default.
+const DefaultKeyPattern = `^[A-Za-z_][A-Za-z0-9_]*$`
+
+// DefaultReservedKeys are the names of the config sections, which would be
+// confusing to use as variables within templates.
+var DefaultReservedKeys = []string{"global", "files", "commands", "local", "template"}
+
+// KeyRules constrain the names of global and local variables. The zero value
+// applies DefaultKeyPattern and DefaultReservedKeys.
+type KeyRules struct {
+	pattern  *regexp.Regexp
+	reserved map[string]bool
+}
+
+var defaultKeyRules = mustKeyRules(DefaultKeyPattern, DefaultReservedKeys)
+
+// NewKeyRules returns the rules admitting the names matching pattern, except
+// for the reserved ones.
+func NewKeyRules(pattern string, reserved []string) (KeyRules, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return KeyRules{}, fmt.Errorf("key pattern: %w", err)
+	}
+	r := KeyRules{pattern: re, reserved: make(map[string]bool)}
+	for _, k := range reserved {
+		if k = strings.TrimSpace(k); k != "" {
+			r.reserved[k] = true
+		}
+	}
+	return r, nil
+}
+
+func mustKeyRules(pattern string, reserved []string) KeyRules {
+	r, err := NewKeyRules(pattern, reserved)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Check returns a descriptive error if key breaks the rules.
+func (r KeyRules) Check(key string) error {
+	if r.pattern == nil {
+		r = defaultKeyRules
+	}
+	switch {
+	case key == "":
+		return fmt.Errorf("variable name must not be empty")
+	case r.reserved[key]:
+		return fmt.Errorf("variable name %q is reserved", key)
+	case r.pattern.MatchString(key):
+		return nil
+	case unicode.IsDigit([]rune(key)[0]):
+		return fmt.Errorf("variable name %q must not start with a digit", key)
+	default:
+		return fmt.Errorf("variable name %q must match %s", key, r.pattern)
+	}
+}
+
+// CheckName checks every segment of the possibly dotted name, such as
+// db.host.
+func (r KeyRules) CheckName(name string) error {
+	for _, segment := range strings.Split(name, ".") {
+		if err := r.Check(segment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
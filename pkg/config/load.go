@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Load reads a config from r, detecting whether it is written in JSON, JSON
+// with comments, YAML or TOML from its content. Configs in an older layout
+// are migrated to the current Version, and the result is validated: configs
+// failing Validate are rejected with a ValidationErrors error.
+func Load(r io.Reader) (*Config, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	cfg, err := load(data, DetectFormat("", data))
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	return cfg, nil
+}
+
+// LoadFile is Load for the config at path, whose format is detected from its
+// extension first.
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	cfg, err := load(data, DetectFormat(path, data))
+	if err != nil {
+		return nil, fmt.Errorf("load config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func load(data []byte, format string) (*Config, error) {
+	cfg, err := Decode(data, format)
+	if err != nil {
+		return nil, err
+	}
+	if errs := Validate(cfg); len(errs) != 0 {
+		return nil, errs
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,70 @@
+package config
+
+import "fmt"
+
+// migrations[v] upgrades a decoded config from version v to v+1 in place.
+// There must be exactly Version entries.
+var migrations = [Version]func(raw map[string]any) error{
+	// 0 -> 1: the layout is unchanged, only the version field is introduced.
+	func(map[string]any) error { return nil },
+}
+
+// rawVersion reports the version of a decoded, not yet migrated config.
+func rawVersion(raw map[string]any) (int, error) {
+	v, ok := raw["version"]
+	if !ok || v == nil {
+		return 0, nil
+	}
+	n, ok := NormalizeValue(v).(int64)
+	if !ok || n < 0 {
+		return 0, fmt.Errorf("invalid config version: %v", v)
+	}
+	if n > Version {
+		return 0, fmt.Errorf("config version %d is newer than the supported version %d", n, Version)
+	}
+	return int(n), nil
+}
+
+// migrateRaw upgrades raw to Version and returns the version it was
+// originally at.
+func migrateRaw(raw map[string]any) (int, error) {
+	from, err := rawVersion(raw)
+	if err != nil {
+		return 0, err
+	}
+	for v := from; v < Version; v++ {
+		if err := migrations[v](raw); err != nil {
+			return from, fmt.Errorf("migrate from version %d: %w", v, err)
+		}
+	}
+	raw["version"] = Version
+	return from, nil
+}
+
+// decodeRaw decodes data into a generic map, so that it can be inspected and
+// migrated before being mapped onto Config.
+func decodeRaw(data []byte, format string) (map[string]any, error) {
+	var (
+		raw map[string]any
+		err error
+	)
+	c, ok := LookupFormat(format)
+	if !ok || c.Decode == nil {
+		return nil, fmt.Errorf("reading %s configs is not supported", format)
+	}
+	err = c.Decode(data, &raw)
+	if raw == nil {
+		raw = make(map[string]any)
+	}
+	return raw, err
+}
+
+// DetectVersion reports the version of the layout the config data, written
+// in format, is in, without migrating it.
+func DetectVersion(data []byte, format string) (int, error) {
+	raw, err := decodeRaw(data, format)
+	if err != nil {
+		return 0, err
+	}
+	return rawVersion(raw)
+}
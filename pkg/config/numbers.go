@@ -0,0 +1,227 @@
+package config
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// jsonNumberPattern matches the numbers of JSON syntax, the ones configs are
+// written with.
+var jsonNumberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// formatFloat formats f the way floats are written to configs: as
+// encoding/json does, with .0 appended to whole numbers so that they are
+// read back as floats rather than integers.
+func formatFloat(f float64) string {
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	s := strconv.FormatFloat(f, format, -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return s
+}
+
+// writtenValue returns v, a Config or part of one, with the floats among its
+// variables replaced by their text as formatFloat writes it, and every
+// number by what number makes of its text, so that encoders write numbers
+// exactly. Maps and slices are copied rather than modified.
+func writtenValue(v any, number func(string) any) any {
+	switch val := v.(type) {
+	case Config:
+		val.Global = writtenVars(val.Global, number)
+		val.Files = writtenValue(val.Files, number).([]File)
+		if val.Environments != nil {
+			envs := make(map[string]Environment, len(val.Environments))
+			for name, env := range val.Environments {
+				env.Global = writtenVars(env.Global, number)
+				envs[name] = env
+			}
+			val.Environments = envs
+		}
+		return val
+	case []File:
+		if val == nil {
+			return val
+		}
+		files := make([]File, len(val))
+		for i, f := range val {
+			f.Local = writtenVars(f.Local, number)
+			files[i] = f
+		}
+		return files
+	case map[string]any:
+		return writtenVars(val, number)
+	case []any:
+		list := make([]any, len(val))
+		for i, e := range val {
+			list[i] = writtenValue(e, number)
+		}
+		return list
+	case float64:
+		if math.IsInf(val, 0) || math.IsNaN(val) {
+			return val
+		}
+		return number(formatFloat(val))
+	case json.Number:
+		return number(val.String())
+	default:
+		return v
+	}
+}
+
+func writtenVars(vars map[string]any, number func(string) any) map[string]any {
+	if vars == nil {
+		return nil
+	}
+	out := make(map[string]any, len(vars))
+	for k, v := range vars {
+		out[k] = writtenValue(v, number)
+	}
+	return out
+}
+
+// yamlNumber is a number written to YAML as a plain scalar, where
+// json.Number would be quoted as the string it is.
+type yamlNumber string
+
+func (n yamlNumber) MarshalYAML() (any, error) {
+	tag := "!!float"
+	if integerPattern.MatchString(string(n)) {
+		tag = "!!int"
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: string(n)}, nil
+}
+
+// decodeYAML is yaml.Unmarshal, except that numbers decoded into generic
+// values are kept as json.Number, as unmarshalJSON keeps them, so that
+// exactNumbers sees their text.
+func decodeYAML(data []byte, v any) error {
+	switch v.(type) {
+	case *map[string]any, *any:
+	default:
+		return yaml.Unmarshal(data, v)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	val, err := yamlValue(doc.Content[0])
+	if err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case *map[string]any:
+		*v, _ = val.(map[string]any)
+	case *any:
+		*v = val
+	}
+	return nil
+}
+
+func yamlValue(n *yaml.Node) (any, error) {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return yamlValue(n.Alias)
+	case yaml.SequenceNode:
+		list := make([]any, len(n.Content))
+		for i, e := range n.Content {
+			v, err := yamlValue(e)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = v
+		}
+		return list, nil
+	case yaml.MappingNode:
+		m := make(map[string]any, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if k.ShortTag() == "!!merge" {
+				// Merge keys are left to the decoder.
+				var merged map[string]any
+				if err := v.Decode(&merged); err != nil {
+					return nil, err
+				}
+				for mk, mv := range merged {
+					if _, ok := m[mk]; !ok {
+						m[mk] = mv
+					}
+				}
+				continue
+			}
+			val, err := yamlValue(v)
+			if err != nil {
+				return nil, err
+			}
+			m[k.Value] = val
+		}
+		return m, nil
+	case yaml.ScalarNode:
+		if tag := n.ShortTag(); (tag == "!!int" || tag == "!!float") && jsonNumberPattern.MatchString(n.Value) {
+			return json.Number(n.Value), nil
+		}
+	}
+	var v any
+	err := n.Decode(&v)
+	return v, err
+}
+
+// exactNumbers replaces the numbers among the variables of cfg by the ones
+// of the generic document raw cfg was decoded from, which keeps their text,
+// so that NormalizeValue can tell numbers whose text would not survive a
+// conversion to int64 or float64.
+func exactNumbers(cfg *Config, raw map[string]any) {
+	cfg.Global = exactVars(cfg.Global, raw["global"])
+	files, _ := raw["files"].([]any)
+	for i := range cfg.Files {
+		if i < len(files) {
+			f, _ := files[i].(map[string]any)
+			cfg.Files[i].Local = exactVars(cfg.Files[i].Local, f["local"])
+		}
+	}
+	envs, _ := raw["environments"].(map[string]any)
+	for name, env := range cfg.Environments {
+		e, _ := envs[name].(map[string]any)
+		env.Global = exactVars(env.Global, e["global"])
+		cfg.Environments[name] = env
+	}
+}
+
+func exactVars(vars map[string]any, raw any) map[string]any {
+	m, _ := raw.(map[string]any)
+	for k, v := range vars {
+		vars[k] = exactValue(v, m[k])
+	}
+	return vars
+}
+
+func exactValue(v, raw any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		return exactVars(val, raw)
+	case []any:
+		list, _ := raw.([]any)
+		for i := range val {
+			if i < len(list) {
+				val[i] = exactValue(val[i], list[i])
+			}
+		}
+		return val
+	case int, int64, uint64, float64, json.Number:
+		if n, ok := raw.(json.Number); ok {
+			return n
+		}
+	}
+	return v
+}
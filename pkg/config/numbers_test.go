@@ -0,0 +1,66 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestNumbersRoundTrip(t *testing.T) {
+	global := map[string]any{
+		"Int":    int64(3),
+		"Float":  2.5,
+		"Whole":  float64(2),
+		"Exact":  json.Number("1.50"),
+		"Big":    json.Number("12345678901234567890"),
+		"Exp":    json.Number("1e3"),
+		"List":   []any{int64(1), float64(2), json.Number("0.10")},
+		"Nested": map[string]any{"Port": int64(8080), "Ratio": float64(1)},
+	}
+	for _, format := range []string{FormatJSON, FormatJSONC, FormatYAML} {
+		t.Run(format, func(t *testing.T) {
+			cfg := Config{Global: global, Files: []File{{Name: "a", Path: ".", Template: "t", Local: map[string]any{"N": float64(7)}}}}
+			var b bytes.Buffer
+			if err := Encode(&b, cfg, EncodeOptions{Format: format, Indent: DefaultIndent}); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Decode(b.Bytes(), format)
+			if err != nil {
+				t.Fatalf("decode: %v\n%s", err, b.String())
+			}
+			if !reflect.DeepEqual(got.Global, global) {
+				t.Errorf("got globals %#v, want %#v\n%s", got.Global, global, b.String())
+			}
+			if want := map[string]any{"N": float64(7)}; !reflect.DeepEqual(got.Files[0].Local, want) {
+				t.Errorf("got locals %#v, want %#v", got.Files[0].Local, want)
+			}
+		})
+	}
+}
+
+func TestNormalizeValue(t *testing.T) {
+	tests := []struct {
+		in   any
+		want any
+	}{
+		{int(1), int64(1)},
+		{uint64(1 << 63), json.Number("9223372036854775808")},
+		{float32(0.5), float64(0.5)},
+		{float64(2), float64(2)},
+		{json.Number("42"), int64(42)},
+		{json.Number("-0"), int64(0)},
+		{json.Number("99999999999999999999"), json.Number("99999999999999999999")},
+		{json.Number("2.5"), 2.5},
+		{json.Number("2.0"), float64(2)},
+		{json.Number("2.50"), json.Number("2.50")},
+		{json.Number("1e-07"), 1e-7},
+		{json.Number("1e-7"), json.Number("1e-7")},
+		{json.Number("1E3"), json.Number("1E3")},
+	}
+	for _, tt := range tests {
+		if got := NormalizeValue(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("NormalizeValue(%#v) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
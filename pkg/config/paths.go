@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Target returns the normalized path of the file generated for f, on the
+// system the program runs on.
+func (f File) Target() string {
+	return filepath.Clean(filepath.Join(filepath.FromSlash(f.Path), filepath.FromSlash(f.Name)))
+}
+
+// PortablePath returns p in the form configs are best stored in, which
+// every system reads the same: cleaned and slash separated, backslashes
+// being taken for separators.
+func PortablePath(p string) string {
+	if p == "" {
+		return ""
+	}
+	return path.Clean(strings.ReplaceAll(p, `\`, "/"))
+}
+
+// windowsReserved are the device names Windows reserves, with or without an
+// extension, in any case.
+var windowsReserved = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// windowsVolume returns the drive letter or UNC prefix p starts with, if
+// any, such as C: or //server/share.
+func windowsVolume(p string) string {
+	p = strings.ReplaceAll(p, `\`, "/")
+	switch {
+	case len(p) >= 2 && p[1] == ':' && ('a' <= p[0] && p[0] <= 'z' || 'A' <= p[0] && p[0] <= 'Z'):
+		return p[:2]
+	case strings.HasPrefix(p, "//"):
+		server, share, _ := strings.Cut(p[2:], "/")
+		share, _, _ = strings.Cut(share, "/")
+		return "//" + server + "/" + share
+	}
+	return ""
+}
+
+// IsAbsPath reports whether p is absolute on any system: rooted, or on a
+// Windows drive or share.
+func IsAbsPath(p string) bool {
+	return strings.HasPrefix(p, "/") || strings.HasPrefix(p, `\`) || windowsVolume(p) != ""
+}
+
+// checkPathFor verifies that the path p of a config means the same on the
+// system goos as where it was written: that it is no absolute path of
+// another system, uses separators goos knows, and, for Windows, has no
+// segment Windows refuses. Names of files may be glob patterns, so isName
+// skips the check of the characters Windows refuses.
+func checkPathFor(goos, p string, isName bool) ValidationErrors {
+	var problems ValidationErrors
+	add := func(s Severity, format string, args ...any) {
+		problems = append(problems, ValidationError{Severity: s, Message: fmt.Sprintf(format, args...)})
+	}
+	windows := goos == "windows"
+	volume := windowsVolume(p)
+	switch {
+	case !windows && volume != "":
+		add(SeverityError, "%s is absolute on windows only, and would be a relative directory on %s", p, goos)
+	case windows && (strings.HasPrefix(p, "/") || strings.HasPrefix(p, `\`)) && volume == "":
+		add(SeverityWarning, "%s is relative to the current drive on windows", p)
+	}
+	if !windows && strings.Contains(p, `\`) {
+		add(SeverityWarning, "%s uses \\ as a separator, which %s takes for part of a name; store it as %s", p, goos, PortablePath(p))
+	}
+	if !windows {
+		return problems
+	}
+	rest := strings.TrimPrefix(strings.ReplaceAll(p, `\`, "/"), volume)
+	for _, segment := range strings.Split(rest, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			continue
+		}
+		base, _, _ := strings.Cut(segment, ".")
+		switch {
+		case windowsReserved[strings.ToUpper(strings.TrimRight(base, " "))]:
+			add(SeverityError, "%s is a device name reserved by windows", segment)
+		case strings.HasSuffix(segment, ".") || strings.HasSuffix(segment, " "):
+			add(SeverityError, "%q ends with a dot or a space, which windows drops", segment)
+		case !isName && strings.ContainsAny(segment, `<>:"|?*`):
+			add(SeverityError, "%q contains one of the characters <>:\"|?* which windows refuses", segment)
+		}
+	}
+	return problems
+}
+
+// FindCollision returns the index of the entry of files, other than skip,
+// producing the same output file as f, or -1 if there is none.
+func FindCollision(files []File, f File, skip int) int {
+	target := f.Target()
+	for i, other := range files {
+		if i != skip && other.Target() == target {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/omerkaya1/gg-config/internal/expr"
+	"github.com/omerkaya1/gg-config/internal/variables"
+)
+
+// Resolve returns cfg with the references in the local variables of its
+// files, such as ${Name}, replaced by the values of the globals and its
+// computed variables evaluated. Configs keep the references and the
+// expressions of computed variables so that they stay consistent when
+// globals change; they are resolved just for rendering.
+func Resolve(cfg Config) (Config, error) {
+	var err error
+	if cfg.Global, err = expr.Compute(cfg.Global, nil, cfg.Computed); err != nil {
+		return cfg, fmt.Errorf("computed.%w", err)
+	}
+	files := make([]File, len(cfg.Files))
+	copy(files, cfg.Files)
+	cfg.Files = files
+	for i := range cfg.Files {
+		f := &cfg.Files[i]
+		if f.Local, err = variables.Expand(f.Local, variables.Data(cfg.Global, nil)); err != nil {
+			return cfg, fmt.Errorf("files[%d].local.%w", i, err)
+		}
+		if f.Local, err = expr.Compute(f.Local, cfg.Global, f.Computed); err != nil {
+			return cfg, fmt.Errorf("files[%d].computed.%w", i, err)
+		}
+	}
+	return cfg, nil
+}
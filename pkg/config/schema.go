@@ -0,0 +1,150 @@
+package config
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+)
+
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// schemaDescriptions documents the fields of the generated schema. Keys are
+// "<Type>.<json field name>".
+var schemaDescriptions = map[string]string{
+	"Config":              "gg-config generator configuration.",
+	"Config.version":      "The version of the config layout, omitted by configs predating versioning.",
+	"Config.global":       "Variables shared by all templates.",
+	"Config.files":        "Files to be generated out of templates.",
+	"Config.pre_commands": "Pre-generation commands executed in declaration order before rendering.",
+	"Config.commands":     "Post-generation commands executed in declaration order.",
+	"File":                "A single file to be generated out of a template.",
+	"File.name":           "The name of the file to be generated out of the template; with a template pattern, every * stands for the matched template name.",
+	"File.path":           "The path to where the file will be placed.",
+	"File.template":       "The name of the template to use: a file, a glob pattern or a directory of templates.",
+	"File.local":          "Variables specific to the template; string values may refer to global variables as ${Name}, resolved at render time.",
+	"File.format":         "Formatter applied to the generated file: none, auto (by extension), gofmt, goimports or json.",
+	"File.skip_if":        "Condition over the variables, e.g. UseDocker == false, under which the file is not generated.",
+	"Command":             "A post-generation hook.",
+	"Command.name":        "The name of the command to be called.",
+	"Command.dir":         "The working directory of the command, the current one if empty; may contain templates like the arguments.",
+	"Command.env":         "Environment variables added to the ones the command inherits.",
+	"Command.timeout":     "How long the command may run before it is killed, e.g. 30s or 5m; unlimited if empty.",
+	"Command.retries":     "How many times the command is retried after failing.",
+	"Command.shell":       "Run the name and arguments, joined by spaces, through sh -c (cmd.exe /C on Windows), enabling pipes and redirections.",
+	"Command.when":        "Condition the command only runs if it holds, over the variables of the config, the captured ones and os and arch, e.g. os == \"linux\".",
+	"Command.on_failure":  "What a failure of the command does: abort the remaining commands (the default), continue silently or warn and continue.",
+	"Command.capture":     "Variable the standard output of the command is stored in, available to the templates if the command is a pre-command, and to later commands as an environment variable.",
+	"Config.secrets":      "Names of the global variables holding secrets, which are redacted from summaries and render logs.",
+	"Config.computed":     "Global variables computed at render time from expressions over other variables, e.g. upper(Name) + \"-\" + string(Major + 1).",
+	"Config.environments": "Overlays of the global variables by environment name, such as dev, staging and prod, selected with gg-config render --env.",
+	"Environment":         "An overlay of the global variables for a single deployment.",
+	"Environment.global":  "Global variables overriding the ones of the same names, nested maps being merged, or added to them.",
+	"File.computed":       "Local variables computed at render time from expressions over the local and global variables.",
+	"Config.choices":      "Values global variables are restricted to, e.g. MIT, Apache-2.0 and GPL-3.0 for License; other values are rejected.",
+	"File.choices":        "Values local variables are restricted to.",
+	"File.secrets":        "Names of the local variables holding secrets.",
+	"File.hooks":          "Commands run after the file has been written, unless it was left unchanged.",
+	"Command.id":          "Identifier other commands of the same section refer to in depends_on.",
+	"Command.depends_on":  "IDs of the commands of the same section that have to run before this one.",
+	"Command.args":        "The arguments passed to the command, which may contain templates such as {{ .Global.ProjectDir }}, executed with .Global, .Files and, for file hooks, .File and .Local.",
+}
+
+// WriteSchema writes the JSON Schema of the config layout to w.
+func WriteSchema(w io.Writer) error {
+	defs := make(map[string]any)
+	root := schemaFor(reflect.TypeOf(Config{}), defs)
+	root["$schema"] = schemaDialect
+	root["$defs"] = defs
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(root)
+}
+
+func schemaFor(t reflect.Type, defs map[string]any) map[string]any {
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		return map[string]any{"type": []string{"array", "null"}, "items": schemaRef(t.Elem(), defs)}
+	case reflect.Map:
+		s := map[string]any{"type": []string{"object", "null"}}
+		if t.Elem().Kind() != reflect.Interface {
+			s["additionalProperties"] = schemaRef(t.Elem(), defs)
+		}
+		return s
+	case reflect.Struct:
+		return structSchema(t, defs)
+	default:
+		return map[string]any{}
+	}
+}
+
+// schemaRef returns a reference to a named struct definition, registering it
+// in defs on first use, or an inline schema for every other type.
+func schemaRef(t reflect.Type, defs map[string]any) map[string]any {
+	if t.Kind() != reflect.Struct {
+		return schemaFor(t, defs)
+	}
+	if _, ok := defs[t.Name()]; !ok {
+		defs[t.Name()] = nil
+		defs[t.Name()] = structSchema(t, defs)
+	}
+	return map[string]any{"$ref": "#/$defs/" + t.Name()}
+}
+
+func isNullable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Pointer, reflect.Interface:
+		return true
+	default:
+		return false
+	}
+}
+
+func structSchema(t reflect.Type, defs map[string]any) map[string]any {
+	var (
+		props    = make(map[string]any)
+		required []string
+	)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		s := schemaRef(f.Type, defs)
+		if d, ok := schemaDescriptions[t.Name()+"."+name]; ok {
+			s["description"] = d
+		}
+		props[name] = s
+		if !strings.Contains(opts, "omitempty") && !isNullable(f.Type) {
+			required = append(required, name)
+		}
+	}
+
+	s := map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if len(required) != 0 {
+		s["required"] = required
+	}
+	if d, ok := schemaDescriptions[t.Name()]; ok {
+		s["description"] = d
+	}
+	return s
+}
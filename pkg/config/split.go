@@ -0,0 +1,70 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrSplitIndex is returned by Decode for the index of a config written as
+// separate files, one per section, which only ReadFile can locate.
+var ErrSplitIndex = errors.New("the index of a split config can only be read from its file")
+
+// splitSections are the fields of a split index naming the files the
+// sections of the config were written to.
+var splitSections = []string{"global", "files", "pre_commands", "commands"}
+
+// isSplitIndex reports whether raw is the index of a split config rather
+// than a config, whose globals are a map and not the name of a file.
+func isSplitIndex(raw map[string]any) bool {
+	_, ok := raw["global"].(string)
+	return ok
+}
+
+// IsSplitIndex reports whether the file at path is the index of a split
+// config.
+func IsSplitIndex(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	raw, err := decodeRaw(data, DetectFormat(path, data))
+	return err == nil && isSplitIndex(raw)
+}
+
+// readSplit reads the config whose index, read from path, is raw: the
+// sections are read from the files the index names, relative to its
+// directory, and take the place of their names.
+func readSplit(path string, raw map[string]any) (Config, error) {
+	for _, section := range splitSections {
+		name, _ := raw[section].(string)
+		if name == "" {
+			delete(raw, section)
+			continue
+		}
+		part := filepath.Join(filepath.Dir(path), filepath.FromSlash(name))
+		data, err := os.ReadFile(part)
+		if err != nil {
+			return Config{}, fmt.Errorf("%s: %w", section, err)
+		}
+		format := DetectFormat(part, data)
+		c, ok := LookupFormat(format)
+		if !ok || c.Decode == nil {
+			return Config{}, fmt.Errorf("%s: reading %s configs is not supported", part, format)
+		}
+		var v any
+		if err = c.Decode(data, &v); err != nil {
+			return Config{}, fmt.Errorf("%s: %w", part, err)
+		}
+		raw[section] = v
+	}
+	// Every section has been read into a generic value, which JSON
+	// represents exactly.
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return Config{}, err
+	}
+	return Decode(data, FormatJSON)
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Severity tells how serious a ValidationError is.
+type Severity uint8
+
+const (
+	// SeverityError makes a config unusable.
+	SeverityError Severity = iota
+	// SeverityWarning points at a likely mistake.
+	SeverityWarning
+	// SeverityInfo is merely informative.
+	SeverityInfo
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	default:
+		return "error"
+	}
+}
+
+// ParseSeverity parses the name of a severity: error, warning or info.
+func ParseSeverity(s string) (Severity, error) {
+	switch s {
+	case "error":
+		return SeverityError, nil
+	case "warning":
+		return SeverityWarning, nil
+	case "info":
+		return SeverityInfo, nil
+	default:
+		return 0, fmt.Errorf("unknown severity: %s", s)
+	}
+}
+
+// MarshalText encodes s by its name.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes s from its name.
+func (s *Severity) UnmarshalText(text []byte) error {
+	v, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// ValidationError is a single issue found in a config, located by the path
+// of the offending field, e.g. files[2].template or global.db.port.
+type ValidationError struct {
+	Path     string   `json:"path"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", e.Path, e.Severity, e.Message)
+}
+
+// ValidationErrors is the error Load and LoadFile reject invalid configs
+// with.
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// HasErrors reports whether any of errs is an error rather than a warning
+// or an informative one.
+func (errs ValidationErrors) HasErrors() bool {
+	for _, e := range errs {
+		if e.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that cfg is structurally sound: every file has a name, a
+// path and a template, and every command a name and valid settings. Whether
+// the templates exist and the variables match them is left to the caller.
+// Every issue found is an error.
+func Validate(cfg Config) ValidationErrors {
+	var errs ValidationErrors
+	for i, f := range cfg.Files {
+		for _, field := range []struct {
+			name, value string
+		}{
+			{"name", f.Name},
+			{"path", f.Path},
+			{"template", f.Template},
+		} {
+			if field.value == "" {
+				errs = append(errs, ValidationError{Path: fmt.Sprintf("files[%d].%s", i, field.name), Message: "must not be empty"})
+			}
+		}
+		errs = append(errs, validateCommands(fmt.Sprintf("files[%d].hooks", i), f.Hooks)...)
+	}
+	errs = append(errs, validateCommands("pre_commands", cfg.PreCmds)...)
+	errs = append(errs, validateCommands("commands", cfg.Cmds)...)
+	return errs
+}
+
+func validateCommands(section string, cmds []Command) ValidationErrors {
+	var errs ValidationErrors
+	for i, c := range cmds {
+		path := fmt.Sprintf("%s[%d]", section, i)
+		if c.Name == "" {
+			errs = append(errs, ValidationError{Path: path + ".name", Message: "must not be empty"})
+		}
+		if c.Timeout != "" {
+			if d, err := time.ParseDuration(c.Timeout); err != nil || d <= 0 {
+				errs = append(errs, ValidationError{Path: path + ".timeout", Message: "invalid timeout: " + c.Timeout})
+			}
+		}
+		if c.Retries < 0 {
+			errs = append(errs, ValidationError{Path: path + ".retries", Message: "must not be negative"})
+		}
+		if !IsFailurePolicy(c.OnFailure) {
+			errs = append(errs, ValidationError{Path: path + ".on_failure", Message: fmt.Sprintf("unknown failure policy %q, expected abort, continue or warn", c.OnFailure)})
+		}
+	}
+	return errs
+}
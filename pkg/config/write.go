@@ -0,0 +1,100 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WriteFileAtomic creates or replaces the file at path with what write
+// writes. The content goes to a temporary file in the same directory, which
+// is renamed over path once write succeeded, so that path never holds a
+// partial content, not even when write fails half-way. The file is synced
+// before the rename, and the directory after it. An existing file keeps its
+// permissions; new ones are created with perm.
+func WriteFileAtomic(path string, perm fs.FileMode, write func(w io.Writer) error) (err error) {
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	if err = write(f); err != nil {
+		return err
+	}
+	if err = f.Chmod(perm); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(f.Name(), path); err != nil {
+		return err
+	}
+	syncDir(filepath.Dir(path))
+	return nil
+}
+
+// syncDir flushes the entries of dir, such as a file renamed into it, to
+// disk, as far as the system allows opening directories for it.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
+
+// backupTimeFormat stamps the names of backups, sorting them by age.
+const backupTimeFormat = "20060102-150405"
+
+// BackupFile copies the file at path next to it, as path.<time>.bak, and
+// returns the name of the copy, or "" if there is no file at path. Backups
+// made within the same second get a counter appended.
+func BackupFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	stamp := path + "." + time.Now().Format(backupTimeFormat)
+	for n := 0; ; n++ {
+		backup := stamp + ".bak"
+		if n != 0 {
+			backup = fmt.Sprintf("%s-%d.bak", stamp, n)
+		}
+		f, err := os.OpenFile(backup, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err = f.Write(data); err != nil {
+			f.Close()
+			return "", err
+		}
+		return backup, f.Close()
+	}
+}
@@ -0,0 +1,33 @@
+// Package formats manages the formats gg-config reads and writes configs in.
+// Formats registered by library consumers are picked up by the config
+// package and by the --format flag of the command alike:
+//
+//	formats.Register("json5", formats.Codec{
+//		Encode:     encodeJSON5,
+//		Decode:     decodeJSON5,
+//		Extensions: []string{".json5"},
+//	})
+package formats
+
+import "github.com/omerkaya1/gg-config/pkg/config"
+
+// Codec reads and writes configs in a format. Either function may be nil
+// for formats that can only be read or only be written.
+type Codec = config.Codec
+
+// Register makes the format called name available, replacing the codec
+// previously registered under name, such as one of the built-in json,
+// jsonc, yaml, toml and hcl ones.
+func Register(name string, c Codec) {
+	config.RegisterFormat(name, c)
+}
+
+// Lookup returns the codec registered under name.
+func Lookup(name string) (Codec, bool) {
+	return config.LookupFormat(name)
+}
+
+// Names returns the names of the registered formats, sorted.
+func Names() []string {
+	return config.Formats()
+}
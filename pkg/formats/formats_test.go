@@ -0,0 +1,97 @@
+package formats
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+// encodeNames writes the names of the global variables of a config, one per
+// line, and decodeNames reads them back as null variables.
+func encodeNames(w io.Writer, v any, _ config.EncodeOptions) error {
+	cfg, ok := v.(config.Config)
+	if !ok {
+		return fmt.Errorf("only whole configs can be written")
+	}
+	names := make([]string, 0, len(cfg.Global))
+	for k := range cfg.Global {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	_, err := io.WriteString(w, strings.Join(names, "\n")+"\n")
+	return err
+}
+
+func decodeNames(data []byte, v any) error {
+	global := make(map[string]any)
+	for _, name := range strings.Fields(string(data)) {
+		global[name] = nil
+	}
+	switch v := v.(type) {
+	case *map[string]any:
+		*v = map[string]any{"version": int64(config.Version), "global": global}
+	case *config.Config:
+		*v = config.Config{Version: config.Version, Global: global}
+	default:
+		return fmt.Errorf("cannot decode into %T", v)
+	}
+	return nil
+}
+
+func TestRegister(t *testing.T) {
+	Register("names", Codec{Encode: encodeNames, Decode: decodeNames, Extensions: []string{".names"}})
+
+	if _, ok := Lookup("names"); !ok {
+		t.Fatal("Lookup does not find the registered format")
+	}
+	if _, ok := Lookup("ini"); ok {
+		t.Error("Lookup finds an unregistered format")
+	}
+	names := Names()
+	if !sort.StringsAreSorted(names) {
+		t.Errorf("Names are not sorted: %q", names)
+	}
+	for _, want := range []string{config.FormatJSON, config.FormatJSONC, config.FormatYAML, config.FormatTOML, config.FormatHCL, "names"} {
+		if i := sort.SearchStrings(names, want); i == len(names) || names[i] != want {
+			t.Errorf("Names %q lack %s", names, want)
+		}
+	}
+
+	// The config package reads and writes the registered format.
+	cfg := config.Config{Global: map[string]any{"B": nil, "A": nil}}
+	var b bytes.Buffer
+	if err := config.Encode(&b, cfg, config.EncodeOptions{Format: "names"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "A\nB\n" {
+		t.Errorf("encoded %q, want %q", got, "A\nB\n")
+	}
+	path := filepath.Join(t.TempDir(), "config.names")
+	if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := config.DetectFormat(path, b.Bytes()); got != "names" {
+		t.Errorf("DetectFormat got %s, want names", got)
+	}
+	got, err := config.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (config.Config{Version: config.Version, Global: map[string]any{"A": nil, "B": nil}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("read %#v, want %#v", got, want)
+	}
+
+	// Registering a format again replaces it.
+	Register("names", Codec{Decode: decodeNames})
+	if config.CanEncode("names") {
+		t.Error("the replaced format can still be written")
+	}
+}
@@ -0,0 +1,149 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/omerkaya1/gg-config/internal/expr"
+	"github.com/omerkaya1/gg-config/internal/variables"
+	"github.com/omerkaya1/gg-config/pkg/config"
+	"github.com/omerkaya1/gg-config/pkg/templates"
+)
+
+// Result is what the template of a config file entry produced.
+type Result struct {
+	// Skipped is set for files skipped by their skip_if condition.
+	Skipped bool
+	Outputs []Output
+}
+
+// Execute executes the template of every file of cfg, read from fsys, by up
+// to jobs concurrent workers, at least one, and returns what they produced by
+// file, in declaration order. The variables of cfg are taken as they are, so configs
+// with references or computed variables have to be resolved first, see
+// config.Resolve. Nothing is returned unless every template executes
+// successfully.
+func Execute(cfg config.Config, fsys fs.FS, jobs int) ([]Result, error) {
+	jobs = max(1, jobs)
+	var (
+		results = make([]Result, len(cfg.Files))
+		errs    = make([]error, len(cfg.Files))
+		queue   = make(chan int)
+		wg      sync.WaitGroup
+	)
+	for w := 0; w < min(jobs, len(cfg.Files)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				r := &results[i]
+				if r.Skipped, errs[i] = skipFile(cfg, cfg.Files[i]); errs[i] == nil && !r.Skipped {
+					r.Outputs, errs[i] = executeFile(cfg, cfg.Files[i], fsys)
+				}
+			}
+		}()
+	}
+	for i := range cfg.Files {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			errs[i] = fmt.Errorf("render files[%d]: %w", i, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Output is the content generated for a single output file.
+type Output struct {
+	// Name is the slash-separated path of the file, made of the path and
+	// name of its config file entry, directory templates adding the
+	// relative paths of their files.
+	Name string
+	Data []byte
+}
+
+// executeFile executes the template of f with the globals of cfg merged with
+// the file's local variables, the latter taking precedence. Directory
+// templates produce one output per file beneath the directory, placed in
+// f.Path under their rendered relative path, and glob patterns one output per
+// matching template, named after it. Outputs are run through the file's
+// formatter.
+func executeFile(cfg config.Config, f config.File, fsys fs.FS) ([]Output, error) {
+	tfs, err := templates.Parse(fsys, f.Template)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		vars    = variables.Data(cfg.Global, f.Local)
+		outputs = make([]Output, 0, len(tfs))
+	)
+	for _, tf := range tfs {
+		target := f.Target()
+		switch {
+		case tf.Match != "":
+			target = filepath.Join(filepath.FromSlash(f.Path), templates.GlobName(f.Name, tf.Match))
+		case tf.Path != nil:
+			rel, err := executeTemplate(tf.Path, vars)
+			if err != nil {
+				return nil, err
+			}
+			if target, err = treeTarget(filepath.FromSlash(f.Path), string(rel)); err != nil {
+				return nil, fmt.Errorf("%s: %w", tf.Path.Name(), err)
+			}
+		}
+		data, err := executeTemplate(tf.Content, vars)
+		if err != nil {
+			return nil, err
+		}
+		if data, err = formatOutput(f.Format, target, data); err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, Output{filepath.ToSlash(target), data})
+	}
+	return outputs, nil
+}
+
+func executeTemplate(t *template.Template, vars map[string]any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := t.Option("missingkey=error").Execute(&buf, vars); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// treeTarget joins dir with the rendered relative path of a file of a
+// directory template, refusing paths leaving dir.
+func treeTarget(dir, rel string) (string, error) {
+	for _, segment := range strings.Split(rel, "/") {
+		if segment == "" || segment == ".." {
+			return "", fmt.Errorf("invalid rendered path %q", rel)
+		}
+	}
+	return filepath.Join(dir, filepath.FromSlash(rel)), nil
+}
+
+// skipFile evaluates the skip_if condition of f, if any, against the globals
+// of cfg merged with the local variables of f.
+func skipFile(cfg config.Config, f config.File) (bool, error) {
+	if f.SkipIf == "" {
+		return false, nil
+	}
+	c, err := expr.Parse("condition", f.SkipIf)
+	if err != nil {
+		return false, err
+	}
+	return c.Holds(variables.Data(cfg.Global, f.Local))
+}
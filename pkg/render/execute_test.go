@@ -0,0 +1,153 @@
+package render
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+func TestExecuteJobs(t *testing.T) {
+	fsys := fstest.MapFS{"greeting.tmpl": {Data: []byte("Hello, {{ .Name }}!")}}
+	cfg := config.Config{
+		Global: map[string]any{"Name": "gopher"},
+		Files: []config.File{
+			{Name: "a.txt", Path: "out", Template: "greeting.tmpl"},
+			{Name: "b.txt", Path: "out", Template: "greeting.tmpl", Local: map[string]any{"Name": "world"}},
+		},
+	}
+	for _, jobs := range []int{-1, 0, 1, 2, 8} {
+		done := make(chan struct{})
+		var (
+			results []Result
+			err     error
+		)
+		go func() {
+			defer close(done)
+			results, err = Execute(cfg, fsys, jobs)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("jobs %d: Execute did not return", jobs)
+		}
+		if err != nil {
+			t.Fatalf("jobs %d: %v", jobs, err)
+		}
+		want := []string{"Hello, gopher!", "Hello, world!"}
+		if len(results) != len(want) {
+			t.Fatalf("jobs %d: got %d results, want %d", jobs, len(results), len(want))
+		}
+		for i, r := range results {
+			if len(r.Outputs) != 1 || string(r.Outputs[0].Data) != want[i] {
+				t.Errorf("jobs %d: files[%d] produced %+v, want %q", jobs, i, r.Outputs, want[i])
+			}
+		}
+	}
+}
+
+func TestExecute(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.tmpl":              {Data: []byte("package {{ .Pkg }}\nfunc  main() {}\n")},
+		"data.tmpl":              {Data: []byte(`{"name":"{{ .Name }}"}`)},
+		"svc/{{.Name}}.go":       {Data: []byte("// {{ .Name }}")},
+		"svc/README.md":          {Data: []byte("# {{ .Name }}")},
+		"handlers/user.tmpl":     {Data: []byte("user {{ .Name }}")},
+		"handlers/order.tmpl":    {Data: []byte("order {{ .Name }}")},
+		"escape/{{.Name}}/x.txt": {Data: []byte("")},
+		"missing.tmpl":           {Data: []byte("{{ .Nope }}")},
+	}
+	global := map[string]any{"Name": "demo", "Pkg": "main"}
+	tests := []struct {
+		name    string
+		file    config.File
+		global  map[string]any
+		want    Result
+		wantErr string
+	}{
+		{
+			name: "single",
+			file: config.File{Name: "main.go", Path: "cmd", Template: "main.tmpl", Local: map[string]any{"Pkg": "cli"}},
+			want: Result{Outputs: []Output{{"cmd/main.go", []byte("package cli\nfunc  main() {}\n")}}},
+		},
+		{
+			name: "gofmt",
+			file: config.File{Name: "main.go", Path: "cmd", Template: "main.tmpl", Format: config.FormatterGofmt},
+			want: Result{Outputs: []Output{{"cmd/main.go", []byte("package main\n\nfunc main() {}\n")}}},
+		},
+		{
+			name: "auto",
+			file: config.File{Name: "data.json", Path: ".", Template: "data.tmpl", Format: config.FormatterAuto},
+			want: Result{Outputs: []Output{{"data.json", []byte("{\n  \"name\": \"demo\"\n}\n")}}},
+		},
+		{
+			name: "auto unknown extension",
+			file: config.File{Name: "data.txt", Path: ".", Template: "data.tmpl", Format: config.FormatterAuto},
+			want: Result{Outputs: []Output{{"data.txt", []byte(`{"name":"demo"}`)}}},
+		},
+		{
+			name: "directory",
+			file: config.File{Name: "ignored", Path: "out", Template: "svc"},
+			want: Result{Outputs: []Output{{"out/README.md", []byte("# demo")}, {"out/demo.go", []byte("// demo")}}},
+		},
+		{
+			name: "glob",
+			file: config.File{Name: "*_handler.go", Path: "h", Template: "handlers/*.tmpl"},
+			want: Result{Outputs: []Output{{"h/order_handler.go", []byte("order demo")}, {"h/user_handler.go", []byte("user demo")}}},
+		},
+		{
+			name: "skipped",
+			file: config.File{Name: "a", Path: ".", Template: "missing.tmpl", SkipIf: `Name == "demo"`},
+			want: Result{Skipped: true},
+		},
+		{
+			name: "not skipped",
+			file: config.File{Name: "a", Path: ".", Template: "data.tmpl", SkipIf: `Name != "demo" || Skip == true`},
+			want: Result{Outputs: []Output{{"a", []byte(`{"name":"demo"}`)}}},
+		},
+		{
+			name:    "missing variable",
+			file:    config.File{Name: "a", Path: ".", Template: "missing.tmpl"},
+			wantErr: `render files[0]: template: missing.tmpl:1:3: executing "missing.tmpl" at <.Nope>: map has no entry for key "Nope"`,
+		},
+		{
+			name:    "invalid condition",
+			file:    config.File{Name: "a", Path: ".", Template: "data.tmpl", SkipIf: "Name =="},
+			wantErr: `render files[0]: condition "Name ==": unexpected end of expression`,
+		},
+		{
+			name:    "path escaping",
+			file:    config.File{Name: "a", Path: "out", Template: "escape"},
+			global:  map[string]any{"Name": ".."},
+			wantErr: `render files[0]: escape:{{.Name}}/x.txt: invalid rendered path "../x.txt"`,
+		},
+		{
+			name:    "unformattable",
+			file:    config.File{Name: "data.json", Path: ".", Template: "main.tmpl", Format: config.FormatterJSON},
+			wantErr: "render files[0]: json data.json: invalid character 'p' looking for beginning of value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Config{Global: global, Files: []config.File{tt.file}}
+			if tt.global != nil {
+				cfg.Global = tt.global
+			}
+			got, err := Execute(cfg, fsys, 1)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != 1 || !reflect.DeepEqual(got[0], tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
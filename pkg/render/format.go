@@ -0,0 +1,71 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	gofmt "go/format"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+// formatters post-process rendered files before they are written.
+var formatters = map[string]func(data []byte) ([]byte, error){
+	config.FormatterGofmt:     gofmt.Source,
+	config.FormatterGoimports: runGoimports,
+	config.FormatterJSON:      indentJSON,
+}
+
+// autoFormatters picks the formatter of an output file by its extension when
+// the file's format is auto.
+var autoFormatters = map[string]string{
+	".go":   config.FormatterGofmt,
+	".json": config.FormatterJSON,
+}
+
+// formatOutput applies the formatter called name to the content rendered for
+// target.
+func formatOutput(name, target string, data []byte) ([]byte, error) {
+	if name == config.FormatterAuto {
+		name = autoFormatters[strings.ToLower(filepath.Ext(target))]
+	}
+	if name == "" || name == config.FormatterNone {
+		return data, nil
+	}
+	f, ok := formatters[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown formatter %q", name)
+	}
+	out, err := f(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", name, target, err)
+	}
+	return out, nil
+}
+
+func indentJSON(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", strings.Repeat(" ", config.DefaultIndent)); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// runGoimports pipes data through the goimports command, which has to be
+// installed separately.
+func runGoimports(data []byte) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("goimports")
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = bytes.NewReader(data), &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,125 @@
+// Package render generates the files described by a gg-config config out of
+// their templates, as the render subcommand does, so that the pipeline can
+// be embedded in other code generation tools:
+//
+//	out := make(render.Map)
+//	err := render.Run(cfg, os.DirFS("templates"), render.Options{Sink: out})
+//
+// Unlike the subcommand, Run never runs commands: pre-commands, file hooks
+// and post-commands are left to the caller, and the variables captured by
+// pre-commands are empty. Execute runs the template stage alone, for callers
+// handling the outputs themselves, as the subcommand does.
+package render
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/omerkaya1/gg-config/internal/variables"
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+// Sink receives the rendered files.
+type Sink interface {
+	// WriteFile stores data as the file called name, a slash-separated
+	// path made of the path and name of a config file entry, directory
+	// templates adding the relative paths of their files.
+	WriteFile(name string, data []byte) error
+}
+
+// Options configures Run.
+type Options struct {
+	// Sink receives the rendered files.
+	Sink Sink
+	// Jobs is the number of templates executed concurrently, the number
+	// of CPUs if not positive.
+	Jobs int
+	// Environment, if set, names the environment of the config whose
+	// globals override the ones of the config.
+	Environment string
+	// AllowEmptyFiles accepts configs without files, which render nothing.
+	AllowEmptyFiles bool
+}
+
+// Run checks cfg against the templates of fsys, executes the template of
+// every file not skipped by its skip_if condition and hands the outputs to
+// opts.Sink in declaration order. Nothing reaches the sink unless every
+// template executes successfully; an invalid config is reported as a
+// config.ValidationErrors error. Only the checks of config.Check are run:
+// whether the files can be written is up to the sink.
+func Run(cfg config.Config, fsys fs.FS, opts Options) error {
+	if opts.Sink == nil {
+		return errors.New("render: no sink")
+	}
+	if opts.Jobs < 1 {
+		opts.Jobs = runtime.NumCPU()
+	}
+	if opts.Environment != "" {
+		var err error
+		if cfg, err = config.WithEnvironment(cfg, opts.Environment); err != nil {
+			return fmt.Errorf("render: %w", err)
+		}
+	}
+	captured := make(map[string]string)
+	for _, c := range cfg.PreCmds {
+		if c.Capture != "" {
+			captured[c.Capture] = ""
+		}
+	}
+	cfg.Global = variables.WithCaptured(cfg.Global, captured)
+	var errs config.ValidationErrors
+	for _, p := range config.Check(cfg, config.CheckOptions{Templates: fsys, AllowEmptyFiles: opts.AllowEmptyFiles}) {
+		if p.Severity == config.SeverityError {
+			errs = append(errs, p)
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("render: %w", errs)
+	}
+	cfg, err := config.Resolve(cfg)
+	if err != nil {
+		return fmt.Errorf("render: %w", err)
+	}
+	results, err := Execute(cfg, fsys, opts.Jobs)
+	if err != nil {
+		return err
+	}
+	for i, r := range results {
+		for _, out := range r.Outputs {
+			if err := opts.Sink.WriteFile(out.Name, out.Data); err != nil {
+				return fmt.Errorf("render files[%d]: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Map is a Sink keeping the rendered files in memory, by name.
+type Map map[string][]byte
+
+// WriteFile implements Sink.
+func (m Map) WriteFile(name string, data []byte) error {
+	m[name] = data
+	return nil
+}
+
+// Dir is a Sink writing the rendered files beneath a directory, creating
+// missing directories on the way and replacing existing files atomically.
+type Dir string
+
+// WriteFile implements Sink.
+func (d Dir) WriteFile(name string, data []byte) error {
+	path := filepath.Join(string(d), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return config.WriteFileAtomic(path, 0o644, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
@@ -0,0 +1,140 @@
+package render
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+func TestRun(t *testing.T) {
+	fsys := fstest.MapFS{
+		"greeting.tmpl": {Data: []byte("{{ .Greeting }}, {{ .Name }}! ({{ .Rev }})")},
+		"port.tmpl":     {Data: []byte("{{ .Port }}")},
+	}
+	cfg := config.Config{
+		Global:       map[string]any{"Name": "gopher", "Port": int64(80)},
+		Computed:     map[string]string{"Greeting": `"Hello"`},
+		Environments: map[string]config.Environment{"prod": {Global: map[string]any{"Port": int64(443)}}},
+		Files: []config.File{
+			{Name: "hello.txt", Path: "out", Template: "greeting.tmpl", Local: map[string]any{"Name": "${Name}s"}},
+			{Name: "port", Path: ".", Template: "port.tmpl"},
+			{Name: "skipped", Path: ".", Template: "port.tmpl", SkipIf: "Port > 0"},
+		},
+		PreCmds: []config.Command{{Name: "git", Args: []string{"rev-parse", "HEAD"}, Capture: "Rev"}},
+	}
+	tests := []struct {
+		name string
+		opts Options
+		want Map
+	}{
+		{
+			name: "default",
+			want: Map{"out/hello.txt": []byte("Hello, gophers! ()"), "port": []byte("80")},
+		},
+		{
+			name: "environment",
+			opts: Options{Environment: "prod", Jobs: 2},
+			want: Map{"out/hello.txt": []byte("Hello, gophers! ()"), "port": []byte("443")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(Map)
+			tt.opts.Sink = got
+			if err := Run(cfg, fsys, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	fsys := fstest.MapFS{"a.tmpl": {Data: []byte("{{ .Name }}")}}
+	valid := config.Config{
+		Global: map[string]any{"Name": "gopher"},
+		Files:  []config.File{{Name: "a", Path: ".", Template: "a.tmpl"}},
+	}
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		opts    Options
+		wantErr string
+	}{
+		{name: "no sink", cfg: valid, wantErr: "render: no sink"},
+		{
+			name:    "unknown environment",
+			cfg:     valid,
+			opts:    Options{Sink: make(Map), Environment: "prod"},
+			wantErr: `render: unknown environment "prod": the config defines none`,
+		},
+		{
+			name:    "no files",
+			cfg:     config.Config{Global: valid.Global},
+			opts:    Options{Sink: make(Map)},
+			wantErr: "render: files: error: at least one file is required",
+		},
+		{
+			name: "invalid",
+			cfg: config.Config{
+				Global: valid.Global,
+				Files:  []config.File{{Name: "a", Path: ".", Template: "b.tmpl"}},
+			},
+			opts:    Options{Sink: make(Map)},
+			wantErr: "render: files[0].template: error: open b.tmpl: file does not exist",
+		},
+		{
+			name:    "sink",
+			cfg:     valid,
+			opts:    Options{Sink: failingSink{}},
+			wantErr: "render files[0]: disk full",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Run(tt.cfg, fsys, tt.opts)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	var problems config.ValidationErrors
+	if err := Run(config.Config{Global: valid.Global}, fsys, Options{Sink: make(Map)}); !errors.As(err, &problems) {
+		t.Errorf("got error %v, want config.ValidationErrors", err)
+	}
+	if err := Run(config.Config{Global: valid.Global}, fsys, Options{Sink: make(Map), AllowEmptyFiles: true}); err != nil {
+		t.Errorf("got error %v with empty files allowed", err)
+	}
+}
+
+type failingSink struct{}
+
+func (failingSink) WriteFile(string, []byte) error {
+	return errors.New("disk full")
+}
+
+func TestDir(t *testing.T) {
+	dir := t.TempDir()
+	sink := Dir(dir)
+	if err := sink.WriteFile("out/nested/a.txt", []byte("first")); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.WriteFile("out/nested/a.txt", []byte("second")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "out", "nested", "a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "second" {
+		t.Errorf("got %q, want second", data)
+	}
+}
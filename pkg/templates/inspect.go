@@ -0,0 +1,283 @@
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"sort"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// Type hints of variables. A variable ranged over is a list, one whose
+// fields are accessed a map, one passed to toTime or toDuration a time or a
+// duration, and one compared with a literal of the type of the literal.
+const (
+	TypeAny      = "any"
+	TypeBool     = "bool"
+	TypeNumber   = "number"
+	TypeString   = "string"
+	TypeList     = "list"
+	TypeMap      = "map"
+	TypeTime     = "time"
+	TypeDuration = "duration"
+)
+
+// Variable is a top-level variable a template refers to, i.e. the X of
+// {{ .X }} or {{ $.X }}.
+type Variable struct {
+	Name string `json:"name"`
+	// Type is one of the Type constants, TypeAny when the template does
+	// not reveal more about the value.
+	Type string `json:"type"`
+}
+
+// Inspect parses the template called name from fsys and returns the
+// variables it refers to, sorted by name. Like the template of a file, name
+// can be a single file, a glob pattern or a directory, whose every file and
+// relative path is a template; "." inspects all templates of fsys.
+func Inspect(fsys fs.FS, name string) ([]Variable, error) {
+	files, err := Parse(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("inspect template %s: %w", name, err)
+	}
+	types := Types(files)
+	names := make([]string, 0, len(types))
+	for k := range types {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	vars := make([]Variable, len(names))
+	for i, k := range names {
+		vars[i] = Variable{Name: k, Type: types[k]}
+	}
+	return vars, nil
+}
+
+// Types returns the top-level variables referenced by the contents and
+// paths of files along with their types. Fields accessed inside range and
+// with blocks, where the dot is rebound, are not considered.
+func Types(files []File) map[string]string {
+	c := collector{types: make(map[string]string)}
+	for _, f := range files {
+		for _, t := range []*template.Template{f.Path, f.Content} {
+			if t != nil {
+				c.template(t)
+			}
+		}
+	}
+	return c.types
+}
+
+// typeFuncs are the template functions revealing the type of the value
+// they are given.
+var typeFuncs = map[string]string{
+	"toTime":     TypeTime,
+	"toDuration": TypeDuration,
+}
+
+// comparisonFuncs are the template functions whose arguments share the type
+// of the literals they are compared with.
+var comparisonFuncs = map[string]bool{"eq": true, "ne": true, "lt": true, "le": true, "gt": true, "ge": true}
+
+// collector gathers the variables referenced by templates, hinting at their
+// types from the way they are used: ranged over, accessed fields of, passed
+// to toTime or toDuration, or compared with literals.
+type collector struct {
+	types map[string]string
+}
+
+// hint records name with typ, which replaces TypeAny but not another type,
+// the first use revealing the type winning.
+func (c *collector) hint(name, typ string) {
+	if old, ok := c.types[name]; ok && old != TypeAny {
+		return
+	}
+	c.types[name] = typ
+}
+
+func (c *collector) template(t *template.Template) {
+	for _, tt := range t.Templates() {
+		if tt.Tree != nil {
+			c.collect(tt.Tree.Root, true)
+		}
+	}
+}
+
+// variable returns the name of the top-level variable node stands for
+// itself, as opposed to one of its fields.
+func variable(node parse.Node, topLevel bool) (string, bool) {
+	switch n := node.(type) {
+	case *parse.FieldNode:
+		if topLevel && len(n.Ident) == 1 {
+			return n.Ident[0], true
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) == 2 && n.Ident[0] == "$" {
+			return n.Ident[1], true
+		}
+	}
+	return "", false
+}
+
+// pipeVariable returns the variable a pipeline consists of, if any.
+func pipeVariable(pipe *parse.PipeNode, topLevel bool) (string, bool) {
+	if pipe == nil || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return "", false
+	}
+	return variable(pipe.Cmds[0].Args[0], topLevel)
+}
+
+// funcName returns the name of the function cmd calls, if any.
+func funcName(cmd *parse.CommandNode) string {
+	if len(cmd.Args) == 0 {
+		return ""
+	}
+	if id, ok := cmd.Args[0].(*parse.IdentifierNode); ok {
+		return id.Ident
+	}
+	return ""
+}
+
+// hintArgs hints at the types of the variables passed to the function cmd
+// calls.
+func (c *collector) hintArgs(cmd *parse.CommandNode, topLevel bool) {
+	fn := funcName(cmd)
+	typ, ok := typeFuncs[fn]
+	if !ok && comparisonFuncs[fn] {
+		for _, a := range cmd.Args[1:] {
+			switch a.(type) {
+			case *parse.StringNode:
+				typ, ok = TypeString, true
+			case *parse.NumberNode:
+				typ, ok = TypeNumber, true
+			case *parse.BoolNode:
+				typ, ok = TypeBool, true
+			}
+		}
+	}
+	if !ok {
+		return
+	}
+	for _, a := range cmd.Args[1:] {
+		if name, ok := variable(a, topLevel); ok {
+			c.hint(name, typ)
+		}
+	}
+}
+
+func (c *collector) collect(node parse.Node, topLevel bool) {
+	switch n := node.(type) {
+	case nil:
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, e := range n.Nodes {
+			c.collect(e, topLevel)
+		}
+	case *parse.ActionNode:
+		c.collect(n.Pipe, topLevel)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for i, cmd := range n.Cmds {
+			c.collect(cmd, topLevel)
+			// {{ .X | toTime }} passes .X to toTime as its last argument.
+			if typ, ok := typeFuncs[funcName(cmd)]; ok && i > 0 && len(n.Cmds[i-1].Args) == 1 {
+				if name, ok := variable(n.Cmds[i-1].Args[0], topLevel); ok {
+					c.hint(name, typ)
+				}
+			}
+		}
+	case *parse.CommandNode:
+		for _, a := range n.Args {
+			c.collect(a, topLevel)
+		}
+		c.hintArgs(n, topLevel)
+	case *parse.ChainNode:
+		c.collect(n.Node, topLevel)
+	case *parse.FieldNode:
+		if topLevel && len(n.Ident) != 0 {
+			c.hint(n.Ident[0], nestedType(len(n.Ident) > 1))
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			c.hint(n.Ident[1], nestedType(len(n.Ident) > 2))
+		}
+	case *parse.IfNode:
+		c.collect(n.Pipe, topLevel)
+		c.collect(n.List, topLevel)
+		c.collect(n.ElseList, topLevel)
+	case *parse.RangeNode:
+		c.collect(n.Pipe, topLevel)
+		if name, ok := pipeVariable(n.Pipe, topLevel); ok {
+			c.hint(name, TypeList)
+		}
+		c.collect(n.List, false)
+		c.collect(n.ElseList, topLevel)
+	case *parse.WithNode:
+		c.collect(n.Pipe, topLevel)
+		c.collect(n.List, false)
+		c.collect(n.ElseList, topLevel)
+	case *parse.TemplateNode:
+		c.collect(n.Pipe, topLevel)
+	}
+}
+
+// nestedType hints at a map for variables whose fields are accessed.
+func nestedType(nested bool) string {
+	if nested {
+		return TypeMap
+	}
+	return TypeAny
+}
+
+// Matches reports whether v can be used the way a template using it as a
+// typ does. Null values match any type.
+func Matches(v any, typ string) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		switch typ {
+		case TypeTime:
+			_, err := time.Parse(time.RFC3339, val)
+			return err == nil
+		case TypeDuration:
+			_, err := time.ParseDuration(val)
+			return err == nil
+		}
+	}
+	switch typ {
+	case TypeAny:
+		return true
+	case TypeList:
+		return TypeOf(v) == TypeList || TypeOf(v) == TypeMap
+	default:
+		return TypeOf(v) == typ
+	}
+}
+
+// TypeOf names the type of the value of a variable after the Type
+// constants.
+func TypeOf(v any) string {
+	switch v.(type) {
+	case bool:
+		return TypeBool
+	case int64, float64, json.Number:
+		return TypeNumber
+	case string:
+		return TypeString
+	case []any:
+		return TypeList
+	case map[string]any:
+		return TypeMap
+	case time.Time:
+		return TypeTime
+	default:
+		return TypeAny
+	}
+}
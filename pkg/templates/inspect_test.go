@@ -0,0 +1,100 @@
+package templates
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestInspect(t *testing.T) {
+	fsys := fstest.MapFS{
+		"types.tmpl": {Data: []byte(`
+{{ .Plain }}
+{{ range .Items }}{{ .Ignored }}{{ end }}
+{{ with .Owner }}{{ .Ignored }}{{ end }}
+{{ .db.host }}
+{{ toTime .Started }} {{ .Timeout | toDuration }}
+{{ if eq .Env "prod" }}{{ end }}{{ if gt .Replicas 1 }}{{ end }}{{ if ne .Debug true }}{{ end }}
+{{ range $i, $e := .Ports }}{{ $.Inner }}{{ end }}
+{{ eq .Plain "x" }}
+`)},
+		"svc/{{.Name}}.go": {Data: []byte("{{ .Pkg }}")},
+		"broken.tmpl":      {Data: []byte("{{ .X ")},
+	}
+	tests := []struct {
+		name    string
+		want    []Variable
+		wantErr string
+	}{
+		{
+			name: "types.tmpl",
+			want: []Variable{
+				{"Debug", TypeBool},
+				{"Env", TypeString},
+				{"Inner", TypeAny},
+				{"Items", TypeList},
+				{"Owner", TypeAny},
+				{"Plain", TypeString},
+				{"Ports", TypeList},
+				{"Replicas", TypeNumber},
+				{"Started", TypeTime},
+				{"Timeout", TypeDuration},
+				{"db", TypeMap},
+			},
+		},
+		{name: "svc", want: []Variable{{"Name", TypeAny}, {"Pkg", TypeAny}}},
+		{name: "broken.tmpl", wantErr: "inspect template broken.tmpl: template: broken.tmpl:1: unclosed action"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Inspect(fsys, tt.name)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		v    any
+		typ  string
+		want bool
+	}{
+		{nil, TypeList, true},
+		{"x", TypeAny, true},
+		{"x", TypeString, true},
+		{"x", TypeNumber, false},
+		{int64(1), TypeNumber, true},
+		{1.5, TypeNumber, true},
+		{json.Number("1.50"), TypeNumber, true},
+		{true, TypeBool, true},
+		{"true", TypeBool, false},
+		{[]any{1}, TypeList, true},
+		{map[string]any{}, TypeList, true},
+		{"abc", TypeList, false},
+		{map[string]any{}, TypeMap, true},
+		{"2024-01-02T03:04:05Z", TypeTime, true},
+		{"yesterday", TypeTime, false},
+		{time.Now(), TypeTime, true},
+		{"1m30s", TypeDuration, true},
+		{"soon", TypeDuration, false},
+		{int64(5), TypeDuration, false},
+	}
+	for _, tt := range tests {
+		if got := Matches(tt.v, tt.typ); got != tt.want {
+			t.Errorf("Matches(%#v, %s) = %v, want %v", tt.v, tt.typ, got, tt.want)
+		}
+	}
+}
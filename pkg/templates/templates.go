@@ -0,0 +1,220 @@
+// Package templates reads gg-config templates and inspects them, reporting
+// the variables they refer to along with hints of their types inferred from
+// how the templates use them:
+//
+//	vars, err := templates.Inspect(os.DirFS("templates"), "service")
+//	for _, v := range vars {
+//		fmt.Println(v.Name, v.Type)
+//	}
+//
+// The template of a config file entry can be a single file, a glob pattern
+// matching several files or a directory, in which case every file beneath
+// it, and its path relative to the directory, is a template.
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// funcs convert the values of time and duration variables, stored as strings
+// in most formats, back to their types.
+var funcs = template.FuncMap{
+	"toTime": func(v any) (time.Time, error) {
+		switch val := v.(type) {
+		case time.Time:
+			return val, nil
+		case string:
+			return time.Parse(time.RFC3339, val)
+		default:
+			return time.Time{}, fmt.Errorf("cannot convert %v to a time", v)
+		}
+	},
+	"toDuration": func(v any) (time.Duration, error) {
+		s, ok := v.(string)
+		if !ok {
+			return 0, fmt.Errorf("cannot convert %v to a duration", v)
+		}
+		return time.ParseDuration(s)
+	},
+}
+
+// New returns a template called name knowing the functions templates may
+// call, toTime and toDuration, for text such as the arguments of commands
+// to be parsed into.
+func New(name string) *template.Template {
+	return template.New(name).Funcs(funcs)
+}
+
+// File is one of the files a template consists of.
+type File struct {
+	// Path is the location of the output file relative to the path of the
+	// file entry, itself a template. It is nil for single-file templates,
+	// which are written under the name of the entry instead.
+	Path *template.Template
+	// Match is the name of the template matched by a glob pattern.
+	Match   string
+	Content *template.Template
+}
+
+// Parse parses the template called name from fsys: a single file, a glob
+// pattern or a directory of templates. Parse errors carry the name of the
+// template and the offending line.
+func Parse(fsys fs.FS, name string) ([]File, error) {
+	if IsGlob(name) {
+		return parseGlob(fsys, name)
+	}
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		t, err := parseFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		return []File{{Content: t}}, nil
+	}
+
+	rels, err := List(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("list templates in %s: %w", name, err)
+	}
+	root, err := fs.Sub(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]File, 0, len(rels))
+	for _, rel := range rels {
+		p, err := New(name + ":" + rel).Parse(rel)
+		if err != nil {
+			return nil, err
+		}
+		t, err := parseFile(root, rel)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, File{Path: p, Content: t})
+	}
+	return files, nil
+}
+
+// parseFile reads the template called name from fsys and parses it.
+func parseFile(fsys fs.FS, name string) (*template.Template, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return New(name).Parse(string(data))
+}
+
+func parseGlob(fsys fs.FS, pattern string) ([]File, error) {
+	matches, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("template pattern %q: %w", pattern, err)
+	}
+	var files []File
+	for _, m := range matches {
+		if info, err := fs.Stat(fsys, m); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		t, err := parseFile(fsys, m)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, File{Match: m, Content: t})
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("template pattern %q matches no files", pattern)
+	}
+	return files, nil
+}
+
+// IsGlob reports whether a template name is a pattern standing for several
+// templates.
+func IsGlob(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// GlobName returns the name of the output file generated out of the
+// template match of a glob pattern: every * in name is replaced by the base
+// name of the template without its extension, which is also the name if
+// name holds no *. E.g. handlers/user.tmpl names "*_handler.go"
+// user_handler.go.
+func GlobName(name, match string) string {
+	base := path.Base(match)
+	stem := strings.TrimSuffix(base, path.Ext(base))
+	if !strings.Contains(name, "*") {
+		return stem
+	}
+	return strings.ReplaceAll(name, "*", stem)
+}
+
+// List returns the paths of all regular files beneath root in fsys,
+// relative to root, sorted.
+func List(fsys fs.FS, root string) ([]string, error) {
+	var names []string
+	err := fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if root != "." {
+			name = strings.TrimPrefix(name, root+"/")
+		}
+		names = append(names, name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, errors.New("no templates found")
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Names returns the names of the templates of fsys, sorted: its files along
+// with the directories containing them, which are directory templates.
+func Names(fsys fs.FS) ([]string, error) {
+	names, err := List(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, name := range names {
+		for d := path.Dir(name); d != "." && !seen[d]; d = path.Dir(d) {
+			seen[d] = true
+			names = append(names, d)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Match reports whether name is one of names, which are sorted, or a glob
+// pattern matching at least one of them.
+func Match(names []string, name string) bool {
+	i := sort.SearchStrings(names, name)
+	if i < len(names) && names[i] == name {
+		return true
+	}
+	if !IsGlob(name) {
+		return false
+	}
+	for _, n := range names {
+		if ok, _ := path.Match(name, n); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package templates
+
+import (
+	"bytes"
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var testFS = fstest.MapFS{
+	"main.tmpl":               {Data: []byte("package {{ .Pkg }}")},
+	"broken.tmpl":             {Data: []byte("line one\n{{ if .X }}")},
+	"handlers/user.tmpl":      {Data: []byte("user")},
+	"handlers/order.tmpl":     {Data: []byte("order")},
+	"handlers/README":         {Data: []byte("readme")},
+	"svc/{{.Name}}/main.go":   {Data: []byte("package {{ .Name }}")},
+	"svc/config/default.yaml": {Data: []byte("name: {{ .Name }}")},
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    []string
+		wantErr string
+	}{
+		{name: "main.tmpl", want: []string{"|main.tmpl"}},
+		{name: "svc", want: []string{"svc:config/default.yaml|config/default.yaml", "svc:{{.Name}}/main.go|{{.Name}}/main.go"}},
+		{name: "handlers/*.tmpl", want: []string{"handlers/order.tmpl|handlers/order.tmpl", "handlers/user.tmpl|handlers/user.tmpl"}},
+		{name: "broken.tmpl", wantErr: "template: broken.tmpl:2: unexpected EOF"},
+		{name: "nope.tmpl", wantErr: "open nope.tmpl: file does not exist"},
+		{name: "*.go", wantErr: `template pattern "*.go" matches no files`},
+		{name: "[", wantErr: `template pattern "[": syntax error in pattern`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := Parse(testFS, tt.name)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// Each file is described as its path template, or the
+			// template it matched, and its content template.
+			var got []string
+			for _, f := range files {
+				name := f.Match
+				if f.Path != nil {
+					name = f.Path.Name()
+				}
+				got = append(got, name+"|"+f.Content.Name())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got files %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDirectoryPaths(t *testing.T) {
+	files, err := Parse(testFS, "svc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := files[1].Path.Execute(&b, map[string]any{"Name": "billing"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "billing/main.go" {
+		t.Errorf("got path %q, want billing/main.go", got)
+	}
+}
+
+func TestNewFuncs(t *testing.T) {
+	tmpl, err := New("t").Parse(`{{ (toTime .T).Year }} {{ (toDuration .D).Minutes }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, map[string]any{"T": "2024-05-06T07:08:09Z", "D": "90s"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "2024 1.5" {
+		t.Errorf("got %q, want %q", got, "2024 1.5")
+	}
+	b.Reset()
+	if err := tmpl.Execute(&b, map[string]any{"T": time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC), "D": 5}); err == nil {
+		t.Error("converting a number to a duration succeeded")
+	}
+}
+
+func TestGlobName(t *testing.T) {
+	tests := []struct {
+		name, match, want string
+	}{
+		{"*_handler.go", "handlers/user.tmpl", "user_handler.go"},
+		{"*.go", "a/b/model.go.tmpl", "model.go.go"},
+		{"*-*", "x.tmpl", "x-x"},
+		{"ignored", "handlers/order.tmpl", "order"},
+		{"*", "README", "README"},
+	}
+	for _, tt := range tests {
+		if got := GlobName(tt.name, tt.match); got != tt.want {
+			t.Errorf("GlobName(%q, %q) = %q, want %q", tt.name, tt.match, got, tt.want)
+		}
+	}
+}
+
+func TestListAndNames(t *testing.T) {
+	list, err := List(testFS, "handlers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"README", "order.tmpl", "user.tmpl"}; !reflect.DeepEqual(list, want) {
+		t.Errorf("List got %q, want %q", list, want)
+	}
+	if _, err := List(fstest.MapFS{"empty": {Mode: fs.ModeDir | 0o755}}, "empty"); err == nil || err.Error() != "no templates found" {
+		t.Errorf("List of an empty directory got error %v", err)
+	}
+
+	names, err := Names(testFS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"broken.tmpl", "handlers", "handlers/README", "handlers/order.tmpl", "handlers/user.tmpl", "main.tmpl",
+		"svc", "svc/config", "svc/config/default.yaml", "svc/{{.Name}}", "svc/{{.Name}}/main.go",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Names got %q, want %q", names, want)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	names := []string{"handlers", "handlers/order.tmpl", "handlers/user.tmpl", "main.tmpl"}
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"main.tmpl", true},
+		{"handlers", true},
+		{"handlers/*.tmpl", true},
+		{"handlers/u?er.tmpl", true},
+		{"*.go", false},
+		{"other.tmpl", false},
+		{"handlers/", false},
+	}
+	for _, tt := range tests {
+		if got := Match(names, tt.name); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// Package validate runs the checks of the gg-config validate subcommand that
+// depend on the config and its templates alone: besides the structural ones
+// of config.Validate, it checks variable names, expressions, conditions,
+// commands and the portability of file paths and, given the templates,
+// whether the variables they refer to are provided and the ones provided are
+// used. Unlike the subcommand, it neither probes the directories files are
+// written to nor looks the commands up in PATH.
+package validate
+
+import (
+	"os"
+
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+// Options configures Config.
+type Options struct {
+	// TemplatesDir, if set, is where the templates of files are looked up
+	// and checked against the variables.
+	TemplatesDir string
+	// Strict turns every warning into an error.
+	Strict bool
+	// TargetOS is the system, as in GOOS, the paths of files are checked
+	// for, the current one if empty.
+	TargetOS string
+	// AllowEmptyFiles accepts configs without files.
+	AllowEmptyFiles bool
+	// Keys are the rules the names of variables are checked against, the
+	// default ones if zero.
+	Keys config.KeyRules
+}
+
+// Config checks cfg and returns every problem found, errors as well as
+// warnings and informative ones, located by the path of the offending field,
+// e.g. files[2].template.
+func Config(cfg config.Config, opts Options) config.ValidationErrors {
+	copts := config.CheckOptions{Strict: opts.Strict, TargetOS: opts.TargetOS, AllowEmptyFiles: opts.AllowEmptyFiles, Keys: opts.Keys}
+	if opts.TemplatesDir != "" {
+		copts.Templates = os.DirFS(opts.TemplatesDir)
+	}
+	return config.Check(cfg, copts)
+}
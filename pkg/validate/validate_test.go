@@ -0,0 +1,93 @@
+package validate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+func TestConfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.tmpl"), []byte("package {{ .Pkg }}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Config{
+		Global: map[string]any{"Name": "demo"},
+		Files:  []config.File{{Name: "main.go", Path: `out\cmd`, Template: "main.tmpl"}},
+	}
+	tests := []struct {
+		name string
+		cfg  config.Config
+		opts Options
+		want []string
+	}{
+		{
+			name: "structure only",
+			cfg:  cfg,
+			opts: Options{TargetOS: "windows"},
+		},
+		{
+			name: "target os",
+			cfg:  cfg,
+			opts: Options{TargetOS: "linux"},
+			want: []string{`files[0].path: warning: out\cmd uses \ as a separator, which linux takes for part of a name; store it as out/cmd`},
+		},
+		{
+			name: "templates",
+			cfg:  cfg,
+			opts: Options{TemplatesDir: dir, TargetOS: "windows"},
+			want: []string{
+				`files[0].template: error: variable "Pkg" is not provided`,
+				"global.Name: warning: not used by any template",
+			},
+		},
+		{
+			name: "strict",
+			cfg:  cfg,
+			opts: Options{TemplatesDir: dir, TargetOS: "windows", Strict: true},
+			want: []string{
+				`files[0].template: error: variable "Pkg" is not provided`,
+				"global.Name: error: not used by any template",
+			},
+		},
+		{
+			name: "empty files",
+			cfg:  config.Config{Global: cfg.Global},
+			want: []string{"files: error: at least one file is required"},
+		},
+		{
+			name: "empty files allowed",
+			cfg:  config.Config{Global: cfg.Global},
+			opts: Options{AllowEmptyFiles: true},
+		},
+		{
+			name: "keys",
+			cfg:  config.Config{Global: map[string]any{"Name": "demo", "name": "x"}, Files: cfg.Files},
+			opts: Options{TargetOS: "windows", Keys: mustKeyRules(t, `^[a-z]+$`)},
+			want: []string{`global.Name: error: variable name "Name" must match ^[a-z]+$`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, p := range Config(tt.cfg, tt.opts) {
+				got = append(got, p.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got problems\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func mustKeyRules(t *testing.T, pattern string) config.KeyRules {
+	t.Helper()
+	r, err := config.NewKeyRules(pattern, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
@@ -0,0 +1,45 @@
+package wizard
+
+import "strings"
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+)
+
+// colorize applies style to s if on is set.
+func colorize(on bool, style, s string) string {
+	if !on || s == "" {
+		return s
+	}
+	return style + s + ansiReset
+}
+
+// styled applies style to s if the wizard output is colored.
+func (w *Wizard) styled(style, s string) string {
+	return colorize(w.color, style, s)
+}
+
+// stylePrompt highlights the section headers, notes and the closing question
+// of a wizard prompt.
+func (w *Wizard) stylePrompt(prompt string) string {
+	if !w.color {
+		return prompt
+	}
+	lines := strings.Split(prompt, "\n")
+	for i, l := range lines {
+		t := strings.TrimSpace(l)
+		switch {
+		case strings.HasPrefix(t, "--") && strings.HasSuffix(t, "--"):
+			lines[i] = w.styled(ansiBold+ansiCyan, l)
+		case strings.HasPrefix(t, "NOTE:"):
+			lines[i] = w.styled(ansiYellow, l)
+		case i == len(lines)-1 && t != "":
+			lines[i] = w.styled(ansiBold, l)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,163 @@
+package wizard
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+// Prompter is what the wizard asks its questions through: prompts and
+// messages are written to it and answers read from it, so that the wizard
+// runs on any pair of streams, such as the buffers of a test.
+type Prompter interface {
+	io.Writer
+	// ReadLine reads a line of input without its line ending.
+	ReadLine() (string, error)
+	// ReadHidden reads a line of input without echoing it, as far as the
+	// input allows.
+	ReadHidden() (string, error)
+}
+
+// lineReader reads the lines answering prompts out of a stream. Prompts
+// reading the same stream have to share its lineReader, or the input one of
+// them buffered would be lost to the others.
+type lineReader struct {
+	mu  sync.Mutex
+	src io.Reader
+	buf *bufio.Reader
+	// max is the length of the longest line read.
+	max int
+}
+
+// DefaultMaxLineSize is the length of the longest line read by default,
+// generous for pasted values yet keeping a stream without line endings
+// from being read into memory whole.
+const DefaultMaxLineSize = 1 << 20
+
+func newLineReader(r io.Reader) *lineReader {
+	return &lineReader{src: r, buf: bufio.NewReader(r), max: DefaultMaxLineSize}
+}
+
+// stdinReader is the lineReader of stdin, shared by every prompt and by
+// whatever else reads stdin.
+var stdinReader = newLineReader(os.Stdin)
+
+// Stdin returns stdin as read by the Prompters of NewPrompter, for reading
+// the input they left after the lines they read.
+func Stdin() io.Reader {
+	return stdinReader
+}
+
+// lineReaderOf returns the lineReader of r, the shared one for stdin.
+func lineReaderOf(r io.Reader) *lineReader {
+	if r == os.Stdin {
+		return stdinReader
+	}
+	return newLineReader(r)
+}
+
+// Read reads the input left after the lines read, for readers of the whole
+// stream.
+func (l *lineReader) Read(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.Read(p)
+}
+
+// setMaxLineSize sets the length of the longest line read to n bytes, see
+// WithMaxLineSize.
+func (l *lineReader) setMaxLineSize(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.max = n
+}
+
+// ReadLine reads a line without its line ending. A last line lacking one is
+// returned as it is, and io.EOF once the stream is exhausted. Lines longer
+// than the maximum are skipped with an error.
+func (l *lineReader) ReadLine() (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var (
+		line []byte
+		long bool
+	)
+	for {
+		chunk, err := l.buf.ReadSlice('\n')
+		if !long {
+			line = append(line, chunk...)
+			long = len(bytes.TrimRight(line, "\r\n")) > l.max
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == io.EOF && len(line) != 0 {
+			err = nil
+		}
+		switch {
+		case long:
+			return "", &lineTooLongError{max: l.max}
+		case err != nil:
+			return "", err
+		}
+		return strings.TrimSuffix(strings.TrimSuffix(string(line), "\n"), "\r"), nil
+	}
+}
+
+// lineTooLongError reports a line longer than the maximum, which was
+// skipped: reading carries on with the next one.
+type lineTooLongError struct {
+	max int
+}
+
+func (e *lineTooLongError) Error() string {
+	return fmt.Sprintf("line longer than %d bytes: raise --max-line-size, or enter the value as <<END followed by its lines and END", e.max)
+}
+
+// ReadHidden disables echo if the stream is a terminal with nothing
+// buffered, and otherwise reads a plain line.
+func (l *lineReader) ReadHidden(w io.Writer) (string, error) {
+	f, ok := l.src.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) || l.buf.Buffered() != 0 {
+		return l.ReadLine()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, err := term.ReadPassword(int(f.Fd()))
+	fmt.Fprintln(w)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// streamPrompter is a Prompter reading from in and writing to w.
+type streamPrompter struct {
+	in *lineReader
+	w  io.Writer
+}
+
+// NewPrompter returns a Prompter reading answers from r and writing prompts
+// to w. Prompters reading stdin share their input; other readers should be
+// given to a single Prompter.
+func NewPrompter(r io.Reader, w io.Writer) Prompter {
+	return streamPrompter{in: lineReaderOf(r), w: w}
+}
+
+func (p streamPrompter) Write(b []byte) (int, error) {
+	return p.w.Write(b)
+}
+
+func (p streamPrompter) ReadLine() (string, error) {
+	return p.in.ReadLine()
+}
+
+func (p streamPrompter) ReadHidden() (string, error) {
+	return p.in.ReadHidden(p.w)
+}
@@ -0,0 +1,920 @@
+package wizard
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/omerkaya1/gg-config/internal/input"
+	"github.com/omerkaya1/gg-config/internal/probe"
+	"github.com/omerkaya1/gg-config/internal/variables"
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+const (
+	yes = "y"
+	no  = "n"
+)
+
+// readSection runs the wizard for a single section of cfg.
+func (w *Wizard) readSection(section Section, cfg *config.Config, defs config.Config) error {
+	var err error
+	switch section {
+	case Globals:
+		var scope input.Scope
+		scope, err = w.readGlobals(input.GlobalScope(*cfg))
+		scope.SetGlobal(cfg)
+	case Files:
+		cfg.Files, err = w.readFiles(cfg.Files, defs.Files)
+	case PreCommands:
+		cfg.PreCmds, err = w.readCommands(cfg.PreCmds, "pre-generation", preCommandsPrompt)
+	default:
+		cfg.Cmds, err = w.readCommands(cfg.Cmds, "post-processing", commandsPrompt)
+	}
+	return err
+}
+
+// review prints a summary of cfg and asks whether to write it, to edit one
+// of its sections again or to edit a single file or (pre-)command entry. It
+// returns the section and the index of the entry to edit, -1 meaning the
+// whole section, or a section past the last one once the config is confirmed.
+func (w *Wizard) review(cfg config.Config) (Section, int, error) {
+	w.printf("\n%s\n", w.stylePrompt(reviewPrompt))
+	printSummary(w.p, cfg)
+	question := "\nWrite config (y), edit globals (g), files (f), pre-commands (p), commands (c) or a single entry (f1, p2, c3...)? "
+	if w.skipCommands {
+		question = "\nWrite config (y), edit globals (g), files (f) or a single file (f1, f2...)? "
+	}
+	if w.assumeYes {
+		w.printf("%s%s\n", w.styled(ansiBold, question), yes)
+		return w.lastSection() + 1, -1, nil
+	}
+	for {
+		answer, err := w.scan(question)
+		if errors.Is(err, errBack) {
+			return w.lastSection(), -1, nil
+		}
+		if err != nil {
+			return 0, -1, err
+		}
+		if w.skipCommands && (strings.HasPrefix(answer, "p") || strings.HasPrefix(answer, "c")) {
+			w.invalidInput("unknown choice %q", answer)
+			continue
+		}
+		switch answer {
+		case yes:
+			return w.lastSection() + 1, -1, nil
+		case "g":
+			return Globals, -1, nil
+		case "f":
+			return Files, -1, nil
+		case "p":
+			return PreCommands, -1, nil
+		case "c":
+			return Commands, -1, nil
+		}
+
+		var (
+			section Section
+			entries int
+		)
+		switch {
+		case strings.HasPrefix(answer, "f"):
+			section, entries = Files, len(cfg.Files)
+		case strings.HasPrefix(answer, "p"):
+			section, entries = PreCommands, len(cfg.PreCmds)
+		case strings.HasPrefix(answer, "c"):
+			section, entries = Commands, len(cfg.Cmds)
+		default:
+			w.invalidInput("unknown choice %q", answer)
+			continue
+		}
+		n, err := strconv.Atoi(answer[1:])
+		if err != nil {
+			w.invalidInput("unknown choice %q", answer)
+			continue
+		}
+		if n < 1 || n > entries {
+			w.invalidInput("there is no entry %q", answer)
+			continue
+		}
+		return section, n - 1, nil
+	}
+}
+
+// readEntry re-runs the prompts of a single file or (pre-)command entry of
+// cfg.
+func (w *Wizard) readEntry(section Section, i int, cfg *config.Config) error {
+	if section == Files {
+		f := cfg.Files[i]
+		for {
+			var err error
+			if f, err = w.readFile(f, 0); err != nil {
+				return err
+			}
+			j := config.FindCollision(cfg.Files, f, i)
+			if j < 0 {
+				break
+			}
+			w.invalidInput("%s produces the same file as entry %d", f.Target(), j+1)
+		}
+		cfg.Files[i] = f
+		return nil
+	}
+	list := cfg.Cmds
+	if section == PreCommands {
+		list = cfg.PreCmds
+	}
+	c, err := w.readCommand(list[i])
+	if err != nil {
+		return err
+	}
+	list[i] = c
+	return nil
+}
+
+// readCommand prompts for the executable, arguments and options of a single
+// command, offering the parts of current as defaults. Going back from the
+// executable returns errBack.
+func (w *Wizard) readCommand(current config.Command) (config.Command, error) {
+	c := current
+	err := runSteps(0, []func() error{
+		func() (err error) {
+			c.Name, err = w.readExecutable(c.Name)
+			if w.exhausted(err) {
+				return errNoMoreEntries
+			}
+			return err
+		},
+		func() (err error) {
+			c, err = w.readArguments(c)
+			return err
+		},
+		func() (err error) {
+			c, err = w.readCommandOptions(c)
+			return err
+		},
+	})
+	if err != nil {
+		return current, err
+	}
+	return c, nil
+}
+
+// maxCompletions is the number of executables listed when completing a
+// command name.
+const maxCompletions = 20
+
+// readExecutable prompts for the name of an executable. Names not found in
+// PATH are completed if they are the prefix of a single executable, and the
+// matching executables are listed for selection by number if there are
+// several.
+func (w *Wizard) readExecutable(def string) (string, error) {
+	var choices []string
+	for {
+		prompt := "Executable"
+		if len(choices) != 0 {
+			prompt = "Executable or number"
+		}
+		answer, err := w.scanDefault(prompt, def)
+		if err != nil {
+			return "", err
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(choices) {
+			return choices[n-1], nil
+		}
+		choices = nil
+		// Paths are not completed.
+		problems := probe.CheckCommand(answer)
+		if len(problems) == 0 || strings.ContainsAny(answer, `/\`) {
+			if w.reportProblems(problems) {
+				return answer, nil
+			}
+			continue
+		}
+
+		switch matches := probe.CompleteCommand(answer); len(matches) {
+		case 0:
+			if w.reportProblems(problems) {
+				return answer, nil
+			}
+		case 1:
+			w.printf("Completed to %s\n", matches[0])
+			return matches[0], nil
+		default:
+			w.println("Matching executables:")
+			for i, m := range matches {
+				if i == maxCompletions {
+					w.printf("\t... and %d more, type a longer prefix\n", len(matches)-i)
+					break
+				}
+				w.printf("\t%d. %s\n", i+1, m)
+			}
+			choices = matches[:min(len(matches), maxCompletions)]
+		}
+	}
+}
+
+// readArguments prompts for the arguments of c, which are checked for shell
+// syntax. Commands using it may be run through a shell, their arguments then
+// being kept as typed.
+func (w *Wizard) readArguments(c config.Command) (config.Command, error) {
+	current := input.JoinTokens(c.Args)
+	if c.Shell {
+		current = strings.Join(c.Args, " ")
+	}
+	for {
+		line, err := w.readOptional("Arguments", current)
+		if err != nil {
+			return c, err
+		}
+		args, err := input.Tokenize(line)
+		if err != nil {
+			w.invalidInput("%s", err)
+			continue
+		}
+		if !input.HasShellSyntax(line) {
+			c.Args, c.Shell = args, false
+			return c, nil
+		}
+		shell, err := w.confirm("The arguments contain shell syntax, run the command through a shell: y/n? ")
+		if err != nil {
+			return c, err
+		}
+		if shell {
+			c.Args, c.Shell = nil, true
+			if line = strings.TrimSpace(line); line != "" {
+				c.Args = []string{line}
+			}
+			return c, nil
+		}
+		if w.reportProblems([]config.ValidationError{{Severity: config.SeverityWarning, Message: "shell syntax is passed to the command literally"}}) {
+			c.Args, c.Shell = args, false
+			return c, nil
+		}
+	}
+}
+
+// readCommandOptions prompts for the working directory and environment of c.
+func (w *Wizard) readCommandOptions(c config.Command) (config.Command, error) {
+	dir, err := w.readOptional("Working directory", c.Dir)
+	if err != nil {
+		return c, err
+	}
+	for {
+		line, err := w.readOptional("Environment (KEY=value ...)", input.JoinEnv(c.Env))
+		if err != nil {
+			return c, err
+		}
+		env, err := input.ParseEnv(line)
+		if err != nil {
+			w.invalidInput("%s", err)
+			continue
+		}
+		c.Dir, c.Env = dir, env
+		return c, nil
+	}
+}
+
+// readOptional prompts for a value which may be left empty: empty input keeps
+// current and "-" clears it.
+func (w *Wizard) readOptional(prompt, current string) (string, error) {
+	fmt.Fprint(w.p, w.styled(ansiBold, fmt.Sprintf("%s [%s] (- to clear): ", prompt, current)))
+	line, err := w.p.ReadLine()
+	if err != nil {
+		return current, err
+	}
+	switch line = strings.TrimSpace(line); line {
+	case "":
+		return current, nil
+	case "-":
+		return "", nil
+	case back:
+		return current, errBack
+	case quit:
+		return current, ErrQuit
+	}
+	return line, nil
+}
+
+const reviewPrompt = `		-- Review --
+Please check the assembled config before it is written.`
+
+// back is the answer that returns to the previous question of the wizard.
+const back = "<"
+
+var errBack = errors.New("back to the previous question")
+
+// quit is the answer that aborts the wizard.
+const quit = ":q"
+
+// errNoMoreEntries is returned for the first question of an entry when the
+// input of a wizard assuming yes ended, which ends the list of entries.
+var errNoMoreEntries = errors.New("no more entries")
+
+const globalPrompt = `		-- Global parameters preparation --
+Here you can add global variables that will be used throughout all templates.
+Provide values as space separated tokens or as Key=Value, quoting values that
+contain spaces. The type of a value is guessed unless its key is annotated with
+one of :string, :int, :float, :bool, :number (kept exactly), :time (RFC 3339),
+:duration (e.g. 1h30m) or :null; null! is null as well. Only true, false and
+decimal numbers are guessed, so 007 or 0x1F stay strings. Lists are enclosed in
+brackets, and entering a key repeatedly collects its values in a list as well.
+Dotted keys group variables, e.g. db.host and db.port. Templates can use the
+time of generation as now, e.g. {{ .now.Year }}, and convert times and
+durations with toTime and toDuration, e.g. {{ (toTime .Released).Year }}. Keys
+annotated with :secret hold secrets: entered without a value, as in
+ApiToken:secret, the value is read without being shown, and it is never printed
+in summaries or logs.
+Keys annotated with :expr are computed when the files are rendered from the
+expression making up the rest of the line, which may use the other variables,
+arithmetic, + to concatenate strings and the functions upper, lower, title,
+camel, snake, kebab, trim and string, e.g. Image:expr lower(Name) + ":" + Tag.
+Keys annotated with :choice, or just :, restrict a variable to the | separated
+values following them, of which one is picked, e.g. License: MIT|Apache-2.0.
+Long or multi-line values, such as certificates, are entered as Key <<END,
+followed by their lines and a line holding just END.
+Type < at any question to go back to the previous one, or :q to quit.
+
+Example: SomeValue 123
+Example: ProjectName="My Cool App"
+Example: Version:string 1.10
+Example: Ports [8080, 8081, 9090]
+
+Whould you like to add Global config values: y/n? `
+
+func (w *Wizard) readGlobals(current input.Scope) (input.Scope, error) {
+	result, err := w.processVariables(globalPrompt, current)
+	if errors.Is(err, errBack) {
+		return current, err
+	}
+	if err != nil {
+		return result, fmt.Errorf("global variables: %w", err)
+	}
+	return result, nil
+}
+
+const (
+	filesPrompt = `		-- Files configuration part preparation --
+This part is dedicated to specifying everything that has to do with file templates.
+Each file consists of four parts:
+
+	1. File name	   - the name of the file to be generated out of the template;
+	2. File path	   - the path to where the file will be placed;
+	3. Template name   - the name of the tmeplate to use;
+	4. Local variables - the local variables specific to the specified template.
+
+NOTE: there has to be at least one file to add.`
+	localVarsPrompt = `		--- Local variables ---
+Provide values as space separated tokens or as Key=Value, quoting values that
+contain spaces. The type of a value is guessed unless its key is annotated with
+one of :string, :int, :float, :bool, :number (kept exactly), :time (RFC 3339),
+:duration (e.g. 1h30m) or :null; null! is null as well. Only true, false and
+decimal numbers are guessed, so 007 or 0x1F stay strings. Lists are enclosed in
+brackets, and entering a key repeatedly collects its values in a list as well.
+Dotted keys group variables, e.g. db.host and db.port. Templates can use the
+time of generation as now, e.g. {{ .now.Year }}, and convert times and
+durations with toTime and toDuration, e.g. {{ (toTime .Released).Year }}. Keys
+annotated with :secret hold secrets, whose value is read without being shown if
+left out. Values may refer to global variables as ${Name}, resolved when the
+files are rendered; $$ stands for a literal $. Keys annotated with :expr are
+computed from the expression making up the rest of the line, as for globals,
+and keys annotated with :choice, or just :, restrict a variable to the |
+separated values following them. Multi-line values are entered as Key <<END,
+followed by their lines and a line holding just END.
+Example: SomeValue 123
+Example: Port:string 8080
+Example: DbPassword:secret
+Example: PackageName ${ProjectName}-api
+Example: ConstName:expr upper(snake(Name))
+
+Whould you like to add local config values: y/n? `
+)
+
+// readFiles lets the user review the current files and add new ones. The
+// n-th new entry is pre-populated with the n-th of defaults, if any.
+func (w *Wizard) readFiles(current, defaults []config.File) ([]config.File, error) {
+	w.printf("\n%s\n", w.stylePrompt(filesPrompt))
+
+	var result []config.File
+	for _, f := range current {
+		edit, err := w.confirmAssuming(fmt.Sprintf("Edit file %q (%s): y/n? ", f.Name, f.Path), false)
+		if err != nil {
+			return current, fmt.Errorf("file parameters: %w", err)
+		}
+		if edit {
+			if f, err = w.readFile(f, 0); err != nil {
+				return current, err
+			}
+		}
+		result = append(result, f)
+	}
+	// A file is required, unless there is one already or none is.
+	if len(current) != 0 || w.allowEmptyFiles {
+		add, err := w.confirm("Add new file: y/n? ")
+		if err != nil {
+			return current, fmt.Errorf("file parameters: %w", err)
+		}
+		if !add {
+			return result, nil
+		}
+	}
+
+	var (
+		kept = len(result)
+		next config.File
+		step int
+	)
+	if len(defaults) != 0 {
+		next = defaults[0]
+	}
+	for {
+		f, err := w.readFile(next, step)
+		if errors.Is(err, errNoMoreEntries) {
+			if len(result) == 0 && !w.allowEmptyFiles {
+				// The file required was never entered.
+				return result, fmt.Errorf("file parameters: %w", io.EOF)
+			}
+			return result, nil
+		}
+		if errors.Is(err, errBack) {
+			// Going back from the first question of a new entry reopens
+			// the last question of the previous one.
+			if len(result) == kept {
+				return current, err
+			}
+			next, result = result[len(result)-1], result[:len(result)-1]
+			step = fileSteps
+			continue
+		}
+		// The entries completed so far are kept when the input ends.
+		if errors.Is(err, io.EOF) {
+			return result, err
+		}
+		if err != nil {
+			return current, err
+		}
+
+		appended := true
+		if j := config.FindCollision(result, f, -1); j >= 0 {
+			choice, err := w.resolveCollision(f, j)
+			if err != nil {
+				return current, fmt.Errorf("file parameters: %w", err)
+			}
+			switch choice {
+			case collisionEdit:
+				next, step = f, 0
+				continue
+			case collisionReplace:
+				result[j] = f
+			}
+			appended = false
+		} else {
+			result = append(result, f)
+		}
+		next, step = config.File{}, 0
+		if n := len(result) - kept; n < len(defaults) {
+			next = defaults[n]
+		}
+
+		answer, err := w.scanNext("Add next file: y/n? ")
+		if errors.Is(err, errBack) {
+			if appended {
+				f, result = result[len(result)-1], result[:len(result)-1]
+			}
+			next, step = f, fileSteps-1
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			return result, fmt.Errorf("file parameters: %w", err)
+		}
+		if err != nil {
+			return current, fmt.Errorf("file parameters: %w", err)
+		}
+		if answer == no {
+			return result, nil
+		}
+	}
+}
+
+const (
+	collisionReplace = "r"
+	collisionEdit    = "e"
+	collisionDrop    = "d"
+)
+
+// resolveCollision asks what to do with a new file entry producing the same
+// output file as the j-th one.
+func (w *Wizard) resolveCollision(f config.File, j int) (string, error) {
+	w.println(w.styled(ansiYellow, fmt.Sprintf("Warning: %s produces the same file as entry %d", f.Target(), j+1)))
+	for {
+		answer, err := w.scan("Replace the existing entry (r), edit this one (e) or drop it (d)? ")
+		if err != nil {
+			return "", err
+		}
+		switch answer {
+		case collisionReplace, collisionEdit, collisionDrop:
+			return answer, nil
+		default:
+			w.invalidInput("unknown choice %q", answer)
+		}
+	}
+}
+
+// fileSteps is the number of questions asked for a single file entry.
+const fileSteps = 4
+
+// readFile prompts for a single file entry starting at the given step,
+// offering the values of current as defaults. Going back from the first
+// step returns errBack.
+func (w *Wizard) readFile(current config.File, step int) (config.File, error) {
+	f := current
+	err := runSteps(step, []func() error{
+		func() (err error) {
+			f.Name, err = w.scanDefault("File name", f.Name)
+			if w.exhausted(err) {
+				return errNoMoreEntries
+			}
+			return err
+		},
+		func() error {
+			for {
+				path, err := w.scanDefault("File path", f.Path)
+				if err != nil {
+					return err
+				}
+				if w.reportProblems(probe.CheckFilePath(path, f.Name)) {
+					f.Path = path
+					return nil
+				}
+			}
+		},
+		func() (err error) {
+			f.Template, err = w.pickTemplate(f.Template)
+			return err
+		},
+		func() error {
+			w.println()
+			w.templateHint(f.Template)
+			local, err := w.processVariables(localVarsPrompt, input.LocalScope(f))
+			if err != nil {
+				return err
+			}
+			local.SetLocal(&f)
+			return nil
+		},
+	})
+	if err != nil {
+		return current, fmt.Errorf("file parameters: %w", err)
+	}
+	return f, nil
+}
+
+// runSteps executes steps in order starting at start, moving one step back
+// whenever a step returns errBack. Going back from the first step is
+// reported to the caller.
+func runSteps(start int, steps []func() error) error {
+	if start >= len(steps) {
+		start = len(steps) - 1
+	}
+	for i := start; i < len(steps); {
+		err := steps[i]()
+		switch {
+		case errors.Is(err, errBack):
+			if i == 0 {
+				return err
+			}
+			i--
+		case err != nil:
+			return err
+		default:
+			i++
+		}
+	}
+	return nil
+}
+
+const preCommandsPrompt = `		-- Command pre-hooks configuration preparation --
+This part is dedicated to specifying commands that have to run before the files are generated,
+e.g. fetching the latest templates or cleaning up previous output.
+Each entry consists of four parts:
+
+	1. Executable	      - the command to be called, a prefix listing the matching ones in PATH;
+	2. Command arguments  - the arguments passed to the command, optionally through a shell;
+	3. Working directory  - where the command runs, the current directory if empty;
+	4. Environment        - additional KEY=value environment variables.
+
+Example: mkdir -p build
+
+Whould you like to add pre-generation commands: y/n? `
+
+const commandsPrompt = `		-- Command post-hooks configuration preparation --
+This part is dedicated to specifying everything that has to do with post-generation hooks.
+Each entry consists of four parts:
+
+	1. Executable	      - the command to be called, a prefix listing the matching ones in PATH;
+	2. Command arguments  - the arguments passed to the command, optionally through a shell;
+	3. Working directory  - where the command runs, the current directory if empty;
+	4. Environment        - additional KEY=value environment variables.
+
+Example: go mod tidy
+
+Whould you like to add post-processing commands: y/n? `
+
+// readCommands asks for a list of commands, kind naming them in messages
+// and prompt introducing the section.
+func (w *Wizard) readCommands(current []config.Command, kind, prompt string) ([]config.Command, error) {
+	var result []config.Command
+	if len(current) != 0 {
+		w.printf("\nCurrent %s commands:\n", kind)
+		for _, c := range current {
+			w.printf("\t%s\n", input.JoinTokens(append([]string{c.Name}, c.Args...)))
+		}
+		keep, err := w.confirm("Keep current commands: y/n? ")
+		if err != nil {
+			return current, fmt.Errorf("read commands: %w", err)
+		}
+		if keep {
+			result = append(result, current...)
+		}
+	}
+
+	w.printf("\n%s", w.stylePrompt(prompt))
+	add, err := w.confirm("")
+	for err == nil && add {
+		var c config.Command
+		c, err = w.readCommand(config.Command{})
+		if errors.Is(err, errNoMoreEntries) {
+			return result, nil
+		}
+		if errors.Is(err, errBack) {
+			return current, fmt.Errorf("read commands: %w", err)
+		}
+		if err != nil {
+			break
+		}
+		result = append(result, c)
+		add, err = w.confirm("Add next command: y/n? ")
+	}
+	// The commands completed so far are kept when the input ends.
+	if errors.Is(err, io.EOF) {
+		return result, fmt.Errorf("read commands: %w", err)
+	}
+	if err != nil {
+		return current, fmt.Errorf("read commands: %w", err)
+	}
+	return result, nil
+}
+
+// processVariables lets the user keep the current variables and add new
+// ones.
+func (w *Wizard) processVariables(prompt string, current input.Scope) (input.Scope, error) {
+	result := input.Scope{Rules: w.rules, Ask: w}
+	if len(current.Vars) != 0 || len(current.Computed) != 0 {
+		w.println("Current values:")
+		flat := input.RedactVars(current.Vars, current.Secrets)
+		for _, k := range sortedKeys(flat) {
+			if choices, ok := current.Choices[k]; ok {
+				w.printf("\t%s %s (one of %s)\n", k, variables.Display(flat[k]), strings.Join(choices, ", "))
+				continue
+			}
+			w.printf("\t%s %s\n", k, variables.Display(flat[k]))
+		}
+		for _, k := range sortedKeys(current.Computed) {
+			w.printf("\t%s = %s\n", k, current.Computed[k])
+		}
+		keep, err := w.confirm("Keep current values: y/n? ")
+		if err != nil {
+			return result, fmt.Errorf("process variables: %w", err)
+		}
+		if keep {
+			result.Vars = make(map[string]any, len(current.Vars))
+			for k, v := range current.Vars {
+				result.Vars[k] = v
+			}
+			result.Secrets = current.Secrets
+			for k, expr := range current.Computed {
+				result.Computed = input.SetComputed(result.Computed, k, expr)
+			}
+			for k, choices := range current.Choices {
+				result.Choices = input.SetChoices(result.Choices, k, choices)
+			}
+		}
+	}
+
+	fmt.Fprint(w.p, w.stylePrompt(prompt))
+	entered := make(map[string]bool)
+Cycle:
+	for {
+		line, err := w.p.ReadLine()
+		if err == io.EOF {
+			break
+		}
+		var long *lineTooLongError
+		if errors.As(err, &long) {
+			w.invalidInput("%s", err)
+			fmt.Fprint(w.p, w.styled(ansiBold, `Value: `))
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("process variables: %w", err)
+		}
+		switch line {
+		case yes:
+			continue
+		case no:
+			break Cycle
+		case back:
+			return current, fmt.Errorf("process variables: %w", errBack)
+		case quit:
+			return current, ErrQuit
+		default:
+		}
+		key, value, err := input.ParseVariable(line)
+		if sentinel, ok := input.MultiLineSentinel(value); ok && err == nil {
+			if value, err = w.readMultiLine(sentinel); err != nil {
+				return result, fmt.Errorf("process variables: %w", err)
+			}
+		}
+		if err == nil {
+			err = result.Add(key, value, entered)
+		}
+		if err != nil {
+			w.invalidInput("%s", err)
+			fmt.Fprint(w.p, w.styled(ansiBold, `Value: `))
+			continue
+		}
+		fmt.Fprint(w.p, w.styled(ansiBold, `Add next value: y/n? `))
+	}
+	return result, nil
+}
+
+// readMultiLine reads the lines of a multi-line value up to the one holding
+// just sentinel, and returns them joined by newlines. Input ending before
+// the sentinel is an io.EOF.
+func (w *Wizard) readMultiLine(sentinel string) (string, error) {
+	var lines []string
+	for {
+		line, err := w.p.ReadLine()
+		if err != nil {
+			return "", err
+		}
+		if line == sentinel {
+			return strings.Join(lines, "\n"), nil
+		}
+		lines = append(lines, line)
+	}
+}
+
+// scan prompts for a single token.
+func (w *Wizard) scan(prompt string) (string, error) {
+	return w.scanToken(prompt, "")
+}
+
+// scanNext asks whether to add another entry to a list, answering yes
+// itself when assuming so: the list then ends with the input.
+func (w *Wizard) scanNext(prompt string) (string, error) {
+	if w.assumeYes {
+		w.printf("%s%s\n", w.styled(ansiBold, prompt), yes)
+		return yes, nil
+	}
+	return w.scan(prompt)
+}
+
+// scanDefault prompts for a single token, returning def when the user submits
+// an empty line.
+func (w *Wizard) scanDefault(prompt, def string) (string, error) {
+	if def == "" {
+		return w.scan(prompt + ": ")
+	}
+	return w.scanToken(fmt.Sprintf("%s [%s]: ", prompt, def), def)
+}
+
+// scanToken prompts until it gets a single token. Empty input is answered
+// with def, if set, and asked again otherwise.
+func (w *Wizard) scanToken(prompt, def string) (string, error) {
+	for {
+		fmt.Fprint(w.p, w.styled(ansiBold, prompt))
+		line, err := w.p.ReadLine()
+		if w.exhausted(err) && def != "" {
+			w.println(def)
+			return def, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		fields, err := input.Tokenize(line)
+		if err != nil {
+			w.invalidInput("%s", err)
+			continue
+		}
+		switch {
+		case len(fields) == 0 && def != "":
+			return def, nil
+		case len(fields) == 0:
+			continue
+		case len(fields) > 1:
+			w.invalidInput("expected a single value, got %d", len(fields))
+			continue
+		}
+		switch fields[0] {
+		case back:
+			return "", errBack
+		case quit:
+			return "", ErrQuit
+		default:
+			return fields[0], nil
+		}
+	}
+}
+
+// reportProblems prints the problems found in a wizard answer and reports
+// whether the answer can be accepted, i.e. none of them is an error, nor a
+// warning in strict mode.
+func (w *Wizard) reportProblems(problems []config.ValidationError) bool {
+	for _, p := range problems {
+		if p.Severity == config.SeverityError || w.strict && p.Severity == config.SeverityWarning {
+			w.invalidInput("%s", p.Message)
+			return false
+		}
+		w.println(w.styled(ansiYellow, "Warning: "+p.Message))
+	}
+	return true
+}
+
+func (w *Wizard) invalidInput(reason string, args ...any) {
+	w.println(w.styled(ansiRed, fmt.Sprintf("Invalid input: %s. Try again or type %s to quit.", fmt.Sprintf(reason, args...), quit)))
+}
+
+// confirm asks a yes/no question until it gets a valid answer, or answers
+// it with yes when assuming so.
+func (w *Wizard) confirm(prompt string) (bool, error) {
+	return w.confirmAssuming(prompt, true)
+}
+
+// confirmAssuming is confirm, answering with assumed when assuming yes.
+// Questions whose yes would ask for more, such as whether to edit an entry,
+// are assumed to be answered with no.
+func (w *Wizard) confirmAssuming(prompt string, assumed bool) (bool, error) {
+	if w.assumeYes {
+		answer := no
+		if assumed {
+			answer = yes
+		}
+		w.printf("%s%s\n", w.styled(ansiBold, prompt), answer)
+		return assumed, nil
+	}
+	for {
+		answer, err := w.scan(prompt)
+		if err != nil {
+			return false, err
+		}
+		switch answer {
+		case yes:
+			return true, nil
+		case no:
+			return false, nil
+		default:
+		}
+	}
+}
+
+// PickChoice asks for one of the choices of the variable name, by value or
+// by number.
+func (w *Wizard) PickChoice(name string, choices []string, def string) (string, error) {
+	w.printf("Choices for %s:\n", name)
+	for i, c := range choices {
+		w.printf("\t%d. %s\n", i+1, c)
+	}
+	for {
+		answer, err := w.scanDefault(name+" value or number", def)
+		if err != nil {
+			return "", err
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(choices) {
+			return choices[n-1], nil
+		}
+		for _, c := range choices {
+			if c == answer {
+				return c, nil
+			}
+		}
+		w.invalidInput("%q is not one of %s", answer, strings.Join(choices, ", "))
+	}
+}
+
+// exhausted reports whether err is the end of the input of a wizard
+// answering confirmations itself, which ends the list being entered.
+func (w *Wizard) exhausted(err error) bool {
+	return w.assumeYes && errors.Is(err, io.EOF)
+}
+
+func (w *Wizard) printf(format string, args ...any) {
+	fmt.Fprintf(w.p, format, args...)
+}
+
+func (w *Wizard) println(args ...any) {
+	fmt.Fprintln(w.p, args...)
+}
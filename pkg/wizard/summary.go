@@ -0,0 +1,112 @@
+package wizard
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/omerkaya1/gg-config/internal/input"
+	"github.com/omerkaya1/gg-config/internal/variables"
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+// printSummary writes a human-readable overview of cfg.
+func printSummary(w io.Writer, cfg config.Config) {
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	fmt.Fprintln(bw, "Global variables:")
+	if len(cfg.Global) == 0 && len(cfg.Computed) == 0 {
+		fmt.Fprintln(bw, "\t(none)")
+	}
+	printVars(bw, input.GlobalScope(cfg), "\t")
+
+	fmt.Fprintln(bw, "Files:")
+	if len(cfg.Files) == 0 {
+		fmt.Fprintln(bw, "\t(none)")
+	}
+	for i, f := range cfg.Files {
+		fmt.Fprintf(bw, "\t%d. %s in %s (template: %s)\n", i+1, f.Name, f.Path, f.Template)
+		if f.SkipIf != "" {
+			fmt.Fprintf(bw, "\t   skipped if %s\n", f.SkipIf)
+		}
+		if f.Format != "" {
+			fmt.Fprintf(bw, "\t   formatted with %s\n", f.Format)
+		}
+		for _, h := range f.Hooks {
+			fmt.Fprintf(bw, "\t   then %s\n", input.JoinTokens(append([]string{h.Name}, h.Args...)))
+		}
+		printVars(bw, input.LocalScope(f), "\t   ")
+	}
+
+	printCommands(bw, "Pre-commands:", cfg.PreCmds)
+	printCommands(bw, "Commands:", cfg.Cmds)
+}
+
+func printCommands(w io.Writer, title string, cmds []config.Command) {
+	fmt.Fprintln(w, title)
+	if len(cmds) == 0 {
+		fmt.Fprintln(w, "\t(none)")
+	}
+	for i, c := range cmds {
+		if c.Shell {
+			fmt.Fprintf(w, "\t%d. %s (shell)\n", i+1, strings.Join(append([]string{c.Name}, c.Args...), " "))
+		} else {
+			fmt.Fprintf(w, "\t%d. %s\n", i+1, input.JoinTokens(append([]string{c.Name}, c.Args...)))
+		}
+		if c.ID != "" {
+			fmt.Fprintf(w, "\t   id %s\n", c.ID)
+		}
+		if c.When != "" {
+			fmt.Fprintf(w, "\t   when %s\n", c.When)
+		}
+		if len(c.DependsOn) != 0 {
+			fmt.Fprintf(w, "\t   after %s\n", strings.Join(c.DependsOn, ", "))
+		}
+		if c.Capture != "" {
+			fmt.Fprintf(w, "\t   output stored in %s\n", c.Capture)
+		}
+		if c.Dir != "" {
+			fmt.Fprintf(w, "\t   in %s\n", c.Dir)
+		}
+		if len(c.Env) != 0 {
+			fmt.Fprintf(w, "\t   with %s\n", input.JoinEnv(c.Env))
+		}
+		if c.Timeout != "" {
+			fmt.Fprintf(w, "\t   timeout %s\n", c.Timeout)
+		}
+		if c.Retries != 0 {
+			fmt.Fprintf(w, "\t   retried %d time(s)\n", c.Retries)
+		}
+		if c.OnFailure != "" {
+			fmt.Fprintf(w, "\t   on failure %s\n", c.OnFailure)
+		}
+	}
+}
+
+// printVars lists the variables of s, redacting the values of secrets,
+// followed by the computed ones.
+func printVars(w io.Writer, s input.Scope, indent string) {
+	flat := input.RedactVars(s.Vars, s.Secrets)
+	for _, k := range sortedKeys(flat) {
+		if choices, ok := s.Choices[k]; ok {
+			fmt.Fprintf(w, "%s%s = %s (one of %s)\n", indent, k, variables.Display(flat[k]), strings.Join(choices, ", "))
+			continue
+		}
+		fmt.Fprintf(w, "%s%s = %s\n", indent, k, variables.Display(flat[k]))
+	}
+	for _, k := range sortedKeys(s.Computed) {
+		fmt.Fprintf(w, "%s%s = %s (computed)\n", indent, k, s.Computed[k])
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
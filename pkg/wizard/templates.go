@@ -0,0 +1,79 @@
+package wizard
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/omerkaya1/gg-config/pkg/templates"
+)
+
+// templateChoices restricts the templates of files to names, loaded from
+// dir and presented to the user as a numbered list; none if names is empty.
+type templateChoices struct {
+	names []string
+	dir   string
+}
+
+// match reports whether name is one of the choices or a glob pattern
+// matching at least one of them.
+func (c templateChoices) match(name string) bool {
+	return templates.Match(c.names, name)
+}
+
+// pickTemplate asks for a template name, or for its number when a list of
+// choices is available.
+func (w *Wizard) pickTemplate(def string) (string, error) {
+	choices := w.templates.names
+	if len(choices) == 0 {
+		return w.scanDefault("Template name", def)
+	}
+
+	w.println("Available templates:")
+	for i, name := range choices {
+		w.printf("\t%d. %s\n", i+1, name)
+	}
+	for {
+		answer, err := w.scanDefault("Template name or number", def)
+		if err != nil {
+			return "", err
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(choices) {
+			return choices[n-1], nil
+		}
+		if w.templates.match(answer) {
+			return answer, nil
+		}
+		w.invalidInput("unknown template %q", answer)
+	}
+}
+
+// loadTemplateChoices offers the files beneath dir as template choices, along
+// with the directories containing them, which are directory templates.
+func loadTemplateChoices(dir string) (templateChoices, error) {
+	names, err := templates.Names(os.DirFS(dir))
+	if err != nil {
+		return templateChoices{}, fmt.Errorf("list templates in %s: %w", dir, err)
+	}
+	return templateChoices{names: names, dir: dir}, nil
+}
+
+// templateHint lists the variables the template called name refers to, if
+// it is one of the template choices.
+func (w *Wizard) templateHint(name string) {
+	if w.templates.dir == "" || !w.templates.match(name) {
+		return
+	}
+	vars, err := templates.Inspect(os.DirFS(w.templates.dir), name)
+	if err != nil || len(vars) == 0 {
+		return
+	}
+	w.println("The template refers to:")
+	for _, v := range vars {
+		if v.Type == templates.TypeAny {
+			w.printf("\t%s\n", v.Name)
+			continue
+		}
+		w.printf("\t%s (%s)\n", v.Name, v.Type)
+	}
+}
@@ -0,0 +1,407 @@
+// Package wizard runs the interactive gg-config wizard, which assembles a
+// config by asking for its global variables, files and commands section by
+// section and letting the user review the result, so that other tools can
+// reuse it with their own policies:
+//
+//	cfg, err := wizard.Run(
+//		wizard.WithIO(os.Stdin, os.Stdout),
+//		wizard.WithTemplatesDir("templates"),
+//		wizard.WithSkipCommands(),
+//	)
+package wizard
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/omerkaya1/gg-config/internal/input"
+	"github.com/omerkaya1/gg-config/internal/variables"
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+// ErrQuit is returned by Run when the user quits the wizard.
+var ErrQuit = errors.New("wizard aborted")
+
+// ErrInvalid is returned by Run when the config fails the check of
+// WithCheck and nobody is there to fix it, after the problems found have
+// been printed.
+var ErrInvalid = errors.New("config failed validation, nothing written")
+
+// Section is a section of the config the wizard asks for.
+type Section uint8
+
+// The sections, in the order the wizard asks for them.
+const (
+	Globals Section = iota
+	Files
+	PreCommands
+	Commands
+)
+
+// IncompleteError is returned by Run when its input ends before the config
+// is confirmed, along with the config collected so far.
+type IncompleteError struct {
+	// Section is the section the wizard was in, the one to resume at.
+	Section Section
+}
+
+func (e IncompleteError) Error() string {
+	return "input ended before the config was complete"
+}
+
+func (e IncompleteError) Unwrap() error {
+	return io.EOF
+}
+
+// Wizard asks the questions of the line based wizard through its Prompter.
+type Wizard struct {
+	p Prompter
+	// assumeYes answers confirmations without asking. Lists of entries
+	// then end with the input, and questions offering a default take it
+	// once the input is exhausted.
+	assumeYes bool
+	// strict rejects answers the wizard would otherwise only warn about.
+	strict bool
+	// allowEmptyFiles accepts configs without files.
+	allowEmptyFiles bool
+	// color enables ANSI styling of the output.
+	color bool
+	// rules are what variables are entered by.
+	rules input.Rules
+	// templatesDir, if set, is where the templates of files are picked
+	// from, loaded into templates by New.
+	templatesDir string
+	templates    templateChoices
+	// maxLineSize, if set, is the length of the longest line read.
+	maxLineSize int
+	// skipCommands leaves the pre-commands and commands out.
+	skipCommands bool
+	// config is the config to edit.
+	config config.Config
+	// defaults fills the globals and commands missing from config and
+	// pre-populates new file entries, see readFiles.
+	defaults config.Config
+	// start is the first section asked for, the ones before it being kept
+	// as they are.
+	start Section
+	// check, if set, validates the config before it is accepted.
+	check func(config.Config) config.ValidationErrors
+	// progress, if set, is called with the config whenever a section has
+	// been completed, along with the next one to be filled in.
+	progress func(cfg config.Config, next Section)
+}
+
+// Option customizes a Wizard.
+type Option func(*Wizard)
+
+// WithPrompter asks the questions through p instead of stdin and stdout.
+func WithPrompter(p Prompter) Option {
+	return func(w *Wizard) { w.p = p }
+}
+
+// WithIO asks the questions through r and w instead of stdin and stdout.
+func WithIO(r io.Reader, w io.Writer) Option {
+	return WithPrompter(NewPrompter(r, w))
+}
+
+// WithConfig edits cfg instead of starting from an empty config.
+func WithConfig(cfg config.Config) Option {
+	return func(w *Wizard) { w.config = cfg }
+}
+
+// WithDefaults fills the global variables and commands missing from the
+// config with the ones of cfg, and pre-populates the n-th new file entry with
+// the n-th file of cfg.
+func WithDefaults(cfg config.Config) Option {
+	return func(w *Wizard) { w.defaults = cfg }
+}
+
+// WithSkipCommands leaves the pre-commands and commands out of the wizard,
+// keeping the ones of the config as they are.
+func WithSkipCommands() Option {
+	return func(w *Wizard) { w.skipCommands = true }
+}
+
+// WithTemplatesDir restricts the templates of files to the ones beneath dir,
+// which are offered as a numbered list.
+func WithTemplatesDir(dir string) Option {
+	return func(w *Wizard) { w.templatesDir = dir }
+}
+
+// WithAssumeYes answers confirmations without asking: lists of entries end
+// with the input, and questions offering a default take it once the input is
+// exhausted.
+func WithAssumeYes() Option {
+	return func(w *Wizard) { w.assumeYes = true }
+}
+
+// WithStrict rejects answers the wizard would otherwise only warn about.
+func WithStrict() Option {
+	return func(w *Wizard) { w.strict = true }
+}
+
+// WithAllowEmptyFiles accepts configs without files.
+func WithAllowEmptyFiles() Option {
+	return func(w *Wizard) { w.allowEmptyFiles = true }
+}
+
+// WithNoInfer keeps values entered without a type annotation as strings
+// instead of guessing booleans and numbers.
+func WithNoInfer() Option {
+	return func(w *Wizard) { w.rules.NoInfer = true }
+}
+
+// WithKeyRules checks the names of variables against r instead of the
+// default rules.
+func WithKeyRules(r config.KeyRules) Option {
+	return func(w *Wizard) { w.rules.Keys = r }
+}
+
+// WithColor styles the questions with ANSI escape sequences.
+func WithColor() Option {
+	return func(w *Wizard) { w.color = true }
+}
+
+// WithMaxLineSize limits the answers read to lines of n bytes, instead of
+// DefaultMaxLineSize, when the questions are asked through WithIO or stdin.
+func WithMaxLineSize(n int) Option {
+	return func(w *Wizard) { w.maxLineSize = n }
+}
+
+// WithStart starts the wizard at section s, keeping the sections of the
+// config before it as they are, such as when resuming a draft.
+func WithStart(s Section) Option {
+	return func(w *Wizard) { w.start = s }
+}
+
+// WithCheck validates the config before it is accepted: the problems check
+// finds are printed, and the config is only accepted once none of them is an
+// error.
+func WithCheck(check func(config.Config) config.ValidationErrors) Option {
+	return func(w *Wizard) { w.check = check }
+}
+
+// WithProgress calls progress with the config whenever a section has been
+// completed, along with the next section to be filled in.
+func WithProgress(progress func(cfg config.Config, next Section)) Option {
+	return func(w *Wizard) { w.progress = progress }
+}
+
+// New returns a Wizard asking its questions through stdin and stdout unless
+// told otherwise by opts.
+func New(opts ...Option) (*Wizard, error) {
+	w := &Wizard{}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.p == nil {
+		w.p = NewPrompter(os.Stdin, os.Stdout)
+	}
+	if sp, ok := w.p.(streamPrompter); ok && w.maxLineSize > 0 {
+		sp.in.setMaxLineSize(w.maxLineSize)
+	}
+	if w.templatesDir != "" {
+		var err error
+		if w.templates, err = loadTemplateChoices(w.templatesDir); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// Run runs a wizard configured by opts and returns the config the user
+// confirmed.
+func Run(opts ...Option) (config.Config, error) {
+	w, err := New(opts...)
+	if err != nil {
+		return config.Config{}, err
+	}
+	return w.Run()
+}
+
+// Run asks for the sections of the config in order and then for a review,
+// letting the user edit sections and entries until the config is confirmed.
+// Should the input end before, the config collected so far is returned along
+// with an IncompleteError.
+func (w *Wizard) Run() (config.Config, error) {
+	cfg := input.WithDefaults(w.config, w.defaults)
+	last := w.lastSection()
+	progress := func(next Section) {
+		if w.progress != nil {
+			w.progress(cfg, next)
+		}
+	}
+
+	for i := w.start; i <= last; {
+		err := w.readSection(i, &cfg, w.defaults)
+		if errors.Is(err, io.EOF) {
+			return cfg, IncompleteError{Section: i}
+		}
+		if errors.Is(err, errBack) {
+			if i > Globals {
+				i--
+			}
+			continue
+		}
+		if err != nil {
+			return cfg, err
+		}
+		progress(i + 1)
+		i++
+	}
+
+	for {
+		section, entry, err := w.review(cfg)
+		if errors.Is(err, io.EOF) {
+			return cfg, IncompleteError{Section: last + 1}
+		}
+		if err != nil {
+			return cfg, err
+		}
+		if section > last {
+			if w.check != nil && !w.Report(w.check(cfg)) {
+				// Nobody is there to fix the config.
+				if w.assumeYes {
+					return cfg, ErrInvalid
+				}
+				continue
+			}
+			return cfg, nil
+		}
+		if entry >= 0 {
+			err = w.readEntry(section, entry, &cfg)
+		} else {
+			err = w.readSection(section, &cfg, w.defaults)
+		}
+		if errors.Is(err, io.EOF) {
+			return cfg, IncompleteError{Section: last + 1}
+		}
+		if err != nil && !errors.Is(err, errBack) {
+			return cfg, err
+		}
+		progress(last + 1)
+	}
+}
+
+// lastSection returns the last section the wizard asks for.
+func (w *Wizard) lastSection() Section {
+	if w.skipCommands {
+		return Files
+	}
+	return Commands
+}
+
+// Confirm asks the yes/no question prompt, answered yes without asking if
+// the wizard assumes yes.
+func (w *Wizard) Confirm(prompt string) (bool, error) {
+	return w.confirm(prompt)
+}
+
+// Ask asks the question prompt, def being the answer taken when the user
+// just presses enter.
+func (w *Wizard) Ask(prompt, def string) (string, error) {
+	return w.scanDefault(prompt, def)
+}
+
+// Warn prints the warning msg.
+func (w *Wizard) Warn(msg string) {
+	w.println(w.styled(ansiYellow, msg))
+}
+
+// Report prints problems and reports whether none of them is an error.
+func (w *Wizard) Report(problems config.ValidationErrors) bool {
+	for _, p := range problems {
+		w.println(w.styled(ansiRed, p.Error()))
+	}
+	return !problems.HasErrors()
+}
+
+// ReadSecret prompts for the value of the secret variable name without
+// echoing it.
+func (w *Wizard) ReadSecret(name string) (string, error) {
+	fmt.Fprint(w.p, w.styled(ansiBold, fmt.Sprintf("Value of %s (hidden): ", name)))
+	value, err := w.p.ReadHidden()
+	if err != nil {
+		return "", fmt.Errorf("read secret: %w", err)
+	}
+	return value, nil
+}
+
+// RestoreSecrets asks for the values of the secrets of cfg that are listed
+// without being defined, such as the ones left out of a draft.
+func (w *Wizard) RestoreSecrets(cfg *config.Config) error {
+	restore := func(vars map[string]any, secrets []string, owner string) (map[string]any, error) {
+		for _, name := range secrets {
+			if _, ok := variables.Lookup(vars, name); ok {
+				continue
+			}
+			value, err := w.ReadSecret(owner + name)
+			if err != nil {
+				return vars, err
+			}
+			if vars == nil {
+				vars = make(map[string]any)
+			}
+			if err := variables.Set(vars, name, value); err != nil {
+				return vars, err
+			}
+		}
+		return vars, nil
+	}
+	var err error
+	if cfg.Global, err = restore(cfg.Global, cfg.Secrets, ""); err != nil {
+		return err
+	}
+	for i := range cfg.Files {
+		f := &cfg.Files[i]
+		if f.Local, err = restore(f.Local, f.Secrets, f.Target()+": "); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddFile asks for a single new file entry and adds it to files, resolving
+// collisions with the existing entries the way the files section does.
+func (w *Wizard) AddFile(files []config.File) ([]config.File, error) {
+	var next config.File
+	for {
+		f, err := w.readFile(next, 0)
+		if errors.Is(err, errBack) {
+			continue
+		}
+		if err != nil {
+			return files, err
+		}
+		j := config.FindCollision(files, f, -1)
+		if j < 0 {
+			return append(files, f), nil
+		}
+		choice, err := w.resolveCollision(f, j)
+		if err != nil {
+			return files, fmt.Errorf("file parameters: %w", err)
+		}
+		switch choice {
+		case collisionEdit:
+			next = f
+		case collisionReplace:
+			files[j] = f
+			return files, nil
+		default:
+			return files, nil
+		}
+	}
+}
+
+// AddCommand asks for a single new command, starting from c.
+func (w *Wizard) AddCommand(c config.Command) (config.Command, error) {
+	for {
+		next, err := w.readCommand(c)
+		if errors.Is(err, errBack) {
+			continue
+		}
+		return next, err
+	}
+}
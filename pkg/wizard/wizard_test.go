@@ -0,0 +1,250 @@
+package wizard
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/omerkaya1/gg-config/internal/input"
+	"github.com/omerkaya1/gg-config/pkg/config"
+)
+
+// scriptedPrompter answers the questions of the wizard with lines, in order,
+// and then with io.EOF. Lines longer than max, if set, are rejected as the
+// lineReader of a prompter does.
+type scriptedPrompter struct {
+	lines []string
+	max   int
+	out   strings.Builder
+}
+
+func newScript(lines ...string) *scriptedPrompter {
+	return &scriptedPrompter{lines: lines}
+}
+
+func (p *scriptedPrompter) Write(b []byte) (int, error) {
+	return p.out.Write(b)
+}
+
+func (p *scriptedPrompter) ReadLine() (string, error) {
+	if len(p.lines) == 0 {
+		return "", io.EOF
+	}
+	line := p.lines[0]
+	p.lines = p.lines[1:]
+	if p.max > 0 && len(line) > p.max {
+		return "", &lineTooLongError{max: p.max}
+	}
+	return line, nil
+}
+
+func (p *scriptedPrompter) ReadHidden() (string, error) {
+	return p.ReadLine()
+}
+
+func TestProcessVariables(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		max   int
+		rules input.Rules
+		// want are the variables entered, wantErr the error returned and
+		// wantOut part of the output, if set.
+		want    map[string]any
+		wantErr error
+		wantOut string
+	}{
+		{
+			name:  "input ends",
+			lines: []string{"Name demo", "y", "Port 8080"},
+			want:  map[string]any{"Name": "demo", "Port": int64(8080)},
+		},
+		{
+			name:  "no more values",
+			lines: []string{"Name demo", "n", "Port 8080"},
+			want:  map[string]any{"Name": "demo"},
+		},
+		{
+			name:    "too many tokens",
+			lines:   []string{"Name my demo", "Name demo", "n"},
+			want:    map[string]any{"Name": "demo"},
+			wantOut: "Invalid input: incorrect number of tokens",
+		},
+		{
+			name:    "unknown type",
+			lines:   []string{"Port:port 8080", "Port:int 8080", "n"},
+			want:    map[string]any{"Port": int64(8080)},
+			wantOut: `Invalid input: Port: unknown type "port"`,
+		},
+		{
+			name:    "invalid key",
+			lines:   []string{"Name demo", "name demo", "n"},
+			rules:   input.Rules{Keys: mustKeyRules(t, "^[a-z]+$")},
+			want:    map[string]any{"name": "demo"},
+			wantOut: `Invalid input: variable name "Name" must match`,
+		},
+		{
+			name:    "line too long",
+			lines:   []string{"Name " + strings.Repeat("x", 32), "Name demo", "n"},
+			max:     16,
+			want:    map[string]any{"Name": "demo"},
+			wantOut: "Invalid input: line longer than 16 bytes",
+		},
+		{
+			name:  "multi-line value",
+			lines: []string{"Cert <<END", "-----BEGIN-----", "", "  abc", "-----END-----", "END", "n"},
+			want:  map[string]any{"Cert": "-----BEGIN-----\n\n  abc\n-----END-----"},
+		},
+		{
+			name:    "multi-line value ended by the input",
+			lines:   []string{"Cert <<END", "-----BEGIN-----"},
+			wantErr: io.EOF,
+		},
+		{
+			name:  "no inference",
+			lines: []string{"Port 8080", "Debug true", "n"},
+			rules: input.Rules{NoInfer: true},
+			want:  map[string]any{"Port": "8080", "Debug": "true"},
+		},
+		{
+			name:    "back",
+			lines:   []string{back},
+			wantErr: errBack,
+		},
+		{
+			name:    "quit",
+			lines:   []string{"Name demo", quit},
+			wantErr: ErrQuit,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newScript(tt.lines...)
+			p.max = tt.max
+			w := &Wizard{p: p, rules: tt.rules}
+			got, err := w.processVariables(globalPrompt, input.Scope{})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got.Vars, tt.want) {
+				t.Errorf("got variables %v, want %v", got.Vars, tt.want)
+			}
+			if !strings.Contains(p.out.String(), tt.wantOut) {
+				t.Errorf("output does not contain %q:\n%s", tt.wantOut, p.out.String())
+			}
+		})
+	}
+}
+
+func TestRunWizard(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing")
+	tests := []struct {
+		name  string
+		lines []string
+		opts  []Option
+		// want is the config returned, wantErr the error and wantOut part
+		// of the output, if set.
+		want    config.Config
+		wantErr error
+		wantOut string
+	}{
+		{
+			name:  "confirmed",
+			lines: []string{"Name demo", "n", "main.go", dir, "main.tmpl", "Pkg main", "n", "n", "y"},
+			want: config.Config{
+				Global: map[string]any{"Name": "demo"},
+				Files:  []config.File{{Name: "main.go", Path: dir, Template: "main.tmpl", Local: map[string]any{"Pkg": "main"}}},
+			},
+		},
+		{
+			name:    "input ends in files",
+			lines:   []string{"Name demo", "n", "main.go"},
+			want:    config.Config{Global: map[string]any{"Name": "demo"}},
+			wantErr: IncompleteError{Section: Files},
+		},
+		{
+			name:    "input ends in review",
+			lines:   []string{"n", "main.go", dir, "main.tmpl", "n", "n"},
+			want:    config.Config{Files: []config.File{{Name: "main.go", Path: dir, Template: "main.tmpl"}}},
+			wantErr: IncompleteError{Section: Files + 1},
+		},
+		{
+			name:  "assume yes",
+			lines: []string{"Name demo", "n", "main.go", dir, "main.tmpl"},
+			opts:  []Option{WithAssumeYes()},
+			want: config.Config{
+				Global: map[string]any{"Name": "demo"},
+				Files:  []config.File{{Name: "main.go", Path: dir, Template: "main.tmpl"}},
+			},
+		},
+		{
+			name:    "multiple tokens",
+			lines:   []string{"n", "main go", "main.go", dir, "main.tmpl", "n", "n", "y"},
+			want:    config.Config{Files: []config.File{{Name: "main.go", Path: dir, Template: "main.tmpl"}}},
+			wantOut: "Invalid input: expected a single value, got 2",
+		},
+		{
+			name:    "strict",
+			lines:   []string{"n", "main.go", missing, dir, "main.tmpl", "n", "n", "y"},
+			opts:    []Option{WithStrict()},
+			want:    config.Config{Files: []config.File{{Name: "main.go", Path: dir, Template: "main.tmpl"}}},
+			wantOut: "Invalid input: directory " + missing + " does not exist",
+		},
+		{
+			name:  "multi-line local",
+			lines: []string{"n", "main.go", dir, "main.tmpl", "Doc <<EOF", "a", "b", "EOF", "n", "n", "y"},
+			want:  config.Config{Files: []config.File{{Name: "main.go", Path: dir, Template: "main.tmpl", Local: map[string]any{"Doc": "a\nb"}}}},
+		},
+		{
+			name:  "back to globals",
+			lines: []string{"n", back, "Name demo", "n", "main.go", dir, "main.tmpl", "n", "n", "y"},
+			want: config.Config{
+				Global: map[string]any{"Name": "demo"},
+				Files:  []config.File{{Name: "main.go", Path: dir, Template: "main.tmpl"}},
+			},
+		},
+		{
+			name:    "quit",
+			lines:   []string{"n", quit},
+			wantErr: ErrQuit,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newScript(tt.lines...)
+			got, err := Run(append(tt.opts, WithPrompter(p), WithSkipCommands())...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got config %+v, want %+v", got, tt.want)
+			}
+			if !strings.Contains(p.out.String(), tt.wantOut) {
+				t.Errorf("output does not contain %q:\n%s", tt.wantOut, p.out.String())
+			}
+		})
+	}
+}
+
+func mustKeyRules(t *testing.T, pattern string) config.KeyRules {
+	t.Helper()
+	r, err := config.NewKeyRules(pattern, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}